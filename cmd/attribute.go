@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	attributeFilterFile string
+	attributeSourceRoot string
+)
+
+// attributeCmd represents the attribute command
+var attributeCmd = &cobra.Command{
+	Use:   "attribute [gcovr-file...]",
+	Short: "Show which lines and branches each report covers on its own",
+	Long: `Compare several gcovr JSON reports, typically one per test or fuzz seed,
+and report for each one the lines and branches that no other report covers.
+Reports that contribute nothing unique are listed at the end as candidates
+for pruning.
+
+A report listed as contributing nothing can be removed on its own without
+losing coverage. Removing several of them at once may lose lines that only
+they cover together; use minimize to pick a covering subset instead.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runAttribute,
+}
+
+func init() {
+	rootCmd.AddCommand(attributeCmd)
+
+	attributeCmd.Flags().StringVarP(&attributeFilterFile, "filter", "f", "",
+		"Filter config file (YAML) to specify target files and functions")
+	attributeCmd.Flags().StringVar(&attributeSourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+}
+
+func runAttribute(cmd *cobra.Command, args []string) error {
+	reports, err := loadReports(args, attributeFilterFile, attributeSourceRoot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Computing unique contributions...")
+	attribution, err := gcovr.ComputeAttribution(args, reports)
+	if err != nil {
+		return fmt.Errorf("failed to compute attribution: %w", err)
+	}
+
+	fmt.Print(gcovr.FormatAttributionReport(attribution))
+
+	return nil
+}
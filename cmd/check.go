@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	checkFilterFile     string
+	checkSourceRoot     string
+	checkLine           float64
+	checkBranch         float64
+	checkFunctionLine   float64
+	checkFunctionBranch float64
+	checkAllowUnmatched bool
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check [gcovr-file]",
+	Short: "Fail when coverage is below the configured thresholds",
+	Long: `Evaluate coverage thresholds against a gcovr JSON report and exit
+non-zero when any of them is not met, so CI can gate on coverage.
+
+Thresholds are read from the "thresholds" section of the filter config:
+
+  thresholds:
+    line: 80              # global line coverage
+    branch: 50            # global branch coverage
+    function_line: 60     # line coverage of every target function
+    files:
+      - file: "demo.cc"
+        line: 90
+    functions:
+      - function: "f"
+        line: 100
+
+When the config has targets, the report is filtered first so only target
+files and functions count. The --line, --branch, --function-line and
+--function-branch flags override the global values from the config.
+
+A file or function threshold that matches nothing in the report fails the
+check, so a typo in a path or name cannot pass silently. Set
+"allow_unmatched: true" in the thresholds section or pass --allow-unmatched
+to only warn about them instead.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().StringVarP(&checkFilterFile, "filter", "f", "",
+		"Filter config file (YAML) with targets and thresholds")
+	checkCmd.Flags().StringVar(&checkSourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+	checkCmd.Flags().Float64Var(&checkLine, "line", 0, "Minimum global line coverage (%)")
+	checkCmd.Flags().Float64Var(&checkBranch, "branch", 0, "Minimum global branch coverage (%)")
+	checkCmd.Flags().Float64Var(&checkFunctionLine, "function-line", 0, "Minimum line coverage of every function (%)")
+	checkCmd.Flags().Float64Var(&checkFunctionBranch, "function-branch", 0, "Minimum branch coverage of every function (%)")
+	checkCmd.Flags().BoolVar(&checkAllowUnmatched, "allow-unmatched", false,
+		"Only warn about file and function thresholds that match nothing")
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	reportFile := args[0]
+
+	filterConfig := &gcovr.FilterConfig{}
+	if checkFilterFile != "" {
+		fmt.Printf("Reading filter config: %s\n", checkFilterFile)
+		var err error
+		filterConfig, err = gcovr.ParseFilterConfig(checkFilterFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse filter config: %w", err)
+		}
+	}
+
+	thresholds := &gcovr.Thresholds{}
+	if filterConfig.Thresholds != nil {
+		thresholds = filterConfig.Thresholds
+	}
+	flags := cmd.Flags()
+	if flags.Changed("line") {
+		thresholds.Line = checkLine
+	}
+	if flags.Changed("branch") {
+		thresholds.Branch = checkBranch
+	}
+	if flags.Changed("function-line") {
+		thresholds.FunctionLine = checkFunctionLine
+	}
+	if flags.Changed("function-branch") {
+		thresholds.FunctionBranch = checkFunctionBranch
+	}
+	if flags.Changed("allow-unmatched") {
+		thresholds.AllowUnmatched = checkAllowUnmatched
+	}
+
+	fmt.Printf("Reading report: %s\n", reportFile)
+	report, err := gcovr.ParseReport(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	report, err = applySourceExclusions(report, checkSourceRoot, "")
+	if err != nil {
+		return err
+	}
+
+	if len(filterConfig.Targets) > 0 {
+		fmt.Println("Applying filters...")
+		var matches *gcovr.FilterMatchReport
+		report, matches, err = gcovr.ApplyFilterWithMatches(report, filterConfig)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter: %w", err)
+		}
+		printFilterWarnings("", matches)
+	}
+
+	thresholdReport, err := gcovr.CheckThresholds(report, thresholds, filterConfig.Match)
+	if err != nil {
+		return fmt.Errorf("failed to check thresholds: %w", err)
+	}
+
+	fmt.Print(gcovr.FormatThresholdReport(thresholdReport))
+	for _, warning := range thresholdReport.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	if len(thresholdReport.Results) == 0 {
+		return fmt.Errorf("no coverage thresholds configured")
+	}
+	if failed := thresholdReport.Failed(); len(failed) > 0 {
+		return fmt.Errorf("coverage check failed: %d threshold(s) not met", len(failed))
+	}
+
+	return nil
+}
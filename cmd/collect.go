@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	collectFilterFile          string
+	collectOutputFile          string
+	collectUseGcov             bool
+	collectReset               bool
+	collectIgnoreCompileErrors bool
+	collectCompilerArgs        []string
+	collectGcovrPath           string
+	collectGcovPath            string
+	collectVerbose             bool
+)
+
+// collectCmd represents the collect command
+var collectCmd = &cobra.Command{
+	Use:   "collect [test-input]",
+	Short: "Compile a test input and collect coverage of the target files",
+	Long: `Compile a test input with the compiler configured in the filter config
+and collect the resulting coverage of the target files.
+
+The compiler from compiler.path is run as "path args... input -o output",
+then gcovr (or gcov with --gcov) runs inside compiler.gcovr_exec_path,
+restricted to the files listed under targets. The report can be saved
+with --output and compared with the diff command.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCollect,
+}
+
+func init() {
+	rootCmd.AddCommand(collectCmd)
+
+	collectCmd.Flags().StringVarP(&collectFilterFile, "filter", "f", "",
+		"Filter config file (YAML) with the compiler section and target files (required)")
+	collectCmd.Flags().StringVarP(&collectOutputFile, "output", "o", "",
+		"Write the collected report to this JSON file")
+	collectCmd.Flags().BoolVar(&collectUseGcov, "gcov", false,
+		"Run gcov directly instead of gcovr")
+	collectCmd.Flags().BoolVar(&collectReset, "reset", false,
+		"Delete existing .gcda files before compiling")
+	collectCmd.Flags().BoolVar(&collectIgnoreCompileErrors, "ignore-compile-errors", false,
+		"Collect coverage even if the compiler exits with an error")
+	collectCmd.Flags().StringArrayVar(&collectCompilerArgs, "compiler-arg", nil,
+		"Extra compiler argument (repeatable)")
+	collectCmd.Flags().StringVar(&collectGcovrPath, "gcovr-path", "",
+		"gcovr executable (default \"gcovr\")")
+	collectCmd.Flags().StringVar(&collectGcovPath, "gcov-path", "",
+		"gcov executable (default \"gcov\")")
+	collectCmd.Flags().BoolVarP(&collectVerbose, "verbose", "v", false,
+		"Show compiler and coverage tool output")
+
+	collectCmd.MarkFlagRequired("filter")
+}
+
+func runCollect(cmd *cobra.Command, args []string) error {
+	input := args[0]
+
+	fmt.Printf("Reading filter config: %s\n", collectFilterFile)
+	filterConfig, err := gcovr.ParseFilterConfig(collectFilterFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse filter config: %w", err)
+	}
+
+	opts := gcovr.CollectOptions{
+		Tool:                 gcovr.ToolGcovr,
+		GcovrPath:            collectGcovrPath,
+		GcovPath:             collectGcovPath,
+		ExtraArgs:            collectCompilerArgs,
+		ResetCounters:        collectReset,
+		IgnoreCompilerErrors: collectIgnoreCompileErrors,
+	}
+	if collectUseGcov {
+		opts.Tool = gcovr.ToolGcov
+	}
+	if collectVerbose {
+		opts.Stdout = os.Stdout
+		opts.Stderr = os.Stderr
+	}
+
+	fmt.Printf("Collecting coverage for: %s\n", input)
+	report, err := gcovr.Collect(cmd.Context(), filterConfig, input, opts)
+	if err != nil {
+		return fmt.Errorf("failed to collect coverage: %w", err)
+	}
+
+	covered, total := 0, 0
+	for _, file := range report.Files {
+		for _, line := range file.Lines {
+			total++
+			if line.Count > 0 {
+				covered++
+			}
+		}
+	}
+	fmt.Printf("Collected %d file(s): %d/%d lines covered\n", len(report.Files), covered, total)
+
+	if collectOutputFile != "" {
+		if err := gcovr.WriteReport(report, collectOutputFile); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Printf("Report written to: %s\n", collectOutputFile)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+// demangleCmd represents the demangle command
+var demangleCmd = &cobra.Command{
+	Use:   "demangle [symbol...]",
+	Short: "Demangle Itanium C++ ABI symbol names",
+	Long: `Demangle C++ symbol names such as _ZN2ns3Foo3barEi into their
+human-readable form (ns::Foo::bar(int)), using the same built-in demangler
+that fills in missing demangled names in gcovr reports.
+
+Symbols are taken from the arguments, or read one per line from stdin when
+no arguments are given. Names that cannot be demangled are printed unchanged.`,
+	RunE: runDemangle,
+}
+
+func init() {
+	rootCmd.AddCommand(demangleCmd)
+}
+
+func runDemangle(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		for _, symbol := range args {
+			fmt.Println(gcovr.DemangleName(symbol))
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fmt.Println(gcovr.DemangleName(scanner.Text()))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read symbols from stdin: %w", err)
+	}
+
+	return nil
+}
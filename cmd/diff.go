@@ -2,15 +2,27 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
 )
 
 var (
-	baseFile   string
-	newFile    string
-	filterFile string
+	baseFile    string
+	newFile     string
+	filterFile  string
+	sourceRoot  string
+	snipRoot    string
+	remapDiff   string
+	gitRange    string
+	gitRepo     string
+	matchByHash bool
+	matchFuncs  []string
+	diffContext int
+	diffSort    string
+	diffTop     int
 )
 
 // diffCmd represents the diff command
@@ -25,7 +37,38 @@ functions with increased line coverage. The tool will report:
 - Demangled function names for readability
 
 Optionally, you can specify a filter configuration file to only track
-specific files and functions defined in the targets.`,
+specific files and functions defined in the targets.
+
+When the reports come from different commits, pass the unified diff between
+them with --remap-diff (or let git compute it with --git-range BASE..NEW) so
+base line numbers are moved to where the code is in the new commit. Lines
+changed by the diff lose their base coverage, so shifted code is not
+reported as newly covered.
+
+Reports carrying gcovr/md5 line hashes are checked for source drift, and a
+warning is printed when base and new were built from different sources.
+With --match-by-hash base lines are matched to new lines by their source
+hash instead of their number, which survives shifted code without a diff.
+
+Functions are matched by mangled name, so a signature change makes a
+function look new. --match-functions adds fallback strategies, tried in
+order for functions whose mangled name appears in only one report:
+  base-name  same qualified name, ignoring the parameter list
+  position   same start position (pos) or declaration line (lineno)
+  fuzzy      most similar name and line numbers
+Matched functions are compared against their base coverage and listed as
+renamed or re-signatured.
+
+With --context N the source of the newly covered lines is printed with N
+lines of context, read from --snippet-root (default: --source-root). Only
+--source-root applies GCOVR_EXCL/LCOV_EXCL exclusion markers, so pass
+--snippet-root alone to get snippets without changing the coverage. A
+warning is printed when the source on disk does not match the gcovr/md5
+hashes of the new report.
+
+Functions are listed by file and first newly covered line. --sort ranks
+them by the lines still uncovered or by their new line coverage instead,
+and --top keeps only the first N.`,
 	RunE: runDiff,
 }
 
@@ -36,11 +79,29 @@ func init() {
 	diffCmd.Flags().StringVarP(&newFile, "new", "n", "", "New gcovr JSON report file (required)")
 	diffCmd.Flags().StringVarP(&filterFile, "filter", "f", "", "Filter config file (YAML) to specify target files and functions")
 
+	diffCmd.Flags().StringVar(&sourceRoot, "source-root", "", "Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+	diffCmd.Flags().StringVar(&snipRoot, "snippet-root", "", "Source directory to read --context snippets from (default: --source-root)")
+	diffCmd.Flags().IntVar(&diffContext, "context", -1, "Print the source of newly covered lines with N lines of context (-1 to disable)")
+	diffCmd.Flags().StringVar(&diffSort, "sort", gcovr.SortByLine, "Sort order: file, line, uncovered or coverage")
+	diffCmd.Flags().IntVar(&diffTop, "top", 0, "Show only the first N functions after sorting (0 for all)")
+
+	diffCmd.Flags().StringVar(&remapDiff, "remap-diff", "", "Unified diff from the base to the new commit used to remap base line numbers (\"-\" for stdin)")
+	diffCmd.Flags().StringVar(&gitRange, "git-range", "", "Remap base line numbers through \"git diff BASE NEW\" for a BASE..NEW range")
+	diffCmd.Flags().StringVar(&gitRepo, "repo", ".", "Git repository used with --git-range")
+	diffCmd.Flags().BoolVar(&matchByHash, "match-by-hash", false, "Match base lines to new lines by their gcovr/md5 source hash")
+	diffCmd.Flags().StringSliceVar(&matchFuncs, "match-functions", nil,
+		"Function matching strategies tried in order after mangled names (base-name, position, fuzzy)")
+
 	diffCmd.MarkFlagRequired("base")
 	diffCmd.MarkFlagRequired("new")
+	diffCmd.MarkFlagsMutuallyExclusive("remap-diff", "git-range", "match-by-hash")
 }
 
 func runDiff(cmd *cobra.Command, args []string) error {
+	if err := gcovr.ValidateSortOrder(diffSort); err != nil {
+		return err
+	}
+
 	// Parse filter config if provided
 	var filterConfig *gcovr.FilterConfig
 	if filterFile != "" {
@@ -53,6 +114,11 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Filtering enabled: tracking %d file(s)\n", len(filterConfig.Targets))
 	}
 
+	lineDiffs, err := readRemapDiff()
+	if err != nil {
+		return err
+	}
+
 	// Parse base report
 	fmt.Printf("Reading base report: %s\n", baseFile)
 	baseReport, err := gcovr.ParseReport(baseFile)
@@ -67,23 +133,95 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse new report: %w", err)
 	}
 
+	if lineDiffs != nil {
+		fmt.Printf("Remapping base line numbers through %d changed file(s)\n", len(lineDiffs))
+		baseReport = gcovr.RemapReport(baseReport, lineDiffs)
+	}
+
+	drift := gcovr.DetectSourceDrift(baseReport, newReport)
+	for _, warning := range drift.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	if drift.HasDrift() && !matchByHash && lineDiffs == nil {
+		fmt.Fprintln(os.Stderr, "Warning: line numbers may not correspond; consider --match-by-hash or --remap-diff")
+	}
+
+	if matchByHash {
+		fmt.Println("Matching base lines to new lines by source hash...")
+		baseReport = gcovr.RemapReportByHash(baseReport, newReport)
+	}
+
+	baseReport, err = applySourceExclusions(baseReport, sourceRoot, "base report")
+	if err != nil {
+		return err
+	}
+	newReport, err = applySourceExclusions(newReport, sourceRoot, "new report")
+	if err != nil {
+		return err
+	}
+
 	// Apply filter if provided
 	if filterConfig != nil {
 		fmt.Println("Applying filters...")
-		baseReport = gcovr.ApplyFilter(baseReport, filterConfig)
-		newReport = gcovr.ApplyFilter(newReport, filterConfig)
+		var baseMatches, newMatches *gcovr.FilterMatchReport
+		baseReport, baseMatches, err = gcovr.ApplyFilterWithMatches(baseReport, filterConfig)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter to base report: %w", err)
+		}
+		newReport, newMatches, err = gcovr.ApplyFilterWithMatches(newReport, filterConfig)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter to new report: %w", err)
+		}
+		printFilterWarnings("base report", baseMatches)
+		printFilterWarnings("new report", newMatches)
 	}
 
 	// Compute coverage increase
 	fmt.Println("Computing coverage increases...")
-	report, err := gcovr.ComputeCoverageIncrease(baseReport, newReport)
+	report, err := gcovr.ComputeCoverageIncreaseWithOptions(baseReport, newReport, gcovr.DiffOptions{
+		FunctionMatch: matchFuncs,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to compute coverage increase: %w", err)
 	}
 
+	report.Sort(diffSort)
+	if total := len(report.Increases); diffTop > 0 && diffTop < total {
+		report.Top(diffTop)
+		fmt.Printf("Showing the top %d of %d function(s)\n", diffTop, total)
+	}
+
+	if diffContext >= 0 {
+		reader := sourceSnippetReader(newReport, snippetRoot(snipRoot, sourceRoot))
+		printWarnings(reader.AddIncreaseSnippets(report, diffContext))
+	}
+
 	// Display results
 	output := gcovr.FormatReport(report)
 	fmt.Print(output)
 
 	return nil
 }
+
+// readRemapDiff loads the diff between the base and new commits, if requested
+func readRemapDiff() ([]gcovr.FileDiff, error) {
+	if gitRange != "" {
+		baseRev, newRev, ok := strings.Cut(gitRange, "..")
+		if !ok || baseRev == "" || newRev == "" {
+			return nil, fmt.Errorf("invalid --git-range %q, expected BASE..NEW", gitRange)
+		}
+
+		fmt.Printf("Running git diff %s %s in %s\n", baseRev, newRev, gitRepo)
+		diffs, err := gcovr.GitDiff(gitRepo, baseRev, newRev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff commits: %w", err)
+		}
+		return diffs, nil
+	}
+
+	if remapDiff != "" {
+		return readUnifiedDiff(remapDiff)
+	}
+
+	return nil, nil
+}
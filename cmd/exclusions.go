@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+// applySourceExclusions removes lines and branches carrying exclusion markers
+// in the sources under sourceRoot. It is a no-op when sourceRoot is empty.
+func applySourceExclusions(report *gcovr.GcovrReport, sourceRoot, label string) (*gcovr.GcovrReport, error) {
+	if sourceRoot == "" {
+		return report, nil
+	}
+
+	excluded, summary, err := gcovr.ApplyExclusions(report, sourceRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply exclusion markers: %w", err)
+	}
+
+	prefix := "Warning: "
+	if label != "" {
+		prefix = fmt.Sprintf("Warning (%s): ", label)
+	}
+	for _, missing := range summary.Missing {
+		fmt.Fprintf(os.Stderr, "%ssource file %s not found under %s, exclusion markers not applied\n",
+			prefix, missing, sourceRoot)
+	}
+	for _, warning := range summary.Warnings {
+		fmt.Fprintf(os.Stderr, "%s%s\n", prefix, warning)
+	}
+
+	fmt.Print(gcovr.FormatExclusionReport(summary))
+	return excluded, nil
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	filterCheckFile string
+)
+
+// filterCmd groups subcommands that work on filter configuration files
+var filterCmd = &cobra.Command{
+	Use:   "filter",
+	Short: "Inspect filter configuration files",
+}
+
+// filterCheckCmd represents the filter check command
+var filterCheckCmd = &cobra.Command{
+	Use:   "check [gcovr-file]",
+	Short: "Check that every filter target matches something in a report",
+	Long: `Resolve every target of a filter configuration against a gcovr JSON
+report and show which report files and functions each target matched.
+
+The command fails if any target file, function or line range matched nothing,
+so a stale filter config can be caught before it silently hides coverage.
+Targets that match several report files are reported as ambiguous.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFilterCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(filterCmd)
+	filterCmd.AddCommand(filterCheckCmd)
+
+	filterCheckCmd.Flags().StringVarP(&filterCheckFile, "filter", "f", "",
+		"Filter config file (YAML) to check (required)")
+
+	filterCheckCmd.MarkFlagRequired("filter")
+}
+
+func runFilterCheck(cmd *cobra.Command, args []string) error {
+	reportFile := args[0]
+
+	fmt.Printf("Reading filter config: %s\n", filterCheckFile)
+	filterConfig, err := gcovr.ParseFilterConfig(filterCheckFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse filter config: %w", err)
+	}
+
+	fmt.Printf("Reading report: %s\n", reportFile)
+	report, err := gcovr.ParseReport(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	_, matches, err := gcovr.ApplyFilterWithMatches(report, filterConfig)
+	if matches == nil {
+		return fmt.Errorf("failed to apply filter: %w", err)
+	}
+
+	fmt.Print(gcovr.FormatFilterMatchReport(matches))
+	printFilterWarnings("", matches)
+
+	if err != nil {
+		return fmt.Errorf("failed to apply filter: %w", err)
+	}
+	if matches.HasDeadTargets() {
+		return fmt.Errorf("filter config %s has targets that match nothing", filterCheckFile)
+	}
+
+	return nil
+}
+
+// printFilterWarnings writes the dead and ambiguous target warnings to stderr
+func printFilterWarnings(label string, matches *gcovr.FilterMatchReport) {
+	for _, warning := range matches.Warnings() {
+		if label != "" {
+			fmt.Fprintf(os.Stderr, "Warning (%s): %s\n", label, warning)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
+}
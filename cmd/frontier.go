@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	frontierFilterFile string
+	frontierSourceRoot string
+	frontierTop        int
+)
+
+// frontierCmd represents the frontier command
+var frontierCmd = &cobra.Command{
+	Use:   "frontier [gcovr-file]",
+	Short: "Rank uncovered code that is one step away from covered code",
+	Long: `Find the coverage frontier of a gcovr JSON report: uncovered code that
+executed code leads to directly. These are good targets for directed fuzzing.
+
+Two kinds of targets are reported:
+- branch: an untaken branch of an executed line, with the uncovered lines
+  its destination block starts
+- call: a function that never ran although an executed line calls it
+
+Targets are ranked by the number of uncovered lines they lead to.
+
+gcovr records call sites but not the functions they call, so call targets
+are found by reading the executed call sites in the sources under
+--source-root. Without it only branch targets are reported.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFrontier,
+}
+
+func init() {
+	rootCmd.AddCommand(frontierCmd)
+
+	frontierCmd.Flags().StringVarP(&frontierFilterFile, "filter", "f", "",
+		"Filter config file (YAML) to specify target files and functions")
+	frontierCmd.Flags().StringVar(&frontierSourceRoot, "source-root", "",
+		"Source directory used to find called functions and exclusion markers")
+	frontierCmd.Flags().IntVar(&frontierTop, "top", 0,
+		"Show only the N highest-ranked targets (0 for all)")
+}
+
+func runFrontier(cmd *cobra.Command, args []string) error {
+	filterConfig, err := readFilterConfig(frontierFilterFile)
+	if err != nil {
+		return err
+	}
+
+	report, err := loadReport(args[0], filterConfig, frontierSourceRoot)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Analyzing frontier...")
+	frontier, err := gcovr.FindFrontier(report, frontierSourceRoot)
+	if err != nil {
+		return fmt.Errorf("failed to find frontier: %w", err)
+	}
+
+	for _, warning := range frontier.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	for _, missing := range frontier.Missing {
+		fmt.Fprintf(os.Stderr, "Warning: source file %s not found under %s, call targets in it not searched\n",
+			missing, frontierSourceRoot)
+	}
+
+	fmt.Print(gcovr.FormatFrontierReport(frontier, frontierTop))
+
+	return nil
+}
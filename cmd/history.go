@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	historyDir        string
+	historyPlateau    int
+	historyShowLines  bool
+	historyLabel      string
+	historyTime       string
+	historyFilterFile string
+	historySourceRoot string
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show how coverage evolved across recorded reports",
+	Long: `Print coverage trends from a history store: global line and branch
+coverage per recorded entry, each function's coverage from its first to its
+last entry, plateaus, and optionally the time each line was first covered.
+
+Record entries with "history record". The store is an append-only
+history.jsonl file in --dir.
+
+Coverage has plateaued when the last --plateau entries covered no new lines
+or branches. A function is marked as plateaued when it is not fully covered
+and its covered line count has not grown within that many entries.`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+// historyRecordCmd represents the history record command
+var historyRecordCmd = &cobra.Command{
+	Use:   "record [gcovr-file]",
+	Short: "Append a report summary to the history store",
+	Long: `Summarize a gcovr JSON report (global, per-function line and branch
+coverage, and the lines and branches covered for the first time) and append
+it to the history store in --dir. Entries must be recorded in time order.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runHistoryRecord,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+	historyCmd.AddCommand(historyRecordCmd)
+
+	historyCmd.PersistentFlags().StringVar(&historyDir, "dir", ".gcovr-history",
+		"Directory of the history store")
+
+	historyCmd.Flags().IntVar(&historyPlateau, "plateau", 5,
+		"Number of entries without new coverage that counts as a plateau (0 disables)")
+	historyCmd.Flags().BoolVar(&historyShowLines, "lines", false,
+		"List the time each line was first covered")
+
+	historyRecordCmd.Flags().StringVarP(&historyLabel, "label", "l", "",
+		"Label of the entry, e.g. a commit or campaign round")
+	historyRecordCmd.Flags().StringVar(&historyTime, "time", "",
+		"Time of the entry in RFC 3339 format (default: now)")
+	historyRecordCmd.Flags().StringVarP(&historyFilterFile, "filter", "f", "",
+		"Filter config file (YAML) to specify target files and functions")
+	historyRecordCmd.Flags().StringVar(&historySourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	store, err := gcovr.OpenHistory(historyDir)
+	if err != nil {
+		return err
+	}
+
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+
+	report := gcovr.AnalyzeHistory(entries, historyPlateau)
+	fmt.Print(gcovr.FormatHistoryReport(report, historyShowLines))
+
+	return nil
+}
+
+func runHistoryRecord(cmd *cobra.Command, args []string) error {
+	at := time.Now()
+	if historyTime != "" {
+		var err error
+		at, err = time.Parse(time.RFC3339, historyTime)
+		if err != nil {
+			return fmt.Errorf("invalid --time %q: %w", historyTime, err)
+		}
+	}
+
+	filterConfig, err := readFilterConfig(historyFilterFile)
+	if err != nil {
+		return err
+	}
+	report, err := loadReport(args[0], filterConfig, historySourceRoot)
+	if err != nil {
+		return err
+	}
+
+	store, err := gcovr.OpenHistory(historyDir)
+	if err != nil {
+		return err
+	}
+	entry, err := store.Record(report, at, historyLabel)
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+
+	newLines, newBranches := 0, 0
+	for _, contribution := range entry.New {
+		newLines += len(contribution.Lines)
+		newBranches += len(contribution.Branches)
+	}
+	fmt.Printf("Recorded %s: lines %d/%d, branches %d/%d, %d new line(s), %d new branch(es)\n",
+		at.Format(time.RFC3339), entry.Lines.Covered, entry.Lines.Total,
+		entry.Branches.Covered, entry.Branches.Total, newLines, newBranches)
+
+	return nil
+}
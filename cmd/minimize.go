@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	minimizeFilterFile string
+	minimizeSourceRoot string
+	minimizeList       string
+	minimizeCosts      string
+	minimizeOutput     string
+)
+
+// minimizeCmd represents the minimize command
+var minimizeCmd = &cobra.Command{
+	Use:   "minimize [gcovr-file...]",
+	Short: "Select a small subset of reports that keeps their combined coverage",
+	Long: `Select a subset of gcovr JSON reports, typically one per fuzz seed or
+test, that covers every line and branch covered by all of them together.
+
+The selection is a greedy set cover: the report covering the most lines and
+branches not yet covered is picked first, and so on until nothing new is
+left. With --costs each report is weighted by a cost such as its input size
+or runtime, and the report with the most new coverage per unit of cost is
+picked instead. The costs file has one "<report-path> <cost>" pair per line;
+paths are resolved against the current directory, so "./a.json" and
+"a.json" name the same report.
+
+Reports are read from the arguments and from --list (one path per line, "-"
+for stdin). The chosen paths are printed in selection order and can be
+written to a file with --output.`,
+	SilenceUsage: true,
+	RunE:         runMinimize,
+}
+
+func init() {
+	rootCmd.AddCommand(minimizeCmd)
+
+	minimizeCmd.Flags().StringVarP(&minimizeFilterFile, "filter", "f", "",
+		"Filter config file (YAML) to specify target files and functions")
+	minimizeCmd.Flags().StringVar(&minimizeSourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+	minimizeCmd.Flags().StringVarP(&minimizeList, "list", "l", "",
+		"File with one report path per line (\"-\" for stdin)")
+	minimizeCmd.Flags().StringVar(&minimizeCosts, "costs", "",
+		"File with \"<report-path> <cost>\" lines weighting each report")
+	minimizeCmd.Flags().StringVarP(&minimizeOutput, "output", "o", "",
+		"Write the selected report paths to a file, one per line")
+}
+
+func runMinimize(cmd *cobra.Command, args []string) error {
+	paths := append([]string{}, args...)
+	if minimizeList != "" {
+		listed, err := readPathList(minimizeList)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, listed...)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no reports given")
+	}
+
+	var costs map[string]float64
+	if minimizeCosts != "" {
+		fmt.Printf("Reading costs: %s\n", minimizeCosts)
+		f, err := os.Open(minimizeCosts)
+		if err != nil {
+			return fmt.Errorf("failed to open costs: %w", err)
+		}
+		costs, err = gcovr.ParseCosts(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to parse costs: %w", err)
+		}
+		if costs, err = absoluteCosts(costs); err != nil {
+			return err
+		}
+	}
+
+	filterConfig, err := readFilterConfig(minimizeFilterFile)
+	if err != nil {
+		return err
+	}
+
+	minimizer := gcovr.NewMinimizer()
+	for _, path := range paths {
+		cost := 1.0
+		if costs != nil {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s: %w", path, err)
+			}
+			var ok bool
+			if cost, ok = costs[abs]; !ok {
+				return fmt.Errorf("no cost given for %s", path)
+			}
+		}
+
+		report, err := loadReport(path, filterConfig, minimizeSourceRoot)
+		if err != nil {
+			return err
+		}
+		if err := minimizer.Add(path, report, cost); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Selecting reports...")
+	result := minimizer.Minimize()
+	fmt.Print(gcovr.FormatMinimizeReport(result))
+
+	if minimizeOutput != "" {
+		var b strings.Builder
+		for _, s := range result.Selected {
+			b.WriteString(s.Name)
+			b.WriteString("\n")
+		}
+		if err := os.WriteFile(minimizeOutput, []byte(b.String()), 0644); err != nil {
+			return fmt.Errorf("failed to write selected reports: %w", err)
+		}
+		fmt.Printf("Selected report paths written to %s\n", minimizeOutput)
+	}
+
+	return nil
+}
+
+// absoluteCosts keys report costs by absolute path, so that reports are found
+// however their paths are spelled on the command line and in the costs file
+func absoluteCosts(costs map[string]float64) (map[string]float64, error) {
+	result := make(map[string]float64, len(costs))
+	for path, cost := range costs {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		result[abs] = cost
+	}
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	patchDiffFile   string
+	patchMin        float64
+	patchFilterFile string
+	patchSourceRoot string
+)
+
+// patchCoverageCmd represents the patch-coverage command
+var patchCoverageCmd = &cobra.Command{
+	Use:   "patch-coverage [gcovr-file]",
+	Short: "Report how many lines changed by a patch are covered",
+	Long: `Read a unified diff (e.g. "git diff" output) and report which of the
+added or modified lines are covered in a gcovr JSON report, per file and
+function. Lines the report does not list (comments, declarations, blank
+lines) are not executable and are ignored.
+
+The diff is read from --diff, or from stdin when --diff is omitted or "-".
+With --min the command exits non-zero when patch coverage is below the
+given percentage.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runPatchCoverage,
+}
+
+func init() {
+	rootCmd.AddCommand(patchCoverageCmd)
+
+	patchCoverageCmd.Flags().StringVarP(&patchDiffFile, "diff", "d", "",
+		"Unified diff file (default: read from stdin)")
+	patchCoverageCmd.Flags().Float64Var(&patchMin, "min", 0,
+		"Minimum patch coverage percentage; fail when below")
+	patchCoverageCmd.Flags().StringVarP(&patchFilterFile, "filter", "f", "",
+		"Filter config file (YAML) to specify target files and functions")
+	patchCoverageCmd.Flags().StringVar(&patchSourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+}
+
+func runPatchCoverage(cmd *cobra.Command, args []string) error {
+	reportFile := args[0]
+
+	diffs, err := readUnifiedDiff(patchDiffFile)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Reading report: %s\n", reportFile)
+	report, err := gcovr.ParseReport(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	report, err = applySourceExclusions(report, patchSourceRoot, "")
+	if err != nil {
+		return err
+	}
+
+	if patchFilterFile != "" {
+		fmt.Printf("Reading filter config: %s\n", patchFilterFile)
+		filterConfig, err := gcovr.ParseFilterConfig(patchFilterFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse filter config: %w", err)
+		}
+
+		fmt.Println("Applying filters...")
+		var matches *gcovr.FilterMatchReport
+		report, matches, err = gcovr.ApplyFilterWithMatches(report, filterConfig)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter: %w", err)
+		}
+		printFilterWarnings("", matches)
+	}
+
+	patchReport, err := gcovr.ComputePatchCoverage(report, diffs)
+	if err != nil {
+		return fmt.Errorf("failed to compute patch coverage: %w", err)
+	}
+
+	fmt.Print(gcovr.FormatPatchCoverageReport(patchReport))
+	for _, path := range patchReport.UnmatchedFiles {
+		fmt.Fprintf(os.Stderr, "Warning: changed file %s is not in the report\n", path)
+	}
+	for _, ambiguous := range patchReport.AmbiguousFiles {
+		fmt.Fprintf(os.Stderr, "Warning: changed file %s is ambiguous, matched %d files: %s; its lines are not counted\n",
+			ambiguous.DiffPath, len(ambiguous.Files), strings.Join(ambiguous.Files, ", "))
+	}
+
+	if patchMin > 0 && patchReport.Percent() < patchMin {
+		return fmt.Errorf("patch coverage %.1f%% is below the minimum of %.1f%%", patchReport.Percent(), patchMin)
+	}
+
+	return nil
+}
+
+// readUnifiedDiff parses a unified diff from a file, or from stdin for "" and "-"
+func readUnifiedDiff(path string) ([]gcovr.FileDiff, error) {
+	var r io.Reader = os.Stdin
+	if path != "" && path != "-" {
+		fmt.Printf("Reading diff: %s\n", path)
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open diff: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	diffs, err := gcovr.ParseUnifiedDiff(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse diff: %w", err)
+	}
+	return diffs, nil
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	ratchetBaselineFile string
+	ratchetNewFile      string
+	ratchetFilterFile   string
+	ratchetSourceRoot   string
+	ratchetUpdate       bool
+)
+
+// ratchetCmd represents the ratchet command
+var ratchetCmd = &cobra.Command{
+	Use:   "ratchet",
+	Short: "Fail when any function covers fewer lines than in a stored baseline",
+	Long: `Compare a new gcovr JSON report with a stored baseline report and exit
+non-zero when any function covers fewer lines than it did in the baseline,
+so coverage of the target functions can only go up.
+
+With --update the new report replaces the baseline when no function
+regressed and at least one improved, raising the bar automatically. A
+missing baseline is created from the new report.`,
+	SilenceUsage: true,
+	RunE:         runRatchet,
+}
+
+func init() {
+	rootCmd.AddCommand(ratchetCmd)
+
+	ratchetCmd.Flags().StringVarP(&ratchetBaselineFile, "baseline", "b", "", "Baseline gcovr JSON report file (required)")
+	ratchetCmd.Flags().StringVarP(&ratchetNewFile, "new", "n", "", "New gcovr JSON report file (required)")
+	ratchetCmd.Flags().StringVarP(&ratchetFilterFile, "filter", "f", "", "Filter config file (YAML) to specify target files and functions")
+	ratchetCmd.Flags().StringVar(&ratchetSourceRoot, "source-root", "", "Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+	ratchetCmd.Flags().BoolVarP(&ratchetUpdate, "update", "u", false, "Rewrite the baseline with the new report when coverage improved")
+
+	ratchetCmd.MarkFlagRequired("baseline")
+	ratchetCmd.MarkFlagRequired("new")
+}
+
+func runRatchet(cmd *cobra.Command, args []string) error {
+	var filterConfig *gcovr.FilterConfig
+	if ratchetFilterFile != "" {
+		fmt.Printf("Reading filter config: %s\n", ratchetFilterFile)
+		var err error
+		filterConfig, err = gcovr.ParseFilterConfig(ratchetFilterFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse filter config: %w", err)
+		}
+	}
+
+	fmt.Printf("Reading new report: %s\n", ratchetNewFile)
+	rawNewReport, err := gcovr.ParseReport(ratchetNewFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse new report: %w", err)
+	}
+
+	if _, err := os.Stat(ratchetBaselineFile); os.IsNotExist(err) && ratchetUpdate {
+		if err := gcovr.WriteReport(rawNewReport, ratchetBaselineFile); err != nil {
+			return fmt.Errorf("failed to create baseline: %w", err)
+		}
+		fmt.Printf("Baseline created: %s\n", ratchetBaselineFile)
+		return nil
+	}
+
+	fmt.Printf("Reading baseline: %s\n", ratchetBaselineFile)
+	baseline, err := gcovr.ParseReport(ratchetBaselineFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse baseline: %w", err)
+	}
+
+	baseline, err = applySourceExclusions(baseline, ratchetSourceRoot, "baseline")
+	if err != nil {
+		return err
+	}
+	newReport, err := applySourceExclusions(rawNewReport, ratchetSourceRoot, "new report")
+	if err != nil {
+		return err
+	}
+
+	if filterConfig != nil {
+		fmt.Println("Applying filters...")
+		var baseMatches, newMatches *gcovr.FilterMatchReport
+		baseline, baseMatches, err = gcovr.ApplyFilterWithMatches(baseline, filterConfig)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter to baseline: %w", err)
+		}
+		newReport, newMatches, err = gcovr.ApplyFilterWithMatches(newReport, filterConfig)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter to new report: %w", err)
+		}
+		printFilterWarnings("baseline", baseMatches)
+		printFilterWarnings("new report", newMatches)
+	}
+
+	fmt.Println("Comparing against baseline...")
+	ratchet, err := gcovr.ComputeRatchet(baseline, newReport)
+	if err != nil {
+		return fmt.Errorf("failed to compare against baseline: %w", err)
+	}
+
+	fmt.Print(gcovr.FormatRatchetReport(ratchet))
+
+	if !ratchet.Passed() {
+		return fmt.Errorf("coverage ratchet failed: %d function(s) lost covered lines", len(ratchet.Regressions))
+	}
+
+	if ratchetUpdate && ratchet.Improved() {
+		if err := gcovr.WriteReport(rawNewReport, ratchetBaselineFile); err != nil {
+			return fmt.Errorf("failed to update baseline: %w", err)
+		}
+		fmt.Printf("Baseline updated: %s\n", ratchetBaselineFile)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+// loadReports parses several reports, applying source exclusions and the
+// filter config (if any) to each of them
+func loadReports(paths []string, filterFile, sourceRoot string) ([]*gcovr.GcovrReport, error) {
+	filterConfig, err := readFilterConfig(filterFile)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*gcovr.GcovrReport, 0, len(paths))
+	for _, path := range paths {
+		report, err := loadReport(path, filterConfig, sourceRoot)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// readFilterConfig parses a filter config, or returns nil when path is empty
+func readFilterConfig(path string) (*gcovr.FilterConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	fmt.Printf("Reading filter config: %s\n", path)
+	filterConfig, err := gcovr.ParseFilterConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse filter config: %w", err)
+	}
+	return filterConfig, nil
+}
+
+// loadReport parses one report and applies source exclusions and the filter
+// config, if any
+func loadReport(path string, filterConfig *gcovr.FilterConfig, sourceRoot string) (*gcovr.GcovrReport, error) {
+	fmt.Printf("Reading report: %s\n", path)
+	report, err := gcovr.ParseReport(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+
+	report, err = applySourceExclusions(report, sourceRoot, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if filterConfig != nil {
+		var matches *gcovr.FilterMatchReport
+		report, matches, err = gcovr.ApplyFilterWithMatches(report, filterConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply filter to %s: %w", path, err)
+		}
+		printFilterWarnings(path, matches)
+	}
+
+	return report, nil
+}
+
+// readPathList reads report paths, one per line, from a file or from stdin for "-"
+func readPathList(path string) ([]string, error) {
+	f := os.Stdin
+	if path != "-" {
+		var err error
+		f, err = os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open report list: %w", err)
+		}
+		defer f.Close()
+	}
+
+	paths := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read report list: %w", err)
+	}
+	return paths, nil
+}
+
+// sourceSnippetReader creates a reader for the sources of a report under
+// sourceRoot and warns about source files that differ from the report
+func sourceSnippetReader(report *gcovr.GcovrReport, sourceRoot string) *gcovr.SourceReader {
+	reader := gcovr.NewSourceReader(sourceRoot)
+	printWarnings(reader.VerifyHashes(report))
+	return reader
+}
+
+// snippetRoot returns the directory to read snippets from: the --snippet-root
+// value, or the --source-root value when it is not set
+func snippetRoot(snippetRoot, sourceRoot string) string {
+	if snippetRoot != "" {
+		return snippetRoot
+	}
+	return sourceRoot
+}
+
+// printWarnings prints warnings to stderr
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	summaryFilterFile string
+	summarySourceRoot string
+	summarySort       string
+	summaryFunctions  bool
+	summaryTree       bool
+	summaryDepth      int
+)
+
+// summaryCmd represents the summary command
+var summaryCmd = &cobra.Command{
+	Use:   "summary [gcovr-file]",
+	Short: "Show line, function and branch coverage totals",
+	Long: `Summarize a gcovr JSON report: line, function and branch coverage for the
+whole report, for every directory (including its subdirectories), for every
+file and, with --functions, for every function.
+
+A function counts as covered when it was called. Use --sort to order the
+directories, files and functions by name, by line coverage (lowest first)
+or by the number of uncovered lines (most first).
+
+With --tree, coverage is shown as a directory tree instead, each directory
+rolling up every file below it, down to --depth levels. This shows at a
+glance which subsystems of a large project are undertested. --depth is
+only accepted together with --tree.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSummary,
+}
+
+func init() {
+	rootCmd.AddCommand(summaryCmd)
+
+	summaryCmd.Flags().StringVarP(&summaryFilterFile, "filter", "f", "",
+		"Filter config file (YAML) to specify target files and functions")
+	summaryCmd.Flags().StringVar(&summarySourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+	summaryCmd.Flags().StringVar(&summarySort, "sort", gcovr.SummarySortName,
+		"Sort order: name, coverage or uncovered")
+	summaryCmd.Flags().BoolVar(&summaryFunctions, "functions", false,
+		"List the coverage of every function")
+	summaryCmd.Flags().BoolVar(&summaryTree, "tree", false,
+		"Show coverage as a directory tree")
+	summaryCmd.Flags().IntVar(&summaryDepth, "depth", 0,
+		"Directory levels shown with --tree (0 for all; requires --tree)")
+}
+
+func runSummary(cmd *cobra.Command, args []string) error {
+	if err := gcovr.ValidateSummarySort(summarySort); err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("depth") && !summaryTree {
+		return fmt.Errorf("--depth requires --tree")
+	}
+	if summaryDepth < 0 {
+		return fmt.Errorf("--depth must be 0 or more, got %d", summaryDepth)
+	}
+
+	filterConfig, err := readFilterConfig(summaryFilterFile)
+	if err != nil {
+		return err
+	}
+
+	report, err := loadReport(args[0], filterConfig, summarySourceRoot)
+	if err != nil {
+		return err
+	}
+
+	summary := gcovr.Summarize(report)
+	summary.Sort(summarySort)
+	if summaryTree {
+		fmt.Print(gcovr.FormatSummaryTree(summary, summaryDepth))
+		return nil
+	}
+	fmt.Print(gcovr.FormatSummaryReport(summary, summaryFunctions))
+
+	return nil
+}
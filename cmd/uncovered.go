@@ -9,6 +9,12 @@ import (
 
 var (
 	uncoveredFilterFile string
+	uncoveredSourceRoot string
+	uncoveredSnipRoot   string
+	uncoveredContext    int
+	uncoveredFuncsOnly  bool
+	uncoveredSort       string
+	uncoveredTop        int
 )
 
 // uncoveredCmd represents the uncovered command
@@ -23,7 +29,23 @@ The tool will show:
 - Which files have uncovered lines
 - Which functions within those files have uncovered lines
 - The specific line numbers that are not covered
-- Coverage statistics for each function`,
+- Coverage statistics for each function
+
+With --context N the source of the uncovered lines is printed with N lines
+of context, read from --snippet-root (default: --source-root). Only
+--source-root applies GCOVR_EXCL/LCOV_EXCL exclusion markers, so pass
+--snippet-root alone to get snippets without changing the coverage. A
+warning is printed when the source on disk does not match the gcovr/md5
+hashes in the report.
+
+With --functions-only, only the functions that were never called are
+listed, one per line, after a count of never-called, partially covered and
+fully covered functions. --sort and --top apply to that list; --context
+cannot be combined with it.
+
+Functions are listed by file in source order. --sort ranks them by the
+number of uncovered lines or by line coverage instead, across files, and
+--top keeps only the first N.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runUncovered,
 }
@@ -33,11 +55,29 @@ func init() {
 
 	uncoveredCmd.Flags().StringVarP(&uncoveredFilterFile, "filter", "f", "",
 		"Filter config file (YAML) to specify target files and functions")
+	uncoveredCmd.Flags().StringVar(&uncoveredSourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+	uncoveredCmd.Flags().StringVar(&uncoveredSnipRoot, "snippet-root", "",
+		"Source directory to read --context snippets from (default: --source-root)")
+	uncoveredCmd.Flags().IntVar(&uncoveredContext, "context", -1,
+		"Print the source of uncovered lines with N lines of context (-1 to disable)")
+	uncoveredCmd.Flags().BoolVar(&uncoveredFuncsOnly, "functions-only", false,
+		"List only the functions that were never called")
+	uncoveredCmd.Flags().StringVar(&uncoveredSort, "sort", gcovr.SortByLine,
+		"Sort order: file, line, uncovered or coverage")
+	uncoveredCmd.Flags().IntVar(&uncoveredTop, "top", 0,
+		"Show only the first N functions after sorting (0 for all)")
+
+	uncoveredCmd.MarkFlagsMutuallyExclusive("functions-only", "context")
 }
 
 func runUncovered(cmd *cobra.Command, args []string) error {
 	reportFile := args[0]
 
+	if err := gcovr.ValidateSortOrder(uncoveredSort); err != nil {
+		return err
+	}
+
 	// Parse the gcovr JSON report
 	fmt.Printf("Reading report: %s\n", reportFile)
 	report, err := gcovr.ParseReport(reportFile)
@@ -45,6 +85,11 @@ func runUncovered(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse report: %w", err)
 	}
 
+	report, err = applySourceExclusions(report, uncoveredSourceRoot, "")
+	if err != nil {
+		return err
+	}
+
 	// Apply filter if specified
 	if uncoveredFilterFile != "" {
 		fmt.Printf("Reading filter config: %s\n", uncoveredFilterFile)
@@ -54,8 +99,21 @@ func runUncovered(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("Filtering enabled: tracking %d file(s)\n", len(filterConfig.Targets))
-		report = gcovr.ApplyFilter(report, filterConfig)
 		fmt.Println("Applying filters...")
+		var matches *gcovr.FilterMatchReport
+		report, matches, err = gcovr.ApplyFilterWithMatches(report, filterConfig)
+		if err != nil {
+			return fmt.Errorf("failed to apply filter: %w", err)
+		}
+		printFilterWarnings("", matches)
+	}
+
+	if uncoveredFuncsOnly {
+		fmt.Println("Classifying functions...")
+		classification := gcovr.ClassifyFunctions(report)
+		classification.Sort(uncoveredSort)
+		fmt.Print(gcovr.FormatNeverCalledFunctions(classification, uncoveredTop))
+		return nil
 	}
 
 	// Find uncovered lines
@@ -65,6 +123,17 @@ func runUncovered(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to find uncovered lines: %w", err)
 	}
 
+	uncoveredReport.Sort(uncoveredSort)
+	if total := uncoveredReport.FunctionCount(); uncoveredTop > 0 && uncoveredTop < total {
+		uncoveredReport.Top(uncoveredTop)
+		fmt.Printf("Showing the top %d of %d function(s)\n", uncoveredTop, total)
+	}
+
+	if uncoveredContext >= 0 {
+		reader := sourceSnippetReader(report, snippetRoot(uncoveredSnipRoot, uncoveredSourceRoot))
+		printWarnings(reader.AddUncoveredSnippets(uncoveredReport, uncoveredContext))
+	}
+
 	// Display results
 	output := gcovr.FormatUncoveredReport(uncoveredReport)
 	fmt.Print(output)
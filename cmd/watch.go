@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zjy-dev/gcovr-json-util/v2/pkg/gcovr"
+)
+
+var (
+	watchFilterFile   string
+	watchSourceRoot   string
+	watchInterval     time.Duration
+	watchPattern      string
+	watchJSON         bool
+	watchState        string
+	watchSkipExisting bool
+	watchOnce         bool
+	watchVerbose      bool
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch [dir]",
+	Short: "Follow a directory of incoming reports and show new coverage",
+	Long: `Poll a directory that a fuzzer or test runner drops gcovr JSON reports
+into, add each new report to a running union of coverage, and print every
+report that covers lines or branches not seen before, with its contribution
+and the running totals.
+
+A report is read once its size and modification time stop changing between
+two scans, so partially written files are not read. Reports already in the
+directory are processed first unless --skip-existing is given.
+
+With --json each contributing report is printed as one JSON object per line.
+With --state the union is loaded from and saved to a file, so a restarted
+watch continues where it stopped. Stop watching with Ctrl-C.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringVarP(&watchFilterFile, "filter", "f", "",
+		"Filter config file (YAML) to specify target files and functions")
+	watchCmd.Flags().StringVar(&watchSourceRoot, "source-root", "",
+		"Source directory to scan for GCOVR_EXCL_*/LCOV_EXCL_* exclusion markers")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", time.Second,
+		"Time between directory scans")
+	watchCmd.Flags().StringVar(&watchPattern, "pattern", "*.json",
+		"File name glob of the reports to read")
+	watchCmd.Flags().BoolVar(&watchJSON, "json", false,
+		"Print contributing reports as JSON lines")
+	watchCmd.Flags().StringVar(&watchState, "state", "",
+		"File to load the coverage union from and save it to")
+	watchCmd.Flags().BoolVar(&watchSkipExisting, "skip-existing", false,
+		"Ignore reports already in the directory")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false,
+		"Process the reports currently in the directory and exit")
+	watchCmd.Flags().BoolVarP(&watchVerbose, "verbose", "v", false,
+		"List the new lines and branches of each contributing report")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	dir := args[0]
+
+	// Progress goes to stderr so --json output stays machine-readable
+	var filterConfig *gcovr.FilterConfig
+	if watchFilterFile != "" {
+		var err error
+		filterConfig, err = gcovr.ParseFilterConfig(watchFilterFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse filter config: %w", err)
+		}
+	}
+
+	acc := gcovr.NewAccumulator()
+	if watchState != "" {
+		if _, err := os.Stat(watchState); err == nil {
+			acc, err = gcovr.ReadAccumulator(watchState)
+			if err != nil {
+				return fmt.Errorf("failed to load state: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Loaded state %s: %d line(s), %d branch(es) covered\n",
+				watchState, acc.Lines(), acc.Branches())
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := gcovr.WatchOptions{
+		Interval:     watchInterval,
+		Pattern:      watchPattern,
+		SkipExisting: watchSkipExisting,
+		Once:         watchOnce,
+		Load: func(path string) (*gcovr.GcovrReport, error) {
+			return loadWatchedReport(path, filterConfig)
+		},
+		OnError: func(path string, err error) {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", path, err)
+		},
+	}
+
+	fmt.Fprintf(os.Stderr, "Watching %s for %s...\n", dir, watchPattern)
+	encoder := json.NewEncoder(os.Stdout)
+	err := gcovr.WatchDirectory(ctx, dir, acc, opts, func(event *gcovr.WatchEvent) error {
+		if watchJSON {
+			if err := encoder.Encode(event); err != nil {
+				return fmt.Errorf("failed to write event: %w", err)
+			}
+		} else {
+			fmt.Print(gcovr.FormatWatchEvent(event))
+			if watchVerbose {
+				for _, file := range event.Files {
+					fmt.Printf("   %s: lines %v, branches %v\n", file.FilePath, file.Lines, file.Branches)
+				}
+			}
+		}
+
+		if watchState != "" {
+			return acc.WriteFile(watchState)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Total: %d line(s), %d branch(es) covered\n", acc.Lines(), acc.Branches())
+	return nil
+}
+
+// loadWatchedReport parses a report and applies source exclusions and the
+// filter config without printing progress
+func loadWatchedReport(path string, filterConfig *gcovr.FilterConfig) (*gcovr.GcovrReport, error) {
+	report, err := gcovr.ParseReport(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if watchSourceRoot != "" {
+		report, _, err = gcovr.ApplyExclusions(report, watchSourceRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply exclusion markers: %w", err)
+		}
+	}
+
+	if filterConfig != nil {
+		report, _, err = gcovr.ApplyFilterWithMatches(report, filterConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply filter: %w", err)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,260 @@
+package gcovr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// accumulatorFormatVersion is written to saved accumulators and checked on load
+const accumulatorFormatVersion = 1
+
+// maxAccumulatorLine bounds the line numbers an Accumulator accepts, so that a
+// corrupted report cannot make a file's bitset grow without limit (2 MB each)
+const maxAccumulatorLine = 1 << 24
+
+// bitset is a growable set of small non-negative integers
+type bitset []uint64
+
+// add sets bit i and reports whether it was not set before
+func (b *bitset) add(i int) bool {
+	word := i / 64
+	if word >= len(*b) {
+		grown := make(bitset, word+1)
+		copy(grown, *b)
+		*b = grown
+	}
+	mask := uint64(1) << (i % 64)
+	if (*b)[word]&mask != 0 {
+		return false
+	}
+	(*b)[word] |= mask
+	return true
+}
+
+// has reports whether bit i is set
+func (b bitset) has(i int) bool {
+	word := i / 64
+	return word < len(b) && b[word]&(uint64(1)<<(i%64)) != 0
+}
+
+// count returns the number of set bits
+func (b bitset) count() int {
+	n := 0
+	for _, word := range b {
+		n += bits.OnesCount64(word)
+	}
+	return n
+}
+
+// fileBits holds the covered lines of a file, indexed by line number, and
+// the covered branches of each line, indexed by branch position
+type fileBits struct {
+	Lines    bitset         `json:"lines"`
+	Branches map[int]bitset `json:"branches,omitempty"`
+}
+
+// NewCoverage lists the lines and branches a report covered for the first time
+type NewCoverage struct {
+	Files    []FileContribution
+	Lines    int
+	Branches int
+}
+
+// Empty reports whether nothing new was covered
+func (c *NewCoverage) Empty() bool {
+	return c.Lines == 0 && c.Branches == 0
+}
+
+// Accumulator tracks the union of the coverage of every report added to it,
+// as a bitset of covered lines and branches per file. Adding a report costs
+// time proportional to the report, not to the coverage seen so far, which
+// suits fuzzing loops asking whether an input found anything new.
+// An Accumulator is not safe for concurrent use.
+type Accumulator struct {
+	files    map[string]*fileBits
+	lines    int
+	branches int
+}
+
+// NewAccumulator creates an empty Accumulator
+func NewAccumulator() *Accumulator {
+	return &Accumulator{files: make(map[string]*fileBits)}
+}
+
+// Lines returns the number of distinct lines covered so far
+func (a *Accumulator) Lines() int {
+	return a.lines
+}
+
+// Branches returns the number of distinct branches covered so far
+func (a *Accumulator) Branches() int {
+	return a.branches
+}
+
+// Add merges the coverage of a report into the accumulator and returns the
+// lines and branches it covered for the first time. Branches are identified
+// by their line and their position among the line's branches. Reports with
+// negative line numbers or line numbers above 16777216 are rejected.
+func (a *Accumulator) Add(report *GcovrReport) (*NewCoverage, error) {
+	if report == nil {
+		return nil, fmt.Errorf("report is nil")
+	}
+
+	// Validate first so a bad report leaves the accumulator unchanged
+	for _, file := range report.Files {
+		for _, line := range file.Lines {
+			if line.LineNumber < 0 || line.LineNumber > maxAccumulatorLine {
+				return nil, fmt.Errorf("%s: invalid line number %d (expected 0-%d)",
+					file.FilePath, line.LineNumber, maxAccumulatorLine)
+			}
+		}
+	}
+
+	result := &NewCoverage{Files: make([]FileContribution, 0)}
+	for _, file := range report.Files {
+		fb, ok := a.files[file.FilePath]
+		if !ok {
+			fb = &fileBits{}
+			a.files[file.FilePath] = fb
+		}
+
+		contribution := FileContribution{FilePath: file.FilePath, Lines: make([]int, 0), Branches: make([]BranchID, 0)}
+		for _, line := range file.Lines {
+			if line.Count > 0 && fb.Lines.add(line.LineNumber) {
+				contribution.Lines = append(contribution.Lines, line.LineNumber)
+			}
+			for i, branch := range line.Branches {
+				if branch.Count == 0 {
+					continue
+				}
+				if fb.Branches == nil {
+					fb.Branches = make(map[int]bitset)
+				}
+				taken := fb.Branches[line.LineNumber]
+				if taken.add(i) {
+					contribution.Branches = append(contribution.Branches, BranchID{Line: line.LineNumber, Index: i})
+				}
+				fb.Branches[line.LineNumber] = taken
+			}
+		}
+
+		if len(contribution.Lines) == 0 && len(contribution.Branches) == 0 {
+			continue
+		}
+		sort.Ints(contribution.Lines)
+		sortBranchIDs(contribution.Branches)
+		result.Files = append(result.Files, contribution)
+		result.Lines += len(contribution.Lines)
+		result.Branches += len(contribution.Branches)
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].FilePath < result.Files[j].FilePath
+	})
+
+	a.lines += result.Lines
+	a.branches += result.Branches
+	return result, nil
+}
+
+// addContribution marks the lines and branches of a file contribution as
+// covered, e.g. when replaying recorded history
+func (a *Accumulator) addContribution(contribution FileContribution) {
+	fb, ok := a.files[contribution.FilePath]
+	if !ok {
+		fb = &fileBits{}
+		a.files[contribution.FilePath] = fb
+	}
+	for _, line := range contribution.Lines {
+		if line >= 0 && line <= maxAccumulatorLine && fb.Lines.add(line) {
+			a.lines++
+		}
+	}
+	for _, branch := range contribution.Branches {
+		if branch.Line < 0 || branch.Line > maxAccumulatorLine || branch.Index < 0 || branch.Index > maxAccumulatorLine {
+			continue
+		}
+		if fb.Branches == nil {
+			fb.Branches = make(map[int]bitset)
+		}
+		taken := fb.Branches[branch.Line]
+		if taken.add(branch.Index) {
+			a.branches++
+		}
+		fb.Branches[branch.Line] = taken
+	}
+}
+
+// Covers reports whether a line of a file has been covered
+func (a *Accumulator) Covers(filePath string, lineNumber int) bool {
+	fb, ok := a.files[filePath]
+	return ok && lineNumber >= 0 && fb.Lines.has(lineNumber)
+}
+
+// accumulatorFile is the on-disk form of an Accumulator
+type accumulatorFile struct {
+	FormatVersion int                  `json:"format_version"`
+	Files         map[string]*fileBits `json:"files"`
+}
+
+// WriteFile saves the accumulator to a file. The file is replaced
+// atomically, so an interrupted write keeps the previous state.
+func (a *Accumulator) WriteFile(filePath string) error {
+	data, err := json.Marshal(accumulatorFile{FormatVersion: accumulatorFormatVersion, Files: a.files})
+	if err != nil {
+		return fmt.Errorf("failed to encode accumulator: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// ReadAccumulator loads an accumulator saved with WriteFile
+func ReadAccumulator(filePath string) (*Accumulator, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	var saved accumulatorFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse accumulator from %s: %w", filePath, err)
+	}
+	if saved.FormatVersion != accumulatorFormatVersion {
+		return nil, fmt.Errorf("unsupported accumulator format version %d in %s", saved.FormatVersion, filePath)
+	}
+
+	a := NewAccumulator()
+	for path, fb := range saved.Files {
+		if fb == nil {
+			continue
+		}
+		a.files[path] = fb
+		a.lines += fb.Lines.count()
+		for _, taken := range fb.Branches {
+			a.branches += taken.count()
+		}
+	}
+
+	return a, nil
+}
@@ -0,0 +1,140 @@
+package gcovr
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAccumulator_Add(t *testing.T) {
+	acc := NewAccumulator()
+
+	first, err := acc.Add(attributionReport([]int{1, 2, 10}, 0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.Lines != 3 || first.Branches != 1 || first.Empty() {
+		t.Errorf("Expected 3 new lines and 1 new branch, got %+v", first)
+	}
+
+	second, err := acc.Add(attributionReport([]int{2, 3, 10}, 0, 1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []FileContribution{{FilePath: "demo.cc", Lines: []int{3}, Branches: []BranchID{{Line: 10, Index: 1}}}}
+	if !reflect.DeepEqual(second.Files, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, second.Files)
+	}
+
+	third, err := acc.Add(attributionReport([]int{1, 3}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !third.Empty() || len(third.Files) != 0 {
+		t.Errorf("Expected nothing new, got %+v", third)
+	}
+
+	if acc.Lines() != 4 || acc.Branches() != 2 {
+		t.Errorf("Expected 4 lines and 2 branches, got %d and %d", acc.Lines(), acc.Branches())
+	}
+	if !acc.Covers("demo.cc", 3) || acc.Covers("demo.cc", 4) || acc.Covers("other.cc", 1) {
+		t.Error("Unexpected Covers result")
+	}
+}
+
+func TestAccumulator_LargeLineNumbers(t *testing.T) {
+	acc := NewAccumulator()
+	report := &GcovrReport{Files: []File{{FilePath: "big.cc", Lines: []Line{
+		{LineNumber: 63, Count: 1},
+		{LineNumber: 64, Count: 1},
+		{LineNumber: 100000, Count: 1},
+	}}}}
+
+	added, err := acc.Add(report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if added.Lines != 3 || !acc.Covers("big.cc", 100000) || acc.Covers("big.cc", 65) {
+		t.Errorf("Unexpected result: %+v", added)
+	}
+}
+
+func TestAccumulator_InvalidReport(t *testing.T) {
+	acc := NewAccumulator()
+	if _, err := acc.Add(nil); err == nil {
+		t.Error("Expected an error for a nil report")
+	}
+
+	tests := []struct {
+		name       string
+		lineNumber int
+	}{
+		{name: "Negative line number", lineNumber: -1},
+		{name: "Line number beyond the maximum", lineNumber: 2000000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &GcovrReport{Files: []File{{FilePath: "a.cc", Lines: []Line{
+				{LineNumber: 1, Count: 1},
+				{LineNumber: tt.lineNumber, Count: 1},
+			}}}}
+			if _, err := acc.Add(report); err == nil {
+				t.Errorf("Expected an error for line number %d", tt.lineNumber)
+			}
+			if acc.Lines() != 0 || acc.Covers("a.cc", 1) {
+				t.Error("Expected a rejected report to leave the accumulator unchanged")
+			}
+		})
+	}
+}
+
+func TestAccumulator_WriteAndRead(t *testing.T) {
+	acc := NewAccumulator()
+	acc.Add(attributionReport([]int{1, 7}, 1))
+
+	path := filepath.Join(t.TempDir(), "acc.json")
+	if err := acc.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write accumulator: %v", err)
+	}
+
+	loaded, err := ReadAccumulator(path)
+	if err != nil {
+		t.Fatalf("Failed to read accumulator: %v", err)
+	}
+	if loaded.Lines() != acc.Lines() || loaded.Branches() != acc.Branches() {
+		t.Errorf("Expected %d lines and %d branches, got %d and %d",
+			acc.Lines(), acc.Branches(), loaded.Lines(), loaded.Branches())
+	}
+
+	// Coverage seen before saving is not new after loading
+	added, err := loaded.Add(attributionReport([]int{1, 2}, 1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if added.Lines != 1 || added.Branches != 0 {
+		t.Errorf("Expected only line 2 to be new, got %+v", added)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestReadAccumulator_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := ReadAccumulator(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	path := filepath.Join(dir, "future.json")
+	os.WriteFile(path, []byte(`{"format_version": 99, "files": {}}`), 0644)
+	_, err := ReadAccumulator(path)
+	if err == nil || !strings.Contains(err.Error(), "unsupported accumulator format version 99") {
+		t.Errorf("Expected a version error, got %v", err)
+	}
+}
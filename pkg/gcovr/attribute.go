@@ -0,0 +1,204 @@
+package gcovr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BranchID identifies a branch by its line and its index among the line's branches
+type BranchID struct {
+	Line  int `json:"line"`
+	Index int `json:"index"`
+}
+
+// String returns the branch as "line#index", e.g. "42#1"
+func (b BranchID) String() string {
+	return fmt.Sprintf("%d#%d", b.Line, b.Index)
+}
+
+// sortBranchIDs orders branches by line, then by index
+func sortBranchIDs(branches []BranchID) {
+	sort.Slice(branches, func(i, j int) bool {
+		if branches[i].Line != branches[j].Line {
+			return branches[i].Line < branches[j].Line
+		}
+		return branches[i].Index < branches[j].Index
+	})
+}
+
+// FileContribution lists the lines and branches of one file a report
+// contributes: those only it covers (ComputeAttribution) or those it covers
+// for the first time (Accumulator.Add)
+type FileContribution struct {
+	FilePath string     `json:"file"`
+	Lines    []int      `json:"lines"`
+	Branches []BranchID `json:"branches"`
+}
+
+// ReportAttribution describes what one report covers and what it alone covers
+type ReportAttribution struct {
+	Name            string // Label of the report, usually its path
+	CoveredLines    int
+	CoveredBranches int
+	UniqueLines     int // Lines no other report covers
+	UniqueBranches  int // Branches no other report covers
+	Files           []FileContribution
+}
+
+// Contributes reports whether the report covers anything no other report covers
+func (a *ReportAttribution) Contributes() bool {
+	return a.UniqueLines > 0 || a.UniqueBranches > 0
+}
+
+// AttributionReport holds the unique contributions of a set of reports
+type AttributionReport struct {
+	Reports       []ReportAttribution // In input order
+	TotalLines    int                 // Lines covered by at least one report
+	TotalBranches int                 // Branches covered by at least one report
+}
+
+// Redundant returns the names of the reports that contribute nothing unique.
+// Each of them can be dropped on its own without losing coverage; dropping
+// several at once may lose lines only they cover together.
+func (r *AttributionReport) Redundant() []string {
+	names := make([]string, 0)
+	for _, a := range r.Reports {
+		if !a.Contributes() {
+			names = append(names, a.Name)
+		}
+	}
+	return names
+}
+
+// coverageKey identifies a covered line (Branch == -1) or branch across reports
+type coverageKey struct {
+	File   string
+	Line   int
+	Branch int
+}
+
+// coveredKeys returns the lines and branches a report covers. A line listed
+// under several functions (e.g. template instances) counts once.
+func coveredKeys(report *GcovrReport) map[coverageKey]bool {
+	keys := make(map[coverageKey]bool)
+	for _, file := range report.Files {
+		for _, line := range file.Lines {
+			if line.Count > 0 {
+				keys[coverageKey{File: file.FilePath, Line: line.LineNumber, Branch: -1}] = true
+			}
+			for i, branch := range line.Branches {
+				if branch.Count > 0 {
+					keys[coverageKey{File: file.FilePath, Line: line.LineNumber, Branch: i}] = true
+				}
+			}
+		}
+	}
+	return keys
+}
+
+// ComputeAttribution compares each report, e.g. one per test or fuzz seed,
+// against the union of all the others and records the lines and branches
+// only it covers. names labels the reports and must match them in length.
+func ComputeAttribution(names []string, reports []*GcovrReport) (*AttributionReport, error) {
+	if len(names) != len(reports) {
+		return nil, fmt.Errorf("got %d names for %d reports", len(names), len(reports))
+	}
+
+	result := &AttributionReport{Reports: make([]ReportAttribution, 0, len(reports))}
+
+	covered := make([]map[coverageKey]bool, len(reports))
+	coveredBy := make(map[coverageKey]int)
+	for i, report := range reports {
+		covered[i] = coveredKeys(report)
+		for key := range covered[i] {
+			coveredBy[key]++
+		}
+	}
+
+	for key := range coveredBy {
+		if key.Branch < 0 {
+			result.TotalLines++
+		} else {
+			result.TotalBranches++
+		}
+	}
+
+	for i, keys := range covered {
+		attribution := ReportAttribution{Name: names[i], Files: make([]FileContribution, 0)}
+		files := make(map[string]*FileContribution)
+
+		for key := range keys {
+			if key.Branch < 0 {
+				attribution.CoveredLines++
+			} else {
+				attribution.CoveredBranches++
+			}
+			if coveredBy[key] != 1 {
+				continue
+			}
+
+			file, ok := files[key.File]
+			if !ok {
+				file = &FileContribution{FilePath: key.File, Lines: make([]int, 0), Branches: make([]BranchID, 0)}
+				files[key.File] = file
+			}
+			if key.Branch < 0 {
+				attribution.UniqueLines++
+				file.Lines = append(file.Lines, key.Line)
+			} else {
+				attribution.UniqueBranches++
+				file.Branches = append(file.Branches, BranchID{Line: key.Line, Index: key.Branch})
+			}
+		}
+
+		for _, file := range files {
+			sort.Ints(file.Lines)
+			sortBranchIDs(file.Branches)
+			attribution.Files = append(attribution.Files, *file)
+		}
+		sort.Slice(attribution.Files, func(a, b int) bool {
+			return attribution.Files[a].FilePath < attribution.Files[b].FilePath
+		})
+
+		result.Reports = append(result.Reports, attribution)
+	}
+
+	return result, nil
+}
+
+// FormatAttributionReport formats the attribution report as a human-readable string
+func FormatAttributionReport(report *AttributionReport) string {
+	result := fmt.Sprintf("Coverage Attribution Report\n")
+	result += fmt.Sprintf("===========================\n\n")
+	result += fmt.Sprintf("%d report(s) cover %d line(s) and %d branch(es) together\n\n",
+		len(report.Reports), report.TotalLines, report.TotalBranches)
+
+	for i, a := range report.Reports {
+		result += fmt.Sprintf("%d. Report: %s\n", i+1, a.Name)
+		result += fmt.Sprintf("   Covered: %d line(s), %d branch(es)\n", a.CoveredLines, a.CoveredBranches)
+		result += fmt.Sprintf("   Unique: %d line(s), %d branch(es)\n", a.UniqueLines, a.UniqueBranches)
+		for _, file := range a.Files {
+			result += fmt.Sprintf("   File: %s\n", file.FilePath)
+			if len(file.Lines) > 0 {
+				result += fmt.Sprintf("      Lines: %v\n", file.Lines)
+			}
+			if len(file.Branches) > 0 {
+				result += fmt.Sprintf("      Branches: %v\n", file.Branches)
+			}
+		}
+		result += "\n"
+	}
+
+	redundant := report.Redundant()
+	if len(redundant) == 0 {
+		result += "Every report contributes unique coverage.\n"
+		return result
+	}
+
+	result += fmt.Sprintf("%d report(s) contribute nothing unique:\n", len(redundant))
+	for _, name := range redundant {
+		result += fmt.Sprintf("   %s\n", name)
+	}
+
+	return result
+}
@@ -0,0 +1,123 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// attributionReport builds a single-file report covering the given lines.
+// Line 10 has two branches; taken lists which of them are covered.
+func attributionReport(lines []int, taken ...int) *GcovrReport {
+	file := File{FilePath: "demo.cc"}
+	covered := make(map[int]bool)
+	for _, l := range lines {
+		covered[l] = true
+	}
+	for l := 1; l <= 10; l++ {
+		line := Line{LineNumber: l, FunctionName: "_Z1fv"}
+		if covered[l] {
+			line.Count = 1
+		}
+		if l == 10 {
+			line.Branches = []Branch{{}, {}}
+			for _, i := range taken {
+				line.Branches[i].Count = 1
+			}
+		}
+		file.Lines = append(file.Lines, line)
+	}
+	return &GcovrReport{Files: []File{file}}
+}
+
+func TestComputeAttribution(t *testing.T) {
+	reports := []*GcovrReport{
+		attributionReport([]int{1, 2, 3, 10}, 0),
+		attributionReport([]int{1, 2, 10}, 1),
+		attributionReport([]int{1, 2}),
+	}
+
+	attribution, err := ComputeAttribution([]string{"a.json", "b.json", "c.json"}, reports)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if attribution.TotalLines != 4 || attribution.TotalBranches != 2 {
+		t.Errorf("Expected 4 lines and 2 branches in total, got %d and %d",
+			attribution.TotalLines, attribution.TotalBranches)
+	}
+
+	a := attribution.Reports[0]
+	if a.CoveredLines != 4 || a.CoveredBranches != 1 || a.UniqueLines != 1 || a.UniqueBranches != 1 {
+		t.Errorf("Unexpected attribution for a.json: %+v", a)
+	}
+	expected := []FileContribution{{FilePath: "demo.cc", Lines: []int{3}, Branches: []BranchID{{Line: 10, Index: 0}}}}
+	if !reflect.DeepEqual(a.Files, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, a.Files)
+	}
+
+	b := attribution.Reports[1]
+	if b.UniqueLines != 0 || b.UniqueBranches != 1 || !b.Contributes() {
+		t.Errorf("Expected b.json to contribute only a branch, got %+v", b)
+	}
+
+	if redundant := attribution.Redundant(); !reflect.DeepEqual(redundant, []string{"c.json"}) {
+		t.Errorf("Expected c.json to be redundant, got %v", redundant)
+	}
+}
+
+func TestComputeAttribution_DuplicateReports(t *testing.T) {
+	// Identical reports cover nothing uniquely, so both are listed
+	reports := []*GcovrReport{attributionReport([]int{1}), attributionReport([]int{1})}
+
+	attribution, err := ComputeAttribution([]string{"a.json", "b.json"}, reports)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if redundant := attribution.Redundant(); len(redundant) != 2 {
+		t.Errorf("Expected both reports to be redundant, got %v", redundant)
+	}
+}
+
+func TestComputeAttribution_SharedTemplateLine(t *testing.T) {
+	// A line listed under two instances is one line
+	report := &GcovrReport{Files: []File{{FilePath: "t.h", Lines: []Line{
+		{LineNumber: 5, FunctionName: "_Z1fIiEvv", Count: 1},
+		{LineNumber: 5, FunctionName: "_Z1fIdEvv", Count: 0},
+	}}}}
+
+	attribution, err := ComputeAttribution([]string{"a.json"}, []*GcovrReport{report})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if attribution.TotalLines != 1 || attribution.Reports[0].UniqueLines != 1 {
+		t.Errorf("Expected one unique line, got %+v", attribution)
+	}
+}
+
+func TestComputeAttribution_NameMismatch(t *testing.T) {
+	if _, err := ComputeAttribution([]string{"a.json"}, nil); err == nil {
+		t.Error("Expected an error for mismatched names and reports")
+	}
+}
+
+func TestFormatAttributionReport(t *testing.T) {
+	reports := []*GcovrReport{attributionReport([]int{1, 2}, 1), attributionReport([]int{1})}
+	attribution, err := ComputeAttribution([]string{"a.json", "b.json"}, reports)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	output := FormatAttributionReport(attribution)
+	for _, want := range []string{
+		"2 report(s) cover 2 line(s) and 1 branch(es) together",
+		"   Unique: 1 line(s), 1 branch(es)\n",
+		"      Lines: [2]\n",
+		"      Branches: [10#1]\n",
+		"1 report(s) contribute nothing unique:\n   b.json\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
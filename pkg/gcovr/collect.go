@@ -0,0 +1,245 @@
+package gcovr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Coverage tools Collect can run after compiling the test input
+const (
+	// ToolGcovr runs gcovr and reads its JSON report
+	ToolGcovr = "gcovr"
+	// ToolGcov runs gcov in JSON mode directly and converts its output
+	ToolGcov = "gcov"
+)
+
+// CollectOptions controls how Collect compiles a test input and gathers coverage
+type CollectOptions struct {
+	Tool                 string   // ToolGcovr (default) or ToolGcov
+	GcovrPath            string   // gcovr executable, defaults to "gcovr"
+	GcovPath             string   // gcov executable, defaults to "gcov"
+	ExtraArgs            []string // Compiler arguments added after the config's compiler args
+	ResetCounters        bool     // Delete .gcda files under gcovr_exec_path before compiling
+	IgnoreCompilerErrors bool     // Collect coverage even if the compiler exits non-zero
+	Stdout               io.Writer
+	Stderr               io.Writer
+}
+
+// Collect compiles a test input with the compiler from the filter config and
+// returns the resulting coverage of the target files. The compiler is run as
+// "path args... input -o output" with the output placed in a temporary
+// directory; gcovr (or gcov) then runs inside gcovr_exec_path.
+//
+// Compiler and coverage tool output is discarded unless opts.Stdout and
+// opts.Stderr are set.
+func Collect(ctx context.Context, config *FilterConfig, input string, opts CollectOptions) (*GcovrReport, error) {
+	if config == nil || config.Compiler.Path == "" {
+		return nil, fmt.Errorf("filter config has no compiler.path")
+	}
+	if config.Compiler.GcovrExecPath == "" {
+		return nil, fmt.Errorf("filter config has no compiler.gcovr_exec_path")
+	}
+	if err := validateMatchMode(config.Match); err != nil {
+		return nil, err
+	}
+
+	tool := opts.Tool
+	if tool == "" {
+		tool = ToolGcovr
+	}
+	if tool != ToolGcovr && tool != ToolGcov {
+		return nil, fmt.Errorf("unknown coverage tool %q (expected %q or %q)", tool, ToolGcovr, ToolGcov)
+	}
+
+	execPath, err := filepath.Abs(config.Compiler.GcovrExecPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve gcovr_exec_path: %w", err)
+	}
+
+	if opts.ResetCounters {
+		if err := ResetCounters(execPath); err != nil {
+			return nil, err
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gcovr-util-collect-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := runCompiler(ctx, config, input, filepath.Join(tmpDir, "out"), opts); err != nil {
+		return nil, err
+	}
+
+	var report *GcovrReport
+	if tool == ToolGcov {
+		report, err = runGcov(ctx, execPath, opts)
+	} else {
+		report, err = runGcovr(ctx, config, execPath, filepath.Join(tmpDir, "coverage.json"), opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return restrictToTargetFiles(report, config), nil
+}
+
+// ResetCounters deletes every .gcda file below dir so the next run starts
+// from zero coverage
+func ResetCounters(dir string) error {
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".gcda") {
+			return os.Remove(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset coverage counters in %s: %w", dir, err)
+	}
+	return nil
+}
+
+// runCompiler compiles the test input with the configured compiler
+func runCompiler(ctx context.Context, config *FilterConfig, input, output string, opts CollectOptions) error {
+	args := append([]string{}, config.Compiler.Args...)
+	args = append(args, opts.ExtraArgs...)
+	args = append(args, input, "-o", output)
+
+	cmd := exec.CommandContext(ctx, config.Compiler.Path, args...)
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	if err := cmd.Run(); err != nil {
+		if _, exited := err.(*exec.ExitError); exited && opts.IgnoreCompilerErrors {
+			return nil
+		}
+		return fmt.Errorf("failed to compile %s: %w", input, err)
+	}
+	return nil
+}
+
+// runGcovr runs gcovr in execPath, restricted to the target files, and parses its JSON report
+func runGcovr(ctx context.Context, config *FilterConfig, execPath, output string, opts CollectOptions) (*GcovrReport, error) {
+	gcovrPath := opts.GcovrPath
+	if gcovrPath == "" {
+		gcovrPath = "gcovr"
+	}
+
+	args := []string{"--json", output}
+	if opts.GcovPath != "" {
+		args = append(args, "--gcov-executable", opts.GcovPath)
+	}
+	for _, filter := range gcovrFilterArgs(config) {
+		args = append(args, "--filter", filter)
+	}
+
+	cmd := exec.CommandContext(ctx, gcovrPath, args...)
+	cmd.Dir = execPath
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run gcovr in %s: %w", execPath, err)
+	}
+
+	return ParseReport(output)
+}
+
+// gcovrFilterArgs builds one gcovr --filter regex per target file. gcovr matches
+// relative filters against paths relative to its working directory, so the
+// target is allowed to follow any leading directories.
+func gcovrFilterArgs(config *FilterConfig) []string {
+	filters := make([]string, 0, len(config.Targets))
+	for _, target := range config.Targets {
+		filters = append(filters, "(.*/)?"+regexp.QuoteMeta(normalizeFilePath(target.File))+"$")
+	}
+	return filters
+}
+
+// runGcov runs gcov in JSON mode on every .gcda file below execPath and
+// converts the output into a single report
+func runGcov(ctx context.Context, execPath string, opts CollectOptions) (*GcovrReport, error) {
+	gcovPath := opts.GcovPath
+	if gcovPath == "" {
+		gcovPath = "gcov"
+	}
+
+	// gcov finds the .gcno notes next to each .gcda, so run it per directory
+	dataFiles := make(map[string][]string)
+	err := filepath.WalkDir(execPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".gcda") {
+			dir := filepath.Dir(path)
+			dataFiles[dir] = append(dataFiles[dir], filepath.Base(path))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find coverage data in %s: %w", execPath, err)
+	}
+
+	dirs := make([]string, 0, len(dataFiles))
+	for dir := range dataFiles {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	report := &GcovrReport{Files: make([]File, 0)}
+	for _, dir := range dirs {
+		var stdout bytes.Buffer
+		args := append([]string{"--json-format", "--stdout", "--branch-probabilities"}, dataFiles[dir]...)
+		cmd := exec.CommandContext(ctx, gcovPath, args...)
+		cmd.Dir = dir
+		cmd.Stdout = &stdout
+		cmd.Stderr = opts.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to run gcov in %s: %w", dir, err)
+		}
+
+		if err := parseGcovJSON(&stdout, execPath, report); err != nil {
+			return nil, fmt.Errorf("failed to parse gcov output in %s: %w", dir, err)
+		}
+	}
+
+	sortReportFiles(report)
+	return report, nil
+}
+
+// restrictToTargetFiles keeps only the report files that resolve to a filter target
+func restrictToTargetFiles(report *GcovrReport, config *FilterConfig) *GcovrReport {
+	if len(config.Targets) == 0 {
+		return report
+	}
+
+	filterMap := make(map[string]int)
+	for i, target := range config.Targets {
+		filterMap[normalizeFilePath(target.File)] = i
+	}
+
+	restricted := &GcovrReport{
+		FormatVersion: report.FormatVersion,
+		Files:         make([]File, 0),
+	}
+	for _, file := range report.Files {
+		if _, ok := resolveTargetFile(file.FilePath, filterMap, config.Match); ok {
+			restricted.Files = append(restricted.Files, file)
+		}
+	}
+	return restricted
+}
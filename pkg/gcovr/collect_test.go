@@ -0,0 +1,163 @@
+package gcovr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeScript creates an executable shell script in dir
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestCollect_Gcovr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as fake tools")
+	}
+
+	dir := t.TempDir()
+	buildDir := filepath.Join(dir, "build")
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatalf("Failed to create build dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(buildDir, "old.gcda"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write old.gcda: %v", err)
+	}
+
+	// The fake compiler records its arguments; the fake gcovr records its
+	// arguments and working directory and writes a two-file report
+	compiler := writeScript(t, dir, "cc", `echo "$@" > `+filepath.Join(dir, "cc.args")+"\n")
+	gcovr := writeScript(t, dir, "gcovr", `pwd > `+filepath.Join(dir, "gcovr.pwd")+`
+echo "$@" > `+filepath.Join(dir, "gcovr.args")+`
+cat > "$2" <<'JSON'
+{"gcovr/format_version": "0.14", "files": [
+  {"file": "../src/demo.cc", "lines": [{"line_number": 5, "function_name": "_Z1fv", "count": 1}], "functions": []},
+  {"file": "../src/other.cc", "lines": [], "functions": []}
+]}
+JSON
+`)
+
+	config := &FilterConfig{Targets: []TargetFile{{File: "demo.cc"}}}
+	config.Compiler.Path = compiler
+	config.Compiler.Args = []string{"-c"}
+	config.Compiler.GcovrExecPath = buildDir
+
+	report, err := Collect(context.Background(), config, "input.c", CollectOptions{
+		GcovrPath:     gcovr,
+		ExtraArgs:     []string{"-O2"},
+		ResetCounters: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(report.Files) != 1 || report.Files[0].FilePath != "../src/demo.cc" {
+		t.Errorf("Expected only ../src/demo.cc, got %+v", report.Files)
+	}
+
+	ccArgs, _ := os.ReadFile(filepath.Join(dir, "cc.args"))
+	if !strings.HasPrefix(string(ccArgs), "-c -O2 input.c -o ") {
+		t.Errorf("Unexpected compiler arguments: %q", ccArgs)
+	}
+
+	gcovrArgs, _ := os.ReadFile(filepath.Join(dir, "gcovr.args"))
+	if !strings.Contains(string(gcovrArgs), "--filter (.*/)?demo\\.cc$") {
+		t.Errorf("Expected gcovr to be restricted to demo.cc, got %q", gcovrArgs)
+	}
+
+	pwd, _ := os.ReadFile(filepath.Join(dir, "gcovr.pwd"))
+	if resolved, _ := filepath.EvalSymlinks(buildDir); strings.TrimSpace(string(pwd)) != resolved {
+		t.Errorf("Expected gcovr to run in %s, got %q", resolved, pwd)
+	}
+
+	if _, err := os.Stat(filepath.Join(buildDir, "old.gcda")); !os.IsNotExist(err) {
+		t.Error("Expected existing .gcda files to be removed")
+	}
+}
+
+func TestCollect_CompilerFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as fake tools")
+	}
+
+	dir := t.TempDir()
+	compiler := writeScript(t, dir, "cc", "exit 1\n")
+	gcovr := writeScript(t, dir, "gcovr", `echo '{"files": []}' > "$2"`+"\n")
+
+	config := &FilterConfig{}
+	config.Compiler.Path = compiler
+	config.Compiler.GcovrExecPath = dir
+
+	if _, err := Collect(context.Background(), config, "bad.c", CollectOptions{GcovrPath: gcovr}); err == nil {
+		t.Error("Expected error when the compiler fails")
+	}
+
+	if _, err := Collect(context.Background(), config, "bad.c", CollectOptions{
+		GcovrPath:            gcovr,
+		IgnoreCompilerErrors: true,
+	}); err != nil {
+		t.Errorf("Expected compiler errors to be ignored, got %v", err)
+	}
+}
+
+func TestCollect_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *FilterConfig
+		opts   CollectOptions
+	}{
+		{name: "Nil config", config: nil},
+		{name: "No compiler", config: &FilterConfig{}},
+		{name: "Unknown tool", config: func() *FilterConfig {
+			c := &FilterConfig{}
+			c.Compiler.Path = "cc"
+			c.Compiler.GcovrExecPath = "."
+			return c
+		}(), opts: CollectOptions{Tool: "llvm-cov"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Collect(context.Background(), tt.config, "input.c", tt.opts); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestGcovrFilterArgs(t *testing.T) {
+	config := &FilterConfig{Targets: []TargetFile{{File: "gcc/expr.cc"}, {File: "./i386.cc"}}}
+
+	filters := gcovrFilterArgs(config)
+	if len(filters) != 2 {
+		t.Fatalf("Expected 2 filters, got %v", filters)
+	}
+
+	re := regexp.MustCompile("^" + filters[0])
+	for path, want := range map[string]bool{
+		"gcc/expr.cc":          true,
+		"../../gcc/expr.cc":    true,
+		"gcc/cp/expr.cc":       false,
+		"gcc/expr.cc.orig":     false,
+		"../gcc/gcc/expr.cc":   true,
+		"libcpp/gcc/expr_c.cc": false,
+	} {
+		if got := re.MatchString(path); got != want {
+			t.Errorf("filter %q on %q: expected %v, got %v", filters[0], path, want, got)
+		}
+	}
+
+	if filters[1] != `(.*/)?i386\.cc$` {
+		t.Errorf("Expected the target path to be normalized, got %q", filters[1])
+	}
+}
@@ -0,0 +1,285 @@
+package gcovr
+
+import (
+	"strings"
+)
+
+// cppName is a demangled C++ function name split into the parts used for matching
+type cppName struct {
+	Components []string // Qualified name split on "::", e.g. ["ns", "Foo<int>", "bar"]
+	Params     string   // Parameter list without the enclosing parentheses
+	HasParams  bool     // Whether a parameter list was present at all
+}
+
+// trailingQualifiers are the qualifiers a demangler may print after the parameter list
+var trailingQualifiers = []string{"const", "volatile", "&&", "&", "noexcept"}
+
+// parseCppName splits a demangled C++ name (or a filter pattern written like one)
+// into its qualified name components and parameter list. Return types, trailing
+// cv/ref qualifiers, GCC clone suffixes and operator names such as "operator<"
+// and "operator()" are handled; anything unparseable ends up as a single component.
+func parseCppName(name string) cppName {
+	name = stripTrailingQualifiers(stripCloneSuffixes(strings.TrimSpace(name)))
+
+	var result cppName
+	if open := matchingOpenParen(name); open != -1 && !endsWithOperatorKeyword(name[:open]) {
+		result.Params = strings.TrimSpace(name[open+1 : len(name)-1])
+		result.HasParams = true
+		name = name[:open]
+	}
+
+	// "operator" names may contain <, >, ( and ) so keep them out of the scan
+	opName := ""
+	if idx := operatorKeywordIndex(name); idx != -1 {
+		name, opName = name[:idx], name[idx:]
+	}
+
+	name = stripReturnType(name)
+	result.Components = splitTopLevel(name, "::")
+	if opName != "" {
+		if n := len(result.Components); n > 0 && result.Components[n-1] == "" {
+			result.Components = result.Components[:n-1]
+		}
+		result.Components = append(result.Components, opName)
+	}
+
+	return result
+}
+
+// stripCloneSuffixes removes the " [clone .cold]", " [clone .isra.0]" or
+// " [clone .constprop.0]" suffixes GCC appends to the names of cloned functions.
+// Clones of clones carry several suffixes.
+func stripCloneSuffixes(name string) string {
+	for strings.HasSuffix(name, "]") {
+		idx := strings.LastIndex(name, "[clone ")
+		if idx == -1 || strings.Contains(name[idx:len(name)-1], "]") {
+			break
+		}
+		name = strings.TrimSpace(name[:idx])
+	}
+	return name
+}
+
+// stripTrailingQualifiers removes cv/ref qualifiers following the parameter list.
+// Names without a parameter list, such as "operator&", are returned unchanged.
+func stripTrailingQualifiers(name string) string {
+	rest := name
+	for {
+		stripped := false
+		for _, q := range trailingQualifiers {
+			if !strings.HasSuffix(rest, q) {
+				continue
+			}
+			before := rest[:len(rest)-len(q)]
+			if isIdentChar(q[0]) && before != "" && isIdentChar(before[len(before)-1]) {
+				continue // part of a longer identifier
+			}
+			rest = strings.TrimSpace(before)
+			stripped = true
+			break
+		}
+		if !stripped {
+			break
+		}
+	}
+
+	if strings.HasSuffix(rest, ")") {
+		return rest
+	}
+	return name
+}
+
+// matchingOpenParen returns the index of the '(' matching a trailing ')', or -1
+func matchingOpenParen(name string) int {
+	if !strings.HasSuffix(name, ")") {
+		return -1
+	}
+
+	depth := 0
+	for i := len(name) - 1; i >= 0; i-- {
+		switch name[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// endsWithOperatorKeyword reports whether a name ends in the bare "operator"
+// keyword, meaning a following "()" is the call operator rather than parameters
+func endsWithOperatorKeyword(name string) bool {
+	idx := operatorKeywordIndex(name)
+	return idx != -1 && strings.TrimSpace(name[idx:]) == "operator"
+}
+
+// operatorKeywordIndex returns the index of an "operator" keyword that starts
+// a name component, or -1 when the name is not an operator
+func operatorKeywordIndex(name string) int {
+	const keyword = "operator"
+	for start := 0; ; {
+		idx := strings.Index(name[start:], keyword)
+		if idx == -1 {
+			return -1
+		}
+		idx += start
+
+		end := idx + len(keyword)
+		atBoundary := idx == 0 || name[idx-1] == ':' || name[idx-1] == ' '
+		if atBoundary && (end == len(name) || !isIdentChar(name[end])) {
+			return idx
+		}
+		start = end
+	}
+}
+
+// stripReturnType drops a leading return type, which demanglers print for
+// function templates ("int foo<int>(int)")
+func stripReturnType(name string) string {
+	depth := 0
+	last := -1
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '<', '(', '[':
+			depth++
+		case '>', ')', ']':
+			depth--
+		case ' ':
+			if depth == 0 {
+				last = i
+			}
+		}
+	}
+
+	if last == -1 {
+		return name
+	}
+	return name[last+1:]
+}
+
+// splitTopLevel splits a name on sep, ignoring separators nested in <>, () or []
+func splitTopLevel(name, sep string) []string {
+	parts := make([]string, 0)
+	depth := 0
+	start := 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '<', '(', '[':
+			depth++
+		case '>', ')', ']':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(name[i:], sep) {
+				parts = append(parts, name[start:i])
+				i += len(sep) - 1
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, name[start:])
+}
+
+// stripTemplateArgs removes top-level template argument lists from a name component
+func stripTemplateArgs(component string) string {
+	if strings.HasPrefix(component, "operator") {
+		// Only a template argument list after the operator symbol can be dropped,
+		// e.g. "operator<< <int>"
+		if idx := strings.LastIndex(component, " <"); idx != -1 && strings.HasSuffix(component, ">") {
+			return component[:idx]
+		}
+		return component
+	}
+
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(component); i++ {
+		switch c := component[i]; {
+		case c == '<':
+			depth++
+		case c == '>' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// normalizeCppSpaces removes whitespace that is not needed to separate identifiers
+func normalizeCppSpaces(s string) string {
+	fields := strings.Fields(s)
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 && isIdentChar(fields[i-1][len(fields[i-1])-1]) && isIdentChar(f[0]) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f)
+	}
+	return b.String()
+}
+
+// isIdentChar reports whether c can appear in a C++ identifier
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// matchCppPattern checks a filter pattern against a demangled function name.
+//
+//   - "bar" and "Foo::bar" match any function whose qualified name ends in
+//     those components, so both match "ns::Foo::bar(int)"
+//   - template arguments are ignored unless the pattern spells them out:
+//     "foo" matches "foo<int>(int)" but "foo<long>" does not
+//   - a parameter list selects an overload: "bar(int)" matches "bar(int)" only
+//   - "Foo::*" matches every function directly inside Foo
+func matchCppPattern(pattern, demangledName string) bool {
+	if pattern == "" || demangledName == "" {
+		return false
+	}
+
+	p := parseCppName(pattern)
+	d := parseCppName(demangledName)
+
+	if len(p.Components) > len(d.Components) {
+		return false
+	}
+
+	offset := len(d.Components) - len(p.Components)
+	for i, pc := range p.Components {
+		if !matchCppComponent(pc, d.Components[offset+i], i == len(p.Components)-1) {
+			return false
+		}
+	}
+
+	if p.HasParams && normalizeParams(p.Params) != normalizeParams(d.Params) {
+		return false
+	}
+
+	return true
+}
+
+// matchCppComponent compares one qualified name component of a pattern and a name
+func matchCppComponent(pattern, component string, last bool) bool {
+	if last && pattern == "*" {
+		return true
+	}
+
+	pattern = normalizeCppSpaces(pattern)
+
+	if strings.Contains(pattern, "<") && !strings.HasPrefix(pattern, "operator") {
+		return pattern == normalizeCppSpaces(component)
+	}
+	return pattern == normalizeCppSpaces(stripTemplateArgs(component))
+}
+
+// normalizeParams normalizes a parameter list for comparison
+func normalizeParams(params string) string {
+	params = normalizeCppSpaces(params)
+	if params == "void" {
+		return ""
+	}
+	return params
+}
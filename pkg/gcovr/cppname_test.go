@@ -0,0 +1,142 @@
+package gcovr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCppName(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		components []string
+		params     string
+		hasParams  bool
+	}{
+		{
+			name:       "Plain C function",
+			input:      "main",
+			components: []string{"main"},
+		},
+		{
+			name:       "Qualified method with const",
+			input:      "ns::Foo::bar(int) const",
+			components: []string{"ns", "Foo", "bar"},
+			params:     "int",
+			hasParams:  true,
+		},
+		{
+			name:       "Function template with return type",
+			input:      "int foo<int>(int)",
+			components: []string{"foo<int>"},
+			params:     "int",
+			hasParams:  true,
+		},
+		{
+			name:       "Call operator",
+			input:      "Foo::operator()(int) const",
+			components: []string{"Foo", "operator()"},
+			params:     "int",
+			hasParams:  true,
+		},
+		{
+			name:       "Call operator pattern without parameters",
+			input:      "operator()",
+			components: []string{"operator()"},
+		},
+		{
+			name:       "Template operator with return type",
+			input:      "std::ostream& operator<< <int>(std::ostream&, Foo<int> const&)",
+			components: []string{"operator<< <int>"},
+			params:     "std::ostream&, Foo<int> const&",
+			hasParams:  true,
+		},
+		{
+			name:       "Anonymous namespace",
+			input:      "(anonymous namespace)::foo(int)",
+			components: []string{"(anonymous namespace)", "foo"},
+			params:     "int",
+			hasParams:  true,
+		},
+		{
+			name:       "Conversion operator",
+			input:      "Foo::operator bool() const",
+			components: []string{"Foo", "operator bool"},
+			hasParams:  true,
+		},
+		{
+			name:       "Clone suffix",
+			input:      "ns::Foo::bar(int) [clone .cold]",
+			components: []string{"ns", "Foo", "bar"},
+			params:     "int",
+			hasParams:  true,
+		},
+		{
+			name:       "Clone suffixes after qualifiers",
+			input:      "ns::Foo::bar(int) const [clone .isra.0] [clone .constprop.0]",
+			components: []string{"ns", "Foo", "bar"},
+			params:     "int",
+			hasParams:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := parseCppName(tt.input)
+
+			if !reflect.DeepEqual(result.Components, tt.components) {
+				t.Errorf("Expected components %q, got %q", tt.components, result.Components)
+			}
+			if result.Params != tt.params {
+				t.Errorf("Expected params %q, got %q", tt.params, result.Params)
+			}
+			if result.HasParams != tt.hasParams {
+				t.Errorf("Expected HasParams=%v, got %v", tt.hasParams, result.HasParams)
+			}
+		})
+	}
+}
+
+func TestMatchCppPattern(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		demangledName string
+		expected      bool
+	}{
+		{"Unqualified name", "bar", "ns::Foo::bar(int)", true},
+		{"Qualified suffix", "Foo::bar", "ns::Foo::bar(int)", true},
+		{"Fully qualified", "ns::Foo::bar", "ns::Foo::bar(int)", true},
+		{"Wrong class", "Baz::bar", "ns::Foo::bar(int)", false},
+		{"Partial component", "oo::bar", "ns::Foo::bar(int)", false},
+		{"Overload match", "bar(int)", "ns::Foo::bar(int) const", true},
+		{"Overload mismatch", "bar(double)", "ns::Foo::bar(int)", false},
+		{"Overload whitespace", "bar(char const *, int)", "bar(char const*, int)", true},
+		{"Void parameters", "bar(void)", "bar()", true},
+		{"Template agnostic", "foo", "int foo<int>(int)", true},
+		{"Template class agnostic", "Vec::push", "Vec<int>::push(int)", true},
+		{"Explicit template args", "foo<int>", "int foo<int>(int)", true},
+		{"Explicit template mismatch", "foo<long>", "int foo<int>(int)", false},
+		{"All methods of class", "Foo::*", "ns::Foo::bar(int)", true},
+		{"All methods of other class", "Foo::*", "ns::Baz::bar(int)", false},
+		{"Wildcard does not match nested class", "Foo::*", "Foo::Inner::bar()", false},
+		{"Call operator", "Foo::operator()", "Foo::operator()(int) const", true},
+		{"Comparison operator", "operator<", "bool operator<(Foo const&, Foo const&)", true},
+		{"Stream operator template", "operator<<", "std::ostream& operator<< <int>(std::ostream&, Foo<int> const&)", true},
+		{"Operator does not match other operator", "operator<", "bool operator<=(Foo const&, Foo const&)", false},
+		{"Empty pattern", "", "foo()", false},
+		{"Cold clone", "bar", "ns::Foo::bar(int) [clone .cold]", true},
+		{"Clone overload match", "Foo::bar(int)", "ns::Foo::bar(int) [clone .isra.0]", true},
+		{"Clone of clone", "bar", "bar() [clone .constprop.0] [clone .cold]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := matchCppPattern(tt.pattern, tt.demangledName)
+			if result != tt.expected {
+				t.Errorf("matchCppPattern(%q, %q) = %v, expected %v",
+					tt.pattern, tt.demangledName, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,1333 @@
+package gcovr
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotMangled is returned by Demangle for names that are not Itanium C++ ABI symbols
+var ErrNotMangled = errors.New("not an Itanium C++ mangled name")
+
+// Demangle converts an Itanium C++ ABI mangled symbol such as "_ZN2ns3Foo3barEi"
+// into the form printed by c++filt ("ns::Foo::bar(int)"). It supports the
+// constructs gcc and clang emit for ordinary code: nested and local names,
+// templates, substitutions, operators, constructors and destructors, lambdas,
+// special names (vtables, thunks, guard variables) and clone suffixes.
+func Demangle(name string) (result string, err error) {
+	if !strings.HasPrefix(name, "_Z") {
+		return "", ErrNotMangled
+	}
+
+	// A malformed symbol must not take down report parsing, so any indexing
+	// bug the parser still has is reported as a demangling error
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = "", fmt.Errorf("failed to demangle %s: %v", name, r)
+		}
+	}()
+
+	d := &demangler{s: name, pos: 2}
+	result, err = d.parseTopLevel()
+	if err != nil {
+		return "", fmt.Errorf("failed to demangle %s: %w", name, err)
+	}
+	if result == "" {
+		return "", fmt.Errorf("failed to demangle %s: empty name", name)
+	}
+
+	return result, nil
+}
+
+// DemangleName returns the demangled form of name, or name itself when it
+// is not a mangled symbol or cannot be demangled
+func DemangleName(name string) string {
+	demangled, err := Demangle(name)
+	if err != nil {
+		return name
+	}
+	return demangled
+}
+
+// functionDisplayName returns the demangled name of a function, demangling
+// the mangled name when the report did not provide one
+func functionDisplayName(mangledName, demangledName string) string {
+	if demangledName != "" {
+		return demangledName
+	}
+	return DemangleName(mangledName)
+}
+
+// demangledType is a demangled type. Function and array types are printed
+// inside out, so pointers and references to them are kept in inner, as in
+// "void (*)(int)" or "int (&) [3]".
+type demangledType struct {
+	base     string // Everything left of the declarator, e.g. "void"
+	inner    string // Declarator for function and array types, e.g. "*"
+	suffix   string // Parameter list or array bound, e.g. "(int)" or " [3]"
+	compound bool   // Whether this is a function or array type
+	isFunc   bool   // Whether this is a function type
+	ctor     string // Constructor name for standard substitutions, e.g. "basic_string"
+	full     string // Expansion of standard substitutions used before constructors
+	pack     []demangledType
+
+	// A function type returning a function pointer wraps its declarator
+	// between pre and post: "int (*(*)())()" has pre "*" and post "()"
+	pre, post string
+}
+
+// String formats the type
+func (t demangledType) String() string {
+	if t.pack != nil {
+		parts := make([]string, len(t.pack))
+		for i, p := range t.pack {
+			parts[i] = p.String()
+		}
+		return strings.Join(parts, ", ")
+	}
+	if !t.compound {
+		return t.base
+	}
+	if t.pre != "" || t.post != "" {
+		mid := t.pre
+		if t.inner != "" {
+			mid += "(" + t.inner + ")"
+		}
+		return t.base + " (" + mid + t.post + ")" + t.suffix
+	}
+	if t.inner != "" {
+		return t.base + " (" + t.inner + ")" + t.suffix
+	}
+	if t.isFunc {
+		return t.base + " " + t.suffix
+	}
+	return t.base + t.suffix
+}
+
+// simpleType wraps a plain type name
+func simpleType(name string) demangledType {
+	return demangledType{base: name}
+}
+
+// withDeclarator applies a pointer, reference or cv qualifier to a type
+func (t demangledType) withDeclarator(decl string) demangledType {
+	if t.pack != nil {
+		pack := make([]demangledType, len(t.pack))
+		for i := range t.pack {
+			pack[i] = t.pack[i].withDeclarator(decl)
+		}
+		t.pack = pack
+		return t
+	}
+
+	// cv-qualifying an array qualifies its elements: "char const [3]"
+	isQualifier := decl[0] >= 'a' && decl[0] <= 'z'
+	if t.compound && !t.isFunc && t.inner == "" && isQualifier {
+		t.base += " " + decl
+		return t
+	}
+
+	if t.compound {
+		if collapsed, ok := collapseReference(t.inner, decl); ok {
+			t.inner = collapsed
+			return t
+		}
+		t.inner += decl
+		return t
+	}
+
+	if collapsed, ok := collapseReference(t.base, decl); ok {
+		t.base = collapsed
+		return t
+	}
+
+	// Qualifiers are separated by a space ("char const"), pointers are not
+	if isQualifier {
+		t.base += " " + decl
+	} else {
+		t.base += decl
+	}
+	return t
+}
+
+// collapseReference applies C++ reference collapsing when a reference is
+// applied to a reference: the result is && only if both are &&
+func collapseReference(declarator, decl string) (string, bool) {
+	if decl != "&" && decl != "&&" {
+		return "", false
+	}
+	if strings.HasSuffix(declarator, "&&") {
+		return strings.TrimSuffix(declarator, "&&") + decl, true
+	}
+	if strings.HasSuffix(declarator, "&") {
+		return declarator, true
+	}
+	return "", false
+}
+
+// demangledName is a parsed <name> together with the facts needed to format
+// the function encoding it belongs to
+type demangledName struct {
+	name         string
+	hasTemplate  bool   // Ends in template arguments, so a return type follows
+	isCtorDtor   bool   // Constructors and destructors have no return type
+	isConversion bool   // Conversion operators have no return type
+	qualifiers   string // Method cv and ref qualifiers, e.g. " const"
+	last         string // Unqualified last component, used for constructor names
+}
+
+// demangler holds the parsing state for a single symbol
+type demangler struct {
+	s            string
+	pos          int
+	subs         []demangledType
+	templateArgs []demangledType
+	templDepth   int
+	typeDepth    int // Template arguments seen inside types do not bind T_
+}
+
+// Builtin type codes
+var builtinTypes = map[byte]string{
+	'v': "void", 'w': "wchar_t", 'b': "bool", 'c': "char", 'a': "signed char",
+	'h': "unsigned char", 's': "short", 't': "unsigned short", 'i': "int",
+	'j': "unsigned int", 'l': "long", 'm': "unsigned long", 'x': "long long",
+	'y': "unsigned long long", 'n': "__int128", 'o': "unsigned __int128",
+	'f': "float", 'd': "double", 'e': "long double", 'g': "__float128", 'z': "...",
+}
+
+// Builtin type codes prefixed with D
+var builtinDTypes = map[byte]string{
+	'n': "decltype(nullptr)", 'i': "char32_t", 's': "char16_t", 'u': "char8_t",
+	'a': "auto", 'c': "decltype(auto)", 'f': "decimal32", 'd': "decimal64",
+	'e': "decimal128", 'h': "half",
+}
+
+// Operator codes and their names
+var operatorNames = map[string]string{
+	"nw": "new", "na": "new[]", "dl": "delete", "da": "delete[]",
+	"ps": "+", "ng": "-", "ad": "&", "de": "*", "co": "~",
+	"pl": "+", "mi": "-", "ml": "*", "dv": "/", "rm": "%", "an": "&", "or": "|", "eo": "^",
+	"aS": "=", "pL": "+=", "mI": "-=", "mL": "*=", "dV": "/=", "rM": "%=",
+	"aN": "&=", "oR": "|=", "eO": "^=", "ls": "<<", "rs": ">>", "lS": "<<=", "rS": ">>=",
+	"eq": "==", "ne": "!=", "lt": "<", "gt": ">", "le": "<=", "ge": ">=", "ss": "<=>",
+	"nt": "!", "aa": "&&", "oo": "||", "pp": "++", "mm": "--", "cm": ",",
+	"pm": "->*", "pt": "->", "cl": "()", "ix": "[]", "qu": "?", "aw": "co_await",
+}
+
+// Standard substitutions. Like gcov, the short name is printed except in
+// front of a constructor or destructor, where the full expansion is used.
+var stdSubstitutions = map[byte]struct{ name, full, ctor string }{
+	'a': {"std::allocator", "std::allocator", "allocator"},
+	'b': {"std::basic_string", "std::basic_string", "basic_string"},
+	's': {"std::string", "std::basic_string<char, std::char_traits<char>, std::allocator<char> >", "basic_string"},
+	'i': {"std::istream", "std::basic_istream<char, std::char_traits<char> >", "basic_istream"},
+	'o': {"std::ostream", "std::basic_ostream<char, std::char_traits<char> >", "basic_ostream"},
+	'd': {"std::iostream", "std::basic_iostream<char, std::char_traits<char> >", "basic_iostream"},
+}
+
+// Integer literal suffixes by type code, "" means no suffix
+var literalSuffixes = map[byte]string{
+	'i': "", 'j': "u", 'l': "l", 'm': "ul", 'x': "ll", 'y': "ull",
+}
+
+func (d *demangler) eof() bool {
+	return d.pos >= len(d.s)
+}
+
+func (d *demangler) peek() byte {
+	if d.eof() {
+		return 0
+	}
+	return d.s[d.pos]
+}
+
+func (d *demangler) peekAt(offset int) byte {
+	if d.pos+offset >= len(d.s) {
+		return 0
+	}
+	return d.s[d.pos+offset]
+}
+
+func (d *demangler) consume(prefix string) bool {
+	if strings.HasPrefix(d.s[d.pos:], prefix) {
+		d.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+func (d *demangler) expect(c byte) error {
+	if d.peek() != c {
+		return d.errorf("expected %q", c)
+	}
+	d.pos++
+	return nil
+}
+
+func (d *demangler) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("at offset %d: %s", d.pos, fmt.Sprintf(format, args...))
+}
+
+// parseTopLevel parses <encoding> followed by optional clone suffixes
+func (d *demangler) parseTopLevel() (string, error) {
+	result, err := d.parseEncoding()
+	if err != nil {
+		return "", err
+	}
+
+	for d.peek() == '.' {
+		result += " [clone " + d.parseCloneSuffix() + "]"
+	}
+
+	if !d.eof() {
+		return "", d.errorf("unexpected trailing characters %q", d.s[d.pos:])
+	}
+	return result, nil
+}
+
+// parseCloneSuffix parses a gcc clone suffix such as ".constprop.0" or ".cold"
+func (d *demangler) parseCloneSuffix() string {
+	start := d.pos
+	d.pos++
+	for !d.eof() && (d.peek() == '_' || d.peek() >= 'a' && d.peek() <= 'z') {
+		d.pos++
+	}
+	for d.peek() == '.' && isDigit(d.peekAt(1)) {
+		d.pos++
+		for isDigit(d.peek()) {
+			d.pos++
+		}
+	}
+	if d.pos == start+1 {
+		// Unknown suffix shape, take the rest verbatim
+		d.pos = len(d.s)
+	}
+	return d.s[start:d.pos]
+}
+
+// parseEncoding parses <encoding> ::= <name> <bare-function-type> | <name> | <special-name>
+func (d *demangler) parseEncoding() (string, error) {
+	return d.parseEncodingReturn(true)
+}
+
+// parseEncodingReturn parses <encoding>, optionally leaving out the return
+// type of function templates as c++filt does for the scope of local names
+func (d *demangler) parseEncodingReturn(printReturn bool) (string, error) {
+	if d.peek() == 'T' || d.peek() == 'G' {
+		return d.parseSpecialName()
+	}
+
+	name, err := d.parseName()
+	if err != nil {
+		return "", err
+	}
+
+	if d.eof() || d.peek() == 'E' || d.peek() == '.' {
+		return name.name, nil
+	}
+
+	returnType := ""
+	if name.hasTemplate && !name.isCtorDtor && !name.isConversion {
+		t, err := d.parseType()
+		if err != nil {
+			return "", err
+		}
+		returnType = t.String()
+	}
+
+	params, err := d.parseBareFunctionParams()
+	if err != nil {
+		return "", err
+	}
+
+	result := name.name + "(" + params + ")" + name.qualifiers
+	if returnType != "" && printReturn {
+		result = returnType + " " + result
+	}
+	return result, nil
+}
+
+// parseBareFunctionParams parses parameter types up to the end of the encoding
+func (d *demangler) parseBareFunctionParams() (string, error) {
+	params := make([]string, 0)
+	for !d.eof() && d.peek() != 'E' && d.peek() != '.' {
+		t, err := d.parseType()
+		if err != nil {
+			return "", err
+		}
+		if t.pack != nil && len(t.pack) == 0 {
+			continue // Empty parameter pack
+		}
+		params = append(params, t.String())
+	}
+
+	if len(params) == 1 && params[0] == "void" {
+		return "", nil
+	}
+	return strings.Join(params, ", "), nil
+}
+
+// parseSpecialName parses vtables, typeinfo, thunks and guard variables
+func (d *demangler) parseSpecialName() (string, error) {
+	typePrefixes := map[string]string{
+		"TV": "vtable for ", "TT": "VTT for ", "TI": "typeinfo for ", "TS": "typeinfo name for ",
+	}
+	for code, prefix := range typePrefixes {
+		if d.consume(code) {
+			t, err := d.parseType()
+			if err != nil {
+				return "", err
+			}
+			return prefix + t.String(), nil
+		}
+	}
+
+	switch {
+	case d.consume("Th"):
+		if err := d.skipCallOffset('h'); err != nil {
+			return "", err
+		}
+		return d.prefixedEncoding("non-virtual thunk to ")
+	case d.consume("Tv"):
+		if err := d.skipCallOffset('v'); err != nil {
+			return "", err
+		}
+		return d.prefixedEncoding("virtual thunk to ")
+	case d.consume("Tc"):
+		if err := d.skipCallOffset(d.nextByte()); err != nil {
+			return "", err
+		}
+		if err := d.skipCallOffset(d.nextByte()); err != nil {
+			return "", err
+		}
+		return d.prefixedEncoding("covariant return thunk to ")
+	case d.consume("GV"):
+		name, err := d.parseName()
+		if err != nil {
+			return "", err
+		}
+		return "guard variable for " + name.name, nil
+	case d.consume("GR"):
+		name, err := d.parseName()
+		if err != nil {
+			return "", err
+		}
+		for !d.eof() && d.peek() != '_' {
+			d.pos++
+		}
+		d.consume("_")
+		return "reference temporary for " + name.name, nil
+	case d.consume("GTt"):
+		return d.prefixedEncoding("transaction clone for ")
+	}
+
+	return "", d.errorf("unsupported special name")
+}
+
+func (d *demangler) nextByte() byte {
+	c := d.peek()
+	d.pos++
+	return c
+}
+
+// skipCallOffset skips an h or v call offset
+func (d *demangler) skipCallOffset(kind byte) error {
+	count := 1
+	if kind == 'v' {
+		count = 2
+	} else if kind != 'h' {
+		return d.errorf("invalid call offset")
+	}
+
+	for i := 0; i < count; i++ {
+		d.consume("n")
+		for isDigit(d.peek()) {
+			d.pos++
+		}
+		if err := d.expect('_'); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *demangler) prefixedEncoding(prefix string) (string, error) {
+	enc, err := d.parseEncoding()
+	if err != nil {
+		return "", err
+	}
+	return prefix + enc, nil
+}
+
+// parseName parses <name>
+func (d *demangler) parseName() (demangledName, error) {
+	switch d.peek() {
+	case 'N':
+		return d.parseNestedName()
+	case 'Z':
+		return d.parseLocalName()
+	}
+
+	var result demangledName
+	fromSubstitution := false
+
+	if d.peek() == 'S' && d.peekAt(1) != 't' {
+		t, err := d.parseSubstitution()
+		if err != nil {
+			return result, err
+		}
+		result.name = t.String()
+		fromSubstitution = true
+	} else {
+		prefix := ""
+		if d.consume("St") {
+			prefix = "std::"
+		}
+		unq, err := d.parseUnqualifiedName("")
+		if err != nil {
+			return result, err
+		}
+		result = unq
+		result.name = prefix + unq.name
+	}
+
+	if d.peek() == 'I' {
+		if !fromSubstitution {
+			d.addSub(simpleType(result.name))
+		}
+		args, err := d.parseTemplateArgs()
+		if err != nil {
+			return result, err
+		}
+		result.name = appendTemplateArgs(result.name, args)
+		result.hasTemplate = true
+	}
+
+	return result, nil
+}
+
+// parseNestedName parses <nested-name> ::= N [<CV-qualifiers>] [<ref-qualifier>] <prefix> E
+func (d *demangler) parseNestedName() (demangledName, error) {
+	var result demangledName
+	if err := d.expect('N'); err != nil {
+		return result, err
+	}
+
+	result.qualifiers = d.parseCVQualifiers()
+	if d.consume("R") {
+		result.qualifiers += " &"
+	} else if d.consume("O") {
+		result.qualifiers += " &&"
+	}
+
+	name := ""
+	for {
+		if d.eof() {
+			return result, d.errorf("unterminated nested name")
+		}
+
+		c := d.peek()
+		if c == 'E' {
+			d.pos++
+			break
+		}
+
+		fromSubstitution := false
+		result.hasTemplate = false
+		switch {
+		case c == 'S':
+			if d.consume("St") {
+				name = "std"
+				result.last = "std"
+				continue
+			}
+			t, err := d.parseSubstitution()
+			if err != nil {
+				return result, err
+			}
+			name = t.String()
+			if t.full != "" && (d.peek() == 'C' || d.peek() == 'D') {
+				name = t.full
+			}
+			result.last = t.ctorName()
+			fromSubstitution = true
+		case c == 'I':
+			if name == "" {
+				return result, d.errorf("template arguments without a name")
+			}
+			args, err := d.parseTemplateArgs()
+			if err != nil {
+				return result, err
+			}
+			name = appendTemplateArgs(name, args)
+			result.hasTemplate = true
+		case c == 'T':
+			t, err := d.parseTemplateParam()
+			if err != nil {
+				return result, err
+			}
+			name = t.String()
+			result.last = name
+		case c == 'M':
+			d.pos++
+			continue
+		default:
+			unq, err := d.parseUnqualifiedName(result.last)
+			if err != nil {
+				return result, err
+			}
+			if name != "" {
+				name += "::"
+			}
+			name += unq.name
+			result.last = unq.last
+			result.isCtorDtor = unq.isCtorDtor
+			result.isConversion = unq.isConversion
+		}
+
+		if !fromSubstitution && d.peek() != 'E' {
+			d.addSub(simpleType(name))
+		}
+	}
+
+	result.name = name
+	return result, nil
+}
+
+// parseLocalName parses <local-name> ::= Z <encoding> E <entity name> [<discriminator>]
+func (d *demangler) parseLocalName() (demangledName, error) {
+	var result demangledName
+	if err := d.expect('Z'); err != nil {
+		return result, err
+	}
+
+	// The enclosing function's template arguments must not leak into the entity
+	saved := d.templateArgs
+	enc, err := d.parseEncodingReturn(false)
+	d.templateArgs = saved
+	if err != nil {
+		return result, err
+	}
+	if err := d.expect('E'); err != nil {
+		return result, err
+	}
+
+	if d.consume("s") {
+		d.parseDiscriminator()
+		result.name = enc + "::string literal"
+		return result, nil
+	}
+
+	entity, err := d.parseName()
+	if err != nil {
+		return result, err
+	}
+	d.parseDiscriminator()
+
+	entity.name = enc + "::" + entity.name
+	return entity, nil
+}
+
+// parseDiscriminator skips an optional <discriminator>
+func (d *demangler) parseDiscriminator() {
+	if d.peek() != '_' {
+		return
+	}
+	if d.peekAt(1) == '_' {
+		d.pos += 2
+		for isDigit(d.peek()) {
+			d.pos++
+		}
+		d.consume("_")
+		return
+	}
+	if isDigit(d.peekAt(1)) {
+		d.pos += 2
+	}
+}
+
+// parseCVQualifiers parses [r] [V] [K] and returns them in c++filt order,
+// which is the reverse of the mangled order
+func (d *demangler) parseCVQualifiers() string {
+	quals := ""
+	if d.consume("r") {
+		quals = " restrict" + quals
+	}
+	if d.consume("V") {
+		quals = " volatile" + quals
+	}
+	if d.consume("K") {
+		quals = " const" + quals
+	}
+	return quals
+}
+
+// parseUnqualifiedName parses <unqualified-name>. enclosing is the last
+// component of the enclosing scope, used to name constructors and destructors.
+func (d *demangler) parseUnqualifiedName(enclosing string) (demangledName, error) {
+	var result demangledName
+	c := d.peek()
+
+	switch {
+	case isDigit(c):
+		name, err := d.parseSourceName()
+		if err != nil {
+			return result, err
+		}
+		result.name = name
+	case c == 'L':
+		d.pos++
+		name, err := d.parseSourceName()
+		if err != nil {
+			return result, err
+		}
+		result.name = name
+		d.parseDiscriminator()
+	case (c == 'C' || c == 'D') && enclosing == "":
+		return result, d.errorf("constructor or destructor outside of a class")
+	case c == 'C' && (isDigit(d.peekAt(1)) || d.peekAt(1) == 'I'):
+		d.pos++
+		if d.consume("I") {
+			// Inheriting constructor: the base class type follows
+			d.pos++
+			if _, err := d.parseType(); err != nil {
+				return result, err
+			}
+		} else {
+			d.pos++
+		}
+		result.name = enclosing
+		result.isCtorDtor = true
+	case c == 'D' && isDigit(d.peekAt(1)):
+		d.pos += 2
+		result.name = "~" + enclosing
+		result.isCtorDtor = true
+	case c == 'U':
+		name, err := d.parseUnnamedTypeName()
+		if err != nil {
+			return result, err
+		}
+		result.name = name
+	case c >= 'a' && c <= 'z':
+		name, isConversion, err := d.parseOperatorName()
+		if err != nil {
+			return result, err
+		}
+		result.name = name
+		result.isConversion = isConversion
+	default:
+		return result, d.errorf("unexpected character %q in name", c)
+	}
+
+	if result.last == "" {
+		result.last = result.name
+	}
+
+	// ABI tags, e.g. B5cxx11
+	for d.peek() == 'B' {
+		d.pos++
+		tag, err := d.parseSourceName()
+		if err != nil {
+			return result, err
+		}
+		result.name += "[abi:" + tag + "]"
+	}
+
+	return result, nil
+}
+
+// parseSourceName parses <source-name> ::= <positive length number> <identifier>
+func (d *demangler) parseSourceName() (string, error) {
+	n, err := d.parseNumber()
+	if err != nil {
+		return "", err
+	}
+	if n <= 0 || d.pos+n > len(d.s) {
+		return "", d.errorf("invalid source name length %d", n)
+	}
+
+	name := d.s[d.pos : d.pos+n]
+	d.pos += n
+
+	if strings.HasPrefix(name, "_GLOBAL_") && len(name) > 9 && strings.Contains("._$", name[8:9]) && name[9] == 'N' {
+		return "(anonymous namespace)", nil
+	}
+	return name, nil
+}
+
+// parseNumber parses a non-negative decimal number
+func (d *demangler) parseNumber() (int, error) {
+	start := d.pos
+	for isDigit(d.peek()) {
+		d.pos++
+	}
+	if start == d.pos {
+		return 0, d.errorf("expected number")
+	}
+	return strconv.Atoi(d.s[start:d.pos])
+}
+
+// parseUnnamedTypeName parses closure types (Ul) and unnamed types (Ut)
+func (d *demangler) parseUnnamedTypeName() (string, error) {
+	switch {
+	case d.consume("Ut"):
+		n := d.parseSeqNumber()
+		return fmt.Sprintf("{unnamed type#%d}", n+1), nil
+	case d.consume("Ul"):
+		params, err := d.parseBareFunctionParams()
+		if err != nil {
+			return "", err
+		}
+		if err := d.expect('E'); err != nil {
+			return "", err
+		}
+		n := d.parseSeqNumber()
+		return fmt.Sprintf("{lambda(%s)#%d}", params, n+1), nil
+	}
+	return "", d.errorf("unsupported unnamed type")
+}
+
+// parseSeqNumber parses "[<number>] _" where "_" means 0 and "<n>_" means n+1
+func (d *demangler) parseSeqNumber() int {
+	n := 0
+	if isDigit(d.peek()) {
+		n, _ = d.parseNumber()
+		n++
+	}
+	d.consume("_")
+	return n
+}
+
+// parseOperatorName parses <operator-name>
+func (d *demangler) parseOperatorName() (string, bool, error) {
+	switch {
+	case d.consume("cv"):
+		t, err := d.parseType()
+		if err != nil {
+			return "", false, err
+		}
+		return "operator " + t.String(), true, nil
+	case d.consume("li"):
+		name, err := d.parseSourceName()
+		if err != nil {
+			return "", false, err
+		}
+		return "operator\"\" " + name, false, nil
+	case d.peek() == 'v' && isDigit(d.peekAt(1)):
+		d.pos += 2
+		name, err := d.parseSourceName()
+		if err != nil {
+			return "", false, err
+		}
+		return "operator " + name, false, nil
+	}
+
+	if d.pos+2 > len(d.s) {
+		return "", false, d.errorf("truncated operator name")
+	}
+	op, ok := operatorNames[d.s[d.pos:d.pos+2]]
+	if !ok {
+		return "", false, d.errorf("unknown operator %q", d.s[d.pos:d.pos+2])
+	}
+	d.pos += 2
+
+	if op[0] >= 'a' && op[0] <= 'z' {
+		return "operator " + op, false, nil
+	}
+	return "operator" + op, false, nil
+}
+
+// parseTemplateArgs parses <template-args> ::= I <template-arg>+ E
+func (d *demangler) parseTemplateArgs() (string, error) {
+	if err := d.expect('I'); err != nil {
+		return "", err
+	}
+
+	d.templDepth++
+	args := make([]demangledType, 0)
+	for d.peek() != 'E' {
+		if d.eof() {
+			d.templDepth--
+			return "", d.errorf("unterminated template arguments")
+		}
+		arg, err := d.parseTemplateArg()
+		if err != nil {
+			d.templDepth--
+			return "", err
+		}
+		args = append(args, arg)
+	}
+	d.pos++
+	d.templDepth--
+
+	if d.templDepth == 0 && d.typeDepth == 0 {
+		d.templateArgs = args
+	}
+
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		if s := a.String(); s != "" || a.pack == nil {
+			parts = append(parts, s)
+		}
+	}
+	result := "<" + strings.Join(parts, ", ")
+	if strings.HasSuffix(result, ">") {
+		result += " "
+	}
+	return result + ">", nil
+}
+
+// parseTemplateArg parses a single <template-arg>
+func (d *demangler) parseTemplateArg() (demangledType, error) {
+	switch d.peek() {
+	case 'L':
+		s, err := d.parseExprPrimary()
+		return simpleType(s), err
+	case 'X':
+		d.pos++
+		s, err := d.parseExpression()
+		if err != nil {
+			return demangledType{}, err
+		}
+		return simpleType(s), d.expect('E')
+	case 'J':
+		d.pos++
+		pack := make([]demangledType, 0)
+		for d.peek() != 'E' {
+			if d.eof() {
+				return demangledType{}, d.errorf("unterminated argument pack")
+			}
+			arg, err := d.parseTemplateArg()
+			if err != nil {
+				return demangledType{}, err
+			}
+			pack = append(pack, arg)
+		}
+		d.pos++
+		return demangledType{pack: pack}, nil
+	}
+	return d.parseType()
+}
+
+// parseExpression parses the small subset of <expression> that appears in
+// ordinary template arguments: template parameters and literals
+func (d *demangler) parseExpression() (string, error) {
+	switch d.peek() {
+	case 'T':
+		t, err := d.parseTemplateParam()
+		return t.String(), err
+	case 'L':
+		return d.parseExprPrimary()
+	}
+	return "", d.errorf("unsupported expression")
+}
+
+// parseExprPrimary parses <expr-primary> ::= L <type> <value> E | L _Z <encoding> E
+func (d *demangler) parseExprPrimary() (string, error) {
+	if err := d.expect('L'); err != nil {
+		return "", err
+	}
+
+	if d.consume("_Z") {
+		enc, err := d.parseEncoding()
+		if err != nil {
+			return "", err
+		}
+		return enc, d.expect('E')
+	}
+
+	typeCode := d.peek()
+	t, err := d.parseType()
+	if err != nil {
+		return "", err
+	}
+
+	start := d.pos
+	for !d.eof() && d.peek() != 'E' {
+		d.pos++
+	}
+	value := d.s[start:d.pos]
+	if err := d.expect('E'); err != nil {
+		return "", err
+	}
+
+	negative := strings.HasPrefix(value, "n")
+	if negative {
+		value = "-" + value[1:]
+	}
+
+	if typeCode == 'b' && (value == "0" || value == "1") {
+		if value == "1" {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	if suffix, ok := literalSuffixes[typeCode]; ok {
+		return value + suffix, nil
+	}
+	if typeCode == 'D' && value == "" {
+		return "nullptr", nil
+	}
+	return "(" + t.String() + ")" + value, nil
+}
+
+// parseTemplateParam parses <template-param> ::= T_ | T <number> _
+func (d *demangler) parseTemplateParam() (demangledType, error) {
+	if err := d.expect('T'); err != nil {
+		return demangledType{}, err
+	}
+
+	idx := 0
+	if d.peek() != '_' {
+		n, err := d.parseNumber()
+		if err != nil {
+			return demangledType{}, err
+		}
+		idx = n + 1
+	}
+	if err := d.expect('_'); err != nil {
+		return demangledType{}, err
+	}
+
+	if idx < 0 || idx >= len(d.templateArgs) {
+		return demangledType{}, d.errorf("template parameter %d out of range", idx)
+	}
+	return d.templateArgs[idx], nil
+}
+
+// parseSubstitution parses <substitution>
+func (d *demangler) parseSubstitution() (demangledType, error) {
+	if err := d.expect('S'); err != nil {
+		return demangledType{}, err
+	}
+
+	c := d.peek()
+	if std, ok := stdSubstitutions[c]; ok {
+		d.pos++
+		return demangledType{base: std.name, full: std.full, ctor: std.ctor}, nil
+	}
+
+	idx := 0
+	if c != '_' {
+		n := 0
+		for {
+			c = d.peek()
+			switch {
+			case isDigit(c):
+				n = n*36 + int(c-'0')
+			case c >= 'A' && c <= 'Z':
+				n = n*36 + int(c-'A') + 10
+			default:
+				return demangledType{}, d.errorf("invalid substitution")
+			}
+			d.pos++
+			if d.peek() == '_' {
+				break
+			}
+		}
+		idx = n + 1
+	}
+	if err := d.expect('_'); err != nil {
+		return demangledType{}, err
+	}
+
+	if idx < 0 || idx >= len(d.subs) {
+		return demangledType{}, d.errorf("substitution %d out of range", idx)
+	}
+	return d.subs[idx], nil
+}
+
+// ctorName returns the name a constructor of this type is printed with
+func (t demangledType) ctorName() string {
+	if t.ctor != "" {
+		return t.ctor
+	}
+	name := t.String()
+	if idx := strings.LastIndex(name, "::"); idx != -1 {
+		name = name[idx+2:]
+	}
+	return stripTemplateArgs(name)
+}
+
+func (d *demangler) addSub(t demangledType) {
+	d.subs = append(d.subs, t)
+}
+
+// parseType parses <type>
+func (d *demangler) parseType() (demangledType, error) {
+	d.typeDepth++
+	defer func() { d.typeDepth-- }()
+
+	c := d.peek()
+
+	if name, ok := builtinTypes[c]; ok {
+		d.pos++
+		return simpleType(name), nil
+	}
+
+	if c == 'D' {
+		if name, ok := builtinDTypes[d.peekAt(1)]; ok {
+			d.pos += 2
+			return simpleType(name), nil
+		}
+	}
+
+	var result demangledType
+	switch c {
+	case 'r', 'V', 'K':
+		quals := d.parseCVQualifiers()
+		inner, err := d.parseType()
+		if err != nil {
+			return result, err
+		}
+		if inner.isFunc {
+			// cv-qualified function types only occur for member functions
+			inner.suffix += quals
+			result = inner
+		} else {
+			result = inner.withDeclarator(strings.TrimSpace(quals))
+		}
+	case 'P', 'R', 'O':
+		d.pos++
+		inner, err := d.parseType()
+		if err != nil {
+			return result, err
+		}
+		decl := map[byte]string{'P': "*", 'R': "&", 'O': "&&"}[c]
+		result = inner.withDeclarator(decl)
+	case 'C', 'G':
+		d.pos++
+		inner, err := d.parseType()
+		if err != nil {
+			return result, err
+		}
+		suffix := map[byte]string{'C': " _Complex", 'G': " _Imaginary"}[c]
+		result = simpleType(inner.String() + suffix)
+	case 'F':
+		t, err := d.parseFunctionType()
+		if err != nil {
+			return result, err
+		}
+		result = t
+	case 'A':
+		t, err := d.parseArrayType()
+		if err != nil {
+			return result, err
+		}
+		result = t
+	case 'M':
+		t, err := d.parsePointerToMemberType()
+		if err != nil {
+			return result, err
+		}
+		result = t
+	case 'T':
+		t, err := d.parseTemplateParam()
+		if err != nil {
+			return result, err
+		}
+		result = t
+		if d.peek() == 'I' {
+			d.addSub(result)
+			args, err := d.parseTemplateArgs()
+			if err != nil {
+				return result, err
+			}
+			result = simpleType(appendTemplateArgs(result.String(), args))
+		}
+	case 'S':
+		if d.peekAt(1) != 't' {
+			t, err := d.parseSubstitution()
+			if err != nil {
+				return result, err
+			}
+			if d.peek() != 'I' {
+				// Substitutions are not new candidates
+				return t, nil
+			}
+			args, err := d.parseTemplateArgs()
+			if err != nil {
+				return result, err
+			}
+			result = simpleType(appendTemplateArgs(t.String(), args))
+			break
+		}
+		name, err := d.parseName()
+		if err != nil {
+			return result, err
+		}
+		result = simpleType(name.name)
+	case 'D':
+		switch d.peekAt(1) {
+		case 'p':
+			d.pos += 2
+			inner, err := d.parseType()
+			if err != nil {
+				return result, err
+			}
+			if inner.pack != nil {
+				result = inner
+			} else {
+				result = simpleType(inner.String() + "...")
+			}
+		default:
+			return result, d.errorf("unsupported type %q", d.s[d.pos:min(d.pos+2, len(d.s))])
+		}
+	case 'u':
+		d.pos++
+		name, err := d.parseSourceName()
+		if err != nil {
+			return result, err
+		}
+		result = simpleType(name)
+	default:
+		if isDigit(c) || c == 'N' || c == 'Z' {
+			name, err := d.parseName()
+			if err != nil {
+				return result, err
+			}
+			result = simpleType(name.name)
+		} else {
+			return result, d.errorf("unsupported type %q", c)
+		}
+	}
+
+	d.addSub(result)
+	return result, nil
+}
+
+// parseFunctionType parses <function-type> ::= F [Y] <bare-function-type> [<ref-qualifier>] E
+func (d *demangler) parseFunctionType() (demangledType, error) {
+	if err := d.expect('F'); err != nil {
+		return demangledType{}, err
+	}
+	d.consume("Y")
+
+	ret, err := d.parseType()
+	if err != nil {
+		return demangledType{}, err
+	}
+
+	params := make([]string, 0)
+	refQual := ""
+	for d.peek() != 'E' {
+		if d.eof() {
+			return demangledType{}, d.errorf("unterminated function type")
+		}
+		if (d.peek() == 'R' || d.peek() == 'O') && d.peekAt(1) == 'E' {
+			refQual = map[byte]string{'R': " &", 'O': " &&"}[d.peek()]
+			d.pos++
+			continue
+		}
+		t, err := d.parseType()
+		if err != nil {
+			return demangledType{}, err
+		}
+		if t.pack != nil && len(t.pack) == 0 {
+			continue // Empty parameter pack
+		}
+		params = append(params, t.String())
+	}
+	d.pos++
+
+	if len(params) == 1 && params[0] == "void" {
+		params = params[:0]
+	}
+
+	if ret.compound && ret.inner != "" {
+		// Returning a pointer to a function or array nests the declarators
+		return demangledType{
+			base:     ret.base,
+			suffix:   ret.suffix,
+			pre:      ret.inner,
+			post:     "(" + strings.Join(params, ", ") + ")" + refQual,
+			compound: true,
+			isFunc:   true,
+		}, nil
+	}
+
+	return demangledType{
+		base:     ret.String(),
+		suffix:   "(" + strings.Join(params, ", ") + ")" + refQual,
+		compound: true,
+		isFunc:   true,
+	}, nil
+}
+
+// parseArrayType parses <array-type> ::= A [<dimension>] _ <element type>
+func (d *demangler) parseArrayType() (demangledType, error) {
+	if err := d.expect('A'); err != nil {
+		return demangledType{}, err
+	}
+
+	dim := ""
+	if isDigit(d.peek()) {
+		n, err := d.parseNumber()
+		if err != nil {
+			return demangledType{}, err
+		}
+		dim = strconv.Itoa(n)
+	} else if d.peek() == 'T' {
+		t, err := d.parseTemplateParam()
+		if err != nil {
+			return demangledType{}, err
+		}
+		dim = t.String()
+	}
+	if err := d.expect('_'); err != nil {
+		return demangledType{}, err
+	}
+
+	elem, err := d.parseType()
+	if err != nil {
+		return demangledType{}, err
+	}
+
+	if elem.compound && !elem.isFunc && elem.inner == "" {
+		// Multi-dimensional array: int [2][3]
+		elem.suffix = " [" + dim + "]" + strings.TrimPrefix(elem.suffix, " ")
+		return elem, nil
+	}
+
+	return demangledType{
+		base:     elem.String(),
+		suffix:   " [" + dim + "]",
+		compound: true,
+	}, nil
+}
+
+// parsePointerToMemberType parses <pointer-to-member-type> ::= M <class type> <member type>
+func (d *demangler) parsePointerToMemberType() (demangledType, error) {
+	if err := d.expect('M'); err != nil {
+		return demangledType{}, err
+	}
+
+	class, err := d.parseType()
+	if err != nil {
+		return demangledType{}, err
+	}
+	member, err := d.parseType()
+	if err != nil {
+		return demangledType{}, err
+	}
+
+	if member.isFunc {
+		member.inner = class.String() + "::*" + member.inner
+		return member, nil
+	}
+	return simpleType(member.String() + " " + class.String() + "::*"), nil
+}
+
+// appendTemplateArgs appends a template argument list to a name, keeping
+// operator names such as "operator<<" apart from the opening bracket
+func appendTemplateArgs(name, args string) string {
+	if strings.HasSuffix(name, "<") {
+		return name + " " + args
+	}
+	return name + args
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,104 @@
+package gcovr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDemangle(t *testing.T) {
+	tests := []struct {
+		name     string
+		mangled  string
+		expected string
+	}{
+		{"Simple function", "_Z1fv", "f()"},
+		{"Nested name", "_ZN2ns3Foo3barEi", "ns::Foo::bar(int)"},
+		{"Const method", "_ZNK3Foo3barEv", "Foo::bar() const"},
+		{"Pointer to const char", "_Z1fPKc", "f(char const*)"},
+		{"Function template", "_Z3maxIiET_S0_S0_", "int max<int>(int, int)"},
+		{"Std template with substitution", "_ZNSt6vectorIiSaIiEE9push_backERKi", "std::vector<int, std::allocator<int> >::push_back(int const&)"},
+		{"Constructor", "_ZN3FooC2Ev", "Foo::Foo()"},
+		{"Destructor", "_ZN3FooD0Ev", "Foo::~Foo()"},
+		{"Copy assignment", "_ZN3FooaSERKS_", "Foo::operator=(Foo const&)"},
+		{"Stream operator", "_ZlsRSoRK3Foo", "operator<<(std::ostream&, Foo const&)"},
+		{"Conversion operator", "_ZN3FoocviEv", "Foo::operator int()"},
+		{"Operator new", "_Znwm", "operator new(unsigned long)"},
+		{"Function pointer", "_Z3barPFviE", "bar(void (*)(int))"},
+		{"Reference to array", "_Z1fRA10_i", "f(int (&) [10])"},
+		{"Pointer to member function", "_Z1fM3FooFvvE", "f(void (Foo::*)())"},
+		{"Lambda", "_ZZ4mainENKUlvE_clEv", "main::{lambda()#1}::operator()() const"},
+		{"Anonymous namespace", "_ZN12_GLOBAL__N_13fooEv", "(anonymous namespace)::foo()"},
+		{"Internal linkage", "_ZL10local_funcv", "local_func()"},
+		{"Parameter pack", "_Z1fIJidEEvDpT_", "void f<int, double>(int, double)"},
+		{"Reference collapsing", "_ZSt4moveIRiEONSt16remove_referenceIT_E4typeEOS2_", "std::remove_reference<int&>::type&& std::move<int&>(int&)"},
+		{"Non-type template argument", "_Z5firstILi3EEvv", "void first<3>()"},
+		{"Bool template argument", "_Z1fILb1EEvv", "void f<true>()"},
+		{"ABI tag", "_ZN1A1fB5cxx11Ev", "A::f[abi:cxx11]()"},
+		{"Vtable", "_ZTV3Foo", "vtable for Foo"},
+		{"Thunk", "_ZThn8_N3Foo3barEv", "non-virtual thunk to Foo::bar()"},
+		{"Guard variable", "_ZGVZ4mainE1x", "guard variable for main::x"},
+		{"Clone suffix", "_Z1fv.constprop.0", "f() [clone .constprop.0]"},
+		{"GCC builtin expander", "_Z19ix86_expand_builtinP9tree_nodeP7rtx_defS2_12machine_modei",
+			"ix86_expand_builtin(tree_node*, rtx_def*, rtx_def*, machine_mode, int)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Demangle(tt.mangled)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Demangle(%q) = %q, expected %q", tt.mangled, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDemangle_Errors(t *testing.T) {
+	if _, err := Demangle("main"); !errors.Is(err, ErrNotMangled) {
+		t.Errorf("Expected ErrNotMangled for plain name, got %v", err)
+	}
+
+	invalid := []string{"_Z", "_ZN3Foo", "_Z3fooS5_", "_Z1fT_", "_Z99f", "_Z1AD", "_ZS2000000000000_"}
+	for _, name := range invalid {
+		if result, err := Demangle(name); err == nil {
+			t.Errorf("Expected error for %q, got %q", name, result)
+		}
+	}
+}
+
+func TestDemangleName(t *testing.T) {
+	if got := DemangleName("_Z1gv"); got != "g()" {
+		t.Errorf("Expected 'g()', got %q", got)
+	}
+	if got := DemangleName("main"); got != "main" {
+		t.Errorf("Expected unchanged 'main', got %q", got)
+	}
+	if got := DemangleName("_Zbogus"); got != "_Zbogus" {
+		t.Errorf("Expected unchanged '_Zbogus', got %q", got)
+	}
+}
+
+// FuzzDemangle runs the parser on arbitrary symbols. It calls the parser
+// directly rather than Demangle so that index errors surface as panics
+// instead of being turned into errors by Demangle's recover.
+func FuzzDemangle(f *testing.F) {
+	for _, seed := range []string{
+		"_Z1fv", "_ZN2ns3Foo3barEi", "_Z3maxIiET_S0_S0_", "_ZNSt6vectorIiSaIiEE9push_backERKi",
+		"_ZZ4mainENKUlvE_clEv", "_Z1fIJidEEvDpT_", "_ZThn8_N3Foo3barEv", "_Z1fv.constprop.0", "_Z1AD",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, name string) {
+		if strings.HasPrefix(name, "_Z") {
+			d := &demangler{s: name, pos: 2}
+			d.parseTopLevel()
+		}
+		if result := DemangleName(name); result == "" && name != "" {
+			t.Errorf("DemangleName(%q) returned an empty name", name)
+		}
+	})
+}
@@ -2,13 +2,28 @@ package gcovr
 
 import (
 	"fmt"
+	"sort"
 )
 
 // ComputeCoverageIncrease calculates coverage increases from base to new report
 // It returns a report containing functions with increased line coverage
 func ComputeCoverageIncrease(baseReport, newReport *GcovrReport) (*CoverageIncreaseReport, error) {
+	return ComputeCoverageIncreaseWithOptions(baseReport, newReport, DiffOptions{})
+}
+
+// ComputeCoverageIncreaseWithOptions calculates coverage increases like
+// ComputeCoverageIncrease. Functions whose mangled name changed between the
+// reports, e.g. after a signature change, are paired by the strategies in
+// opts.FunctionMatch and compared against their base coverage; the pairs are
+// listed in the report's Renames.
+func ComputeCoverageIncreaseWithOptions(baseReport, newReport *GcovrReport, opts DiffOptions) (*CoverageIncreaseReport, error) {
+	if err := validateFunctionMatch(opts.FunctionMatch); err != nil {
+		return nil, err
+	}
+
 	result := &CoverageIncreaseReport{
 		Increases: make([]FunctionCoverageIncrease, 0),
+		Renames:   make([]FunctionRename, 0),
 	}
 
 	// Create maps for quick lookup
@@ -27,8 +42,10 @@ func ComputeCoverageIncrease(baseReport, newReport *GcovrReport) (*CoverageIncre
 		}
 
 		// Compare functions in the same file
-		increases := compareFunctions(baseFile, &newFile)
+		matches, renames := matchFunctions(baseFile, &newFile, opts.FunctionMatch)
+		increases := compareFunctions(baseFile, &newFile, matches)
 		result.Increases = append(result.Increases, increases...)
+		result.Renames = append(result.Renames, renames...)
 	}
 
 	return result, nil
@@ -55,10 +72,8 @@ func processNewFile(file *File) []FunctionCoverageIncrease {
 
 	// Create increase records
 	for funcName, lineNumbers := range funcLines {
-		demangledName := funcDemangledName[funcName]
-		if demangledName == "" {
-			demangledName = funcName
-		}
+		sort.Ints(lineNumbers)
+		demangledName := functionDisplayName(funcName, funcDemangledName[funcName])
 
 		totalLines := getTotalFunctionLines(file, funcName)
 
@@ -69,16 +84,31 @@ func processNewFile(file *File) []FunctionCoverageIncrease {
 			LinesIncreased:       len(lineNumbers),
 			TotalLines:           totalLines,
 			IncreasedLineNumbers: lineNumbers,
+			IncreasedRanges:      CompactLineRanges(lineNumbers),
 			OldCoveredLines:      0, // No old coverage for new file
 			NewCoveredLines:      len(lineNumbers),
 		})
 	}
 
+	sortIncreasesByLine(increases)
 	return increases
 }
 
-// compareFunctions compares functions between base and new file
-func compareFunctions(baseFile, newFile *File) []FunctionCoverageIncrease {
+// sortIncreasesByLine orders the increases of one file by their first newly
+// covered line, so the output does not depend on map iteration order
+func sortIncreasesByLine(increases []FunctionCoverageIncrease) {
+	sort.Slice(increases, func(i, j int) bool {
+		a, b := &increases[i], &increases[j]
+		if la, lb := increaseFirstLine(a), increaseFirstLine(b); la != lb {
+			return la < lb
+		}
+		return a.FunctionName < b.FunctionName
+	})
+}
+
+// compareFunctions compares functions between base and new file. matches maps
+// new mangled names to the base function they were paired with, if renamed.
+func compareFunctions(baseFile, newFile *File, matches map[string]string) []FunctionCoverageIncrease {
 	increases := make([]FunctionCoverageIncrease, 0)
 
 	// Create line coverage maps: function -> line_number -> count
@@ -87,10 +117,16 @@ func compareFunctions(baseFile, newFile *File) []FunctionCoverageIncrease {
 
 	// Get function demangled names
 	funcNames := buildFunctionNameMap(newFile)
+	baseFuncNames := buildFunctionNameMap(baseFile)
 
 	// Find increased coverage
 	for funcName, newLines := range newCoverage {
 		baseLines, exists := baseCoverage[funcName]
+		previousName := ""
+		if baseName, renamed := matches[funcName]; renamed && !exists {
+			baseLines, exists = baseCoverage[baseName]
+			previousName = functionDisplayName(baseName, baseFuncNames[baseName])
+		}
 
 		increasedLines := make([]int, 0)
 		oldCoveredCount := 0
@@ -119,10 +155,8 @@ func compareFunctions(baseFile, newFile *File) []FunctionCoverageIncrease {
 		}
 
 		if len(increasedLines) > 0 {
-			demangledName := funcNames[funcName]
-			if demangledName == "" {
-				demangledName = funcName
-			}
+			sort.Ints(increasedLines)
+			demangledName := functionDisplayName(funcName, funcNames[funcName])
 
 			totalLines := getTotalFunctionLines(newFile, funcName)
 
@@ -133,12 +167,15 @@ func compareFunctions(baseFile, newFile *File) []FunctionCoverageIncrease {
 				LinesIncreased:       len(increasedLines),
 				TotalLines:           totalLines,
 				IncreasedLineNumbers: increasedLines,
+				IncreasedRanges:      CompactLineRanges(increasedLines),
 				OldCoveredLines:      oldCoveredCount,
 				NewCoveredLines:      newCoveredCount,
+				PreviousName:         previousName,
 			})
 		}
 	}
 
+	sortIncreasesByLine(increases)
 	return increases
 }
 
@@ -181,7 +218,7 @@ func getTotalFunctionLines(file *File, funcName string) int {
 // FormatReport formats the coverage increase report as a human-readable string
 func FormatReport(report *CoverageIncreaseReport) string {
 	if len(report.Increases) == 0 {
-		return "No coverage increases found.\n"
+		return "No coverage increases found.\n" + formatRenames(report.Renames)
 	}
 
 	result := fmt.Sprintf("Coverage Increase Report\n")
@@ -198,11 +235,36 @@ func FormatReport(report *CoverageIncreaseReport) string {
 
 		result += fmt.Sprintf("%d. File: %s\n", i+1, inc.File)
 		result += fmt.Sprintf("   Function: %s\n", inc.DemangledName)
+		if inc.PreviousName != "" {
+			result += fmt.Sprintf("   Previously: %s\n", inc.PreviousName)
+		}
 		result += fmt.Sprintf("   Old Coverage: %d/%d lines (%.1f%%)\n", inc.OldCoveredLines, inc.TotalLines, oldCoveragePercent)
 		result += fmt.Sprintf("   New Coverage: %d/%d lines (%.1f%%)\n", inc.NewCoveredLines, inc.TotalLines, newCoveragePercent)
 		result += fmt.Sprintf("   Lines Increased: %d\n", inc.LinesIncreased)
-		result += fmt.Sprintf("   Newly Covered Line Numbers: %v\n\n", inc.IncreasedLineNumbers)
+		ranges := inc.IncreasedRanges
+		if ranges == nil {
+			ranges = CompactLineRanges(inc.IncreasedLineNumbers)
+		}
+		result += fmt.Sprintf("   Newly Covered Line Numbers: %s\n", FormatLineRanges(ranges))
+		result += formatSnippets(inc.Snippets, "      ")
+		result += "\n"
 	}
 
+	result += formatRenames(report.Renames)
+
+	return result
+}
+
+// formatRenames lists functions matched across reports under a new name
+func formatRenames(renames []FunctionRename) string {
+	if len(renames) == 0 {
+		return ""
+	}
+
+	result := fmt.Sprintf("Matched %d renamed or re-signatured function(s):\n", len(renames))
+	for _, rename := range renames {
+		result += fmt.Sprintf("   %s: %s -> %s [%s]\n",
+			rename.File, rename.OldDemangledName, rename.NewDemangledName, rename.Strategy)
+	}
 	return result
 }
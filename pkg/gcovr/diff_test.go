@@ -328,7 +328,7 @@ func TestFormatReport(t *testing.T) {
 				"Old Coverage: 3/5 lines (60.0%)",
 				"New Coverage: 5/5 lines (100.0%)",
 				"Lines Increased: 2",
-				"Newly Covered Line Numbers: [2 3]",
+				"Newly Covered Line Numbers: 2-3",
 			},
 		},
 		{
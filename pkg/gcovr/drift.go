@@ -0,0 +1,247 @@
+package gcovr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FileDrift records the lines whose source text differs between two reports
+type FileDrift struct {
+	FilePath      string
+	ChangedLines  []int // Line numbers whose gcovr/md5 differs
+	ComparedLines int   // Line numbers present with a hash in both reports
+}
+
+// SourceDriftReport describes files whose sources differ between two reports
+type SourceDriftReport struct {
+	Files []FileDrift
+}
+
+// HasDrift reports whether any file was built from different sources
+func (r *SourceDriftReport) HasDrift() bool {
+	return len(r.Files) > 0
+}
+
+// Warnings returns one human-readable message per drifted file
+func (r *SourceDriftReport) Warnings() []string {
+	warnings := make([]string, 0, len(r.Files))
+	for _, file := range r.Files {
+		warnings = append(warnings, fmt.Sprintf(
+			"source of %s differs between the reports: %d of %d compared lines changed",
+			file.FilePath, len(file.ChangedLines), file.ComparedLines))
+	}
+	return warnings
+}
+
+// DetectSourceDrift compares the gcovr/md5 line hashes of files present in
+// both reports. Lines without a hash in either report are not compared, so
+// reports from tools that do not write hashes never show drift.
+func DetectSourceDrift(baseReport, newReport *GcovrReport) *SourceDriftReport {
+	result := &SourceDriftReport{Files: make([]FileDrift, 0)}
+
+	baseFileMap := make(map[string]*File)
+	for i := range baseReport.Files {
+		baseFileMap[baseReport.Files[i].FilePath] = &baseReport.Files[i]
+	}
+
+	for _, newFile := range newReport.Files {
+		baseFile, exists := baseFileMap[newFile.FilePath]
+		if !exists {
+			continue
+		}
+
+		baseHashes := lineHashes(baseFile)
+		drift := FileDrift{FilePath: newFile.FilePath, ChangedLines: make([]int, 0)}
+		for lineNum, hash := range lineHashes(&newFile) {
+			baseHash, ok := baseHashes[lineNum]
+			if !ok {
+				continue
+			}
+			drift.ComparedLines++
+			if baseHash != hash {
+				drift.ChangedLines = append(drift.ChangedLines, lineNum)
+			}
+		}
+
+		if len(drift.ChangedLines) > 0 {
+			sort.Ints(drift.ChangedLines)
+			result.Files = append(result.Files, drift)
+		}
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].FilePath < result.Files[j].FilePath
+	})
+
+	return result
+}
+
+// lineHashes returns line number -> gcovr/md5 for the lines of a file that have one
+func lineHashes(file *File) map[int]string {
+	hashes := make(map[int]string)
+	for _, line := range file.Lines {
+		if line.MD5 != "" {
+			hashes[line.LineNumber] = line.MD5
+		}
+	}
+	return hashes
+}
+
+// RemapReportByHash moves the line numbers of the base report onto the new
+// report by matching lines with equal gcovr/md5 hashes, so coverage
+// comparisons survive shifted code without a diff. Lines are aligned in
+// order: hashes unique to one line in both files anchor the alignment, and
+// the remaining lines between anchors are paired in sequence. Base lines that
+// find no partner are dropped. Files without hashes are copied unchanged.
+func RemapReportByHash(baseReport, newReport *GcovrReport) *GcovrReport {
+	result := &GcovrReport{
+		FormatVersion: baseReport.FormatVersion,
+		Files:         make([]File, 0, len(baseReport.Files)),
+	}
+
+	newFileMap := make(map[string]*File)
+	for i := range newReport.Files {
+		newFileMap[newReport.Files[i].FilePath] = &newReport.Files[i]
+	}
+
+	for _, baseFile := range baseReport.Files {
+		newFile, exists := newFileMap[baseFile.FilePath]
+		if !exists || len(lineHashes(&baseFile)) == 0 || len(lineHashes(newFile)) == 0 {
+			result.Files = append(result.Files, baseFile)
+			continue
+		}
+
+		mapping := alignLineHashes(hashedLines(&baseFile), hashedLines(newFile))
+
+		remapped := File{
+			FilePath:  baseFile.FilePath,
+			Lines:     make([]Line, 0, len(baseFile.Lines)),
+			Functions: make([]Function, 0, len(baseFile.Functions)),
+		}
+		for _, line := range baseFile.Lines {
+			newLine, ok := mapping[line.LineNumber]
+			if !ok {
+				continue
+			}
+			line.LineNumber = newLine
+			remapped.Lines = append(remapped.Lines, line)
+		}
+		for _, fn := range baseFile.Functions {
+			if newLine, ok := mapping[fn.LineNo]; ok {
+				fn.LineNo = newLine
+			}
+			remapped.Functions = append(remapped.Functions, fn)
+		}
+
+		result.Files = append(result.Files, remapped)
+	}
+
+	return result
+}
+
+// hashedLine is a line number with its source hash
+type hashedLine struct {
+	number int
+	hash   string
+}
+
+// hashedLines returns the distinct hashed lines of a file in line order
+func hashedLines(file *File) []hashedLine {
+	hashes := lineHashes(file)
+	lines := make([]hashedLine, 0, len(hashes))
+	for number, hash := range hashes {
+		lines = append(lines, hashedLine{number: number, hash: hash})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].number < lines[j].number
+	})
+	return lines
+}
+
+// alignLineHashes pairs old and new lines with equal hashes, preserving order.
+// It returns old line number -> new line number.
+func alignLineHashes(oldLines, newLines []hashedLine) map[int]int {
+	mapping := make(map[int]int)
+
+	// Anchors: hashes occurring exactly once on each side
+	oldCount := make(map[string]int)
+	newIndex := make(map[string]int)
+	newCount := make(map[string]int)
+	for _, l := range oldLines {
+		oldCount[l.hash]++
+	}
+	for i, l := range newLines {
+		newCount[l.hash]++
+		newIndex[l.hash] = i
+	}
+
+	type pair struct{ oldIdx, newIdx int }
+	candidates := make([]pair, 0)
+	for i, l := range oldLines {
+		if oldCount[l.hash] == 1 && newCount[l.hash] == 1 {
+			candidates = append(candidates, pair{oldIdx: i, newIdx: newIndex[l.hash]})
+		}
+	}
+
+	// Keep the longest run of anchors that is increasing on both sides
+	newIdxs := make([]int, len(candidates))
+	for i, c := range candidates {
+		newIdxs[i] = c.newIdx
+	}
+	anchors := make([]pair, 0)
+	for _, i := range longestIncreasingSubsequence(newIdxs) {
+		anchors = append(anchors, candidates[i])
+	}
+
+	// Pair the lines between consecutive anchors in sequence
+	oldStart, newStart := 0, 0
+	anchors = append(anchors, pair{oldIdx: len(oldLines), newIdx: len(newLines)})
+	for _, a := range anchors {
+		j := newStart
+		for i := oldStart; i < a.oldIdx; i++ {
+			for k := j; k < a.newIdx; k++ {
+				if newLines[k].hash == oldLines[i].hash {
+					mapping[oldLines[i].number] = newLines[k].number
+					j = k + 1
+					break
+				}
+			}
+		}
+		if a.oldIdx < len(oldLines) {
+			mapping[oldLines[a.oldIdx].number] = newLines[a.newIdx].number
+		}
+		oldStart, newStart = a.oldIdx+1, a.newIdx+1
+	}
+
+	return mapping
+}
+
+// longestIncreasingSubsequence returns the indices of a longest strictly
+// increasing subsequence of values
+func longestIncreasingSubsequence(values []int) []int {
+	tails := make([]int, 0)          // index into values of the smallest tail per length
+	prev := make([]int, len(values)) // predecessor index per element
+	for i, v := range values {
+		pos := sort.Search(len(tails), func(k int) bool { return values[tails[k]] >= v })
+		if pos > 0 {
+			prev[i] = tails[pos-1]
+		} else {
+			prev[i] = -1
+		}
+		if pos == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[pos] = i
+		}
+	}
+
+	result := make([]int, len(tails))
+	if len(tails) == 0 {
+		return result
+	}
+	for i, k := len(tails)-1, tails[len(tails)-1]; i >= 0; i-- {
+		result[i] = k
+		k = prev[k]
+	}
+	return result
+}
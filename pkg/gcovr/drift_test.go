@@ -0,0 +1,140 @@
+package gcovr
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// hashedFile builds a report file whose lines carry the given hashes, starting at line start
+func hashedFile(path string, start int, counts []int, hashes ...string) File {
+	file := File{FilePath: path, Functions: []Function{{Name: "_Z1fv", DemangledName: "f()", LineNo: start}}}
+	for i, hash := range hashes {
+		file.Lines = append(file.Lines, Line{LineNumber: start + i, FunctionName: "_Z1fv", Count: counts[i], MD5: hash})
+	}
+	return file
+}
+
+func TestDetectSourceDrift(t *testing.T) {
+	base := &GcovrReport{Files: []File{
+		hashedFile("demo.cc", 1, []int{1, 1, 0}, "a", "b", "c"),
+		hashedFile("same.cc", 1, []int{1}, "x"),
+	}}
+	newReport := &GcovrReport{Files: []File{
+		hashedFile("demo.cc", 1, []int{1, 1, 0, 0}, "a", "B", "c", "d"),
+		hashedFile("same.cc", 1, []int{1}, "x"),
+		hashedFile("added.cc", 1, []int{1}, "y"),
+	}}
+
+	drift := DetectSourceDrift(base, newReport)
+
+	if !drift.HasDrift() || len(drift.Files) != 1 {
+		t.Fatalf("Expected drift in one file, got %+v", drift.Files)
+	}
+	if d := drift.Files[0]; d.FilePath != "demo.cc" || !reflect.DeepEqual(d.ChangedLines, []int{2}) || d.ComparedLines != 3 {
+		t.Errorf("Unexpected drift: %+v", d)
+	}
+
+	warnings := drift.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "1 of 3 compared lines changed") {
+		t.Errorf("Unexpected warnings: %v", warnings)
+	}
+}
+
+func TestDetectSourceDrift_NoHashes(t *testing.T) {
+	report := &GcovrReport{Files: []File{{FilePath: "demo.cc", Lines: []Line{{LineNumber: 1, Count: 1}}}}}
+	if drift := DetectSourceDrift(report, report); drift.HasDrift() {
+		t.Errorf("Expected no drift without hashes, got %+v", drift.Files)
+	}
+}
+
+func TestDetectSourceDrift_ActualTestData(t *testing.T) {
+	base, err := ParseReport("../../test_data/f.json")
+	if err != nil {
+		t.Fatalf("Failed to parse f.json: %v", err)
+	}
+	if base.Files[0].Lines[0].MD5 == "" {
+		t.Fatal("Expected gcovr/md5 to be parsed")
+	}
+
+	newReport, err := ParseReport("../../test_data/g.json")
+	if err != nil {
+		t.Fatalf("Failed to parse g.json: %v", err)
+	}
+	if drift := DetectSourceDrift(base, newReport); drift.HasDrift() {
+		t.Errorf("Expected reports from the same source to show no drift, got %+v", drift.Files)
+	}
+}
+
+func TestRemapReportByHash(t *testing.T) {
+	// Two lines inserted before the function and the "b" line edited
+	base := &GcovrReport{Files: []File{
+		hashedFile("demo.cc", 1, []int{1, 1, 0, 1}, "a", "b", "c", "close"),
+		{FilePath: "nohash.cc", Lines: []Line{{LineNumber: 4, Count: 1}}},
+	}}
+	newReport := &GcovrReport{Files: []File{
+		hashedFile("demo.cc", 3, []int{1, 1, 1, 1}, "a", "B", "c", "close"),
+		{FilePath: "nohash.cc", Lines: []Line{{LineNumber: 4, Count: 1}}},
+	}}
+
+	result := RemapReportByHash(base, newReport)
+
+	got := make(map[int]int)
+	for _, line := range result.Files[0].Lines {
+		got[line.LineNumber] = line.Count
+	}
+	expected := map[int]int{3: 1, 5: 0, 6: 1}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected remapped lines %v, got %v", expected, got)
+	}
+	if result.Files[0].Functions[0].LineNo != 3 {
+		t.Errorf("Expected function line 3, got %d", result.Files[0].Functions[0].LineNo)
+	}
+	if result.Files[1].Lines[0].LineNumber != 4 {
+		t.Error("Expected files without hashes to be copied unchanged")
+	}
+
+	increases, err := ComputeCoverageIncrease(result, newReport)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(increases.Increases) != 1 {
+		t.Fatalf("Expected one function with increases, got %+v", increases.Increases)
+	}
+	increased := append([]int{}, increases.Increases[0].IncreasedLineNumbers...)
+	sort.Ints(increased)
+	if !reflect.DeepEqual(increased, []int{4, 5}) {
+		t.Errorf("Expected only the edited line 4 and line 5 to be newly covered, got %+v", increases.Increases)
+	}
+}
+
+func TestAlignLineHashes_Duplicates(t *testing.T) {
+	// Repeated "}" lines are paired in order between the unique anchors
+	oldLines := []hashedLine{{1, "f"}, {2, "}"}, {3, "g"}, {4, "}"}}
+	newLines := []hashedLine{{1, "new"}, {2, "f"}, {3, "}"}, {4, "g"}, {5, "}"}}
+
+	mapping := alignLineHashes(oldLines, newLines)
+	expected := map[int]int{1: 2, 2: 3, 3: 4, 4: 5}
+	if !reflect.DeepEqual(mapping, expected) {
+		t.Errorf("Expected %v, got %v", expected, mapping)
+	}
+}
+
+func TestLongestIncreasingSubsequence(t *testing.T) {
+	tests := []struct {
+		values   []int
+		expected []int
+	}{
+		{values: nil, expected: []int{}},
+		{values: []int{0, 1, 2}, expected: []int{0, 1, 2}},
+		{values: []int{2, 0, 1}, expected: []int{1, 2}},
+		{values: []int{3, 1, 2, 0, 4}, expected: []int{1, 2, 4}},
+	}
+
+	for _, tt := range tests {
+		if got := longestIncreasingSubsequence(tt.values); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("longestIncreasingSubsequence(%v): expected %v, got %v", tt.values, tt.expected, got)
+		}
+	}
+}
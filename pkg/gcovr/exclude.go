@@ -0,0 +1,217 @@
+package gcovr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// exclusionMarker matches the gcovr and lcov exclusion markers, e.g.
+// GCOVR_EXCL_LINE, LCOV_EXCL_START or GCOVR_EXCL_BR_STOP
+var exclusionMarker = regexp.MustCompile(`\b(GCOVR|LCOV)_EXCL_(BR_)?(LINE|START|STOP)\b`)
+
+// SourceExclusions holds the lines of one source file marked for exclusion
+type SourceExclusions struct {
+	Lines    map[int]bool // Lines excluded entirely
+	Branches map[int]bool // Lines whose branches are excluded
+	Warnings []string     // Unbalanced START/STOP markers
+}
+
+// FileExclusions records what was removed from one report file
+type FileExclusions struct {
+	FilePath      string
+	ExcludedLines []int // Line numbers removed from the report
+	BranchLines   []int // Line numbers whose branches were removed
+}
+
+// ExclusionReport describes the effect of applying exclusion markers to a report
+type ExclusionReport struct {
+	Files    []FileExclusions
+	Missing  []string // Report files whose source could not be read
+	Warnings []string
+}
+
+// ScanExclusions reads a source file and collects the lines marked with
+// GCOVR_EXCL_* or LCOV_EXCL_* markers. A START marker without a matching
+// STOP excludes everything up to the end of the file.
+func ScanExclusions(r io.Reader) (*SourceExclusions, error) {
+	result := &SourceExclusions{
+		Lines:    make(map[int]bool),
+		Branches: make(map[int]bool),
+		Warnings: make([]string, 0),
+	}
+
+	// Open regions keyed by marker prefix and kind, e.g. "GCOVR_EXCL_BR_"
+	open := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+
+		for _, m := range exclusionMarker.FindAllStringSubmatch(scanner.Text(), -1) {
+			region := m[1] + "_EXCL_" + m[2]
+			target := result.Lines
+			if m[2] != "" {
+				target = result.Branches
+			}
+
+			switch m[3] {
+			case "LINE":
+				target[lineNum] = true
+			case "START":
+				if start, ok := open[region]; ok {
+					result.Warnings = append(result.Warnings, fmt.Sprintf(
+						"line %d: %sSTART inside the region started on line %d", lineNum, region, start))
+					continue
+				}
+				open[region] = lineNum
+			case "STOP":
+				if _, ok := open[region]; !ok {
+					result.Warnings = append(result.Warnings, fmt.Sprintf(
+						"line %d: %sSTOP without a matching %sSTART", lineNum, region, region))
+					continue
+				}
+				// Earlier lines of the region were excluded as they were read
+				target[lineNum] = true
+				delete(open, region)
+			}
+		}
+
+		// Lines inside an open region are excluded as they are read
+		for region := range open {
+			if strings.HasSuffix(region, "_BR_") {
+				result.Branches[lineNum] = true
+			} else {
+				result.Lines[lineNum] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	regions := make([]string, 0, len(open))
+	for region := range open {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"line %d: %sSTART without a matching %sSTOP", open[region], region, region))
+	}
+
+	return result, nil
+}
+
+// ApplyExclusions removes lines and branches marked with exclusion markers
+// from a report. Source files are looked up under sourceRoot unless their
+// report path is absolute; files that cannot be read are left untouched and
+// listed in the returned ExclusionReport. Function records declared on an
+// excluded line are removed as well. The input report is not modified.
+func ApplyExclusions(report *GcovrReport, sourceRoot string) (*GcovrReport, *ExclusionReport, error) {
+	result := &GcovrReport{
+		FormatVersion: report.FormatVersion,
+		Files:         make([]File, 0, len(report.Files)),
+	}
+	summary := &ExclusionReport{
+		Files:    make([]FileExclusions, 0),
+		Missing:  make([]string, 0),
+		Warnings: make([]string, 0),
+	}
+
+	for _, file := range report.Files {
+		exclusions, err := scanSourceFile(resolveSourcePath(sourceRoot, file.FilePath))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, nil, fmt.Errorf("failed to scan %s for exclusion markers: %w", file.FilePath, err)
+			}
+			summary.Missing = append(summary.Missing, file.FilePath)
+			result.Files = append(result.Files, file)
+			continue
+		}
+
+		for _, warning := range exclusions.Warnings {
+			summary.Warnings = append(summary.Warnings, fmt.Sprintf("%s: %s", file.FilePath, warning))
+		}
+
+		excludedFile, fileExclusions := excludeFileLines(file, exclusions)
+		if len(fileExclusions.ExcludedLines) > 0 || len(fileExclusions.BranchLines) > 0 {
+			summary.Files = append(summary.Files, fileExclusions)
+		}
+		result.Files = append(result.Files, excludedFile)
+	}
+
+	return result, summary, nil
+}
+
+// scanSourceFile opens a source file and scans it for exclusion markers
+func scanSourceFile(path string) (*SourceExclusions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ScanExclusions(f)
+}
+
+// resolveSourcePath locates a report file path on disk
+func resolveSourcePath(sourceRoot, filePath string) string {
+	if filepath.IsAbs(filePath) || sourceRoot == "" {
+		return filePath
+	}
+	return filepath.Join(sourceRoot, filePath)
+}
+
+// excludeFileLines returns a copy of a report file with excluded lines and branches removed
+func excludeFileLines(file File, exclusions *SourceExclusions) (File, FileExclusions) {
+	excluded := File{
+		FilePath:  file.FilePath,
+		Lines:     make([]Line, 0, len(file.Lines)),
+		Functions: make([]Function, 0, len(file.Functions)),
+	}
+	record := FileExclusions{
+		FilePath:      file.FilePath,
+		ExcludedLines: make([]int, 0),
+		BranchLines:   make([]int, 0),
+	}
+
+	for _, line := range file.Lines {
+		if exclusions.Lines[line.LineNumber] {
+			record.ExcludedLines = append(record.ExcludedLines, line.LineNumber)
+			continue
+		}
+		if exclusions.Branches[line.LineNumber] && len(line.Branches) > 0 {
+			line.Branches = make([]Branch, 0)
+			record.BranchLines = append(record.BranchLines, line.LineNumber)
+		}
+		excluded.Lines = append(excluded.Lines, line)
+	}
+
+	for _, fn := range file.Functions {
+		if !exclusions.Lines[fn.LineNo] {
+			excluded.Functions = append(excluded.Functions, fn)
+		}
+	}
+
+	return excluded, record
+}
+
+// FormatExclusionReport formats a short summary of the applied exclusions
+func FormatExclusionReport(report *ExclusionReport) string {
+	lines, branches := 0, 0
+	for _, file := range report.Files {
+		lines += len(file.ExcludedLines)
+		branches += len(file.BranchLines)
+	}
+
+	return fmt.Sprintf("Excluded %d line(s) and the branches of %d line(s) in %d file(s)\n",
+		lines, branches, len(report.Files))
+}
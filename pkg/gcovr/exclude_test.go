@@ -0,0 +1,145 @@
+package gcovr
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sortedKeys(m map[int]bool) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func TestScanExclusions(t *testing.T) {
+	source := `int f(int x) {
+  abort(); // GCOVR_EXCL_LINE
+  // LCOV_EXCL_START
+  debug();
+  // LCOV_EXCL_STOP
+  if (x) return 1; // GCOVR_EXCL_BR_LINE
+  // GCOVR_EXCL_BR_START
+  if (x > 1) g();
+  // GCOVR_EXCL_BR_STOP
+  return 0;
+}
+`
+
+	result, err := ScanExclusions(strings.NewReader(source))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := sortedKeys(result.Lines); !reflect.DeepEqual(got, []int{2, 3, 4, 5}) {
+		t.Errorf("Expected excluded lines [2 3 4 5], got %v", got)
+	}
+	if got := sortedKeys(result.Branches); !reflect.DeepEqual(got, []int{6, 7, 8, 9}) {
+		t.Errorf("Expected excluded branch lines [6 7 8 9], got %v", got)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", result.Warnings)
+	}
+}
+
+func TestScanExclusions_Unbalanced(t *testing.T) {
+	source := `a(); // GCOVR_EXCL_STOP
+b();
+// GCOVR_EXCL_START
+c();
+`
+
+	result, err := ScanExclusions(strings.NewReader(source))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := sortedKeys(result.Lines); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("Expected an unterminated region to run to the end of file, got %v", got)
+	}
+	if len(result.Warnings) != 2 {
+		t.Errorf("Expected 2 warnings, got %v", result.Warnings)
+	}
+}
+
+func TestApplyExclusions(t *testing.T) {
+	dir := t.TempDir()
+	source := `int f(int x) {
+  if (x) // GCOVR_EXCL_BR_LINE
+    return 1;
+  abort(); // LCOV_EXCL_LINE
+  return 0;
+}
+void unused() { } // GCOVR_EXCL_LINE
+`
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatalf("Failed to create source dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "demo.cc"), []byte(source), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	report := &GcovrReport{
+		FormatVersion: "0.14",
+		Files: []File{
+			{
+				FilePath: "src/demo.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1fi", Count: 1},
+					{LineNumber: 2, FunctionName: "_Z1fi", Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+					{LineNumber: 3, FunctionName: "_Z1fi", Count: 1},
+					{LineNumber: 4, FunctionName: "_Z1fi", Count: 0},
+					{LineNumber: 5, FunctionName: "_Z1fi", Count: 0},
+					{LineNumber: 7, FunctionName: "_Z6unusedv", Count: 0},
+				},
+				Functions: []Function{
+					{Name: "_Z1fi", LineNo: 1},
+					{Name: "_Z6unusedv", LineNo: 7},
+				},
+			},
+			{FilePath: "missing.cc"},
+		},
+	}
+
+	result, summary, err := ApplyExclusions(report, dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	file := result.Files[0]
+	var lineNumbers []int
+	for _, line := range file.Lines {
+		lineNumbers = append(lineNumbers, line.LineNumber)
+	}
+	if !reflect.DeepEqual(lineNumbers, []int{1, 2, 3, 5}) {
+		t.Errorf("Expected lines [1 2 3 5], got %v", lineNumbers)
+	}
+	if len(file.Lines[1].Branches) != 0 {
+		t.Errorf("Expected branches on line 2 to be removed, got %v", file.Lines[1].Branches)
+	}
+	if len(file.Functions) != 1 || file.Functions[0].Name != "_Z1fi" {
+		t.Errorf("Expected only _Z1fi to remain, got %+v", file.Functions)
+	}
+
+	if len(report.Files[0].Lines) != 6 || len(report.Files[0].Lines[1].Branches) != 2 {
+		t.Error("Expected the input report to be left unmodified")
+	}
+
+	if !reflect.DeepEqual(summary.Missing, []string{"missing.cc"}) {
+		t.Errorf("Expected missing.cc to be reported missing, got %v", summary.Missing)
+	}
+	if len(summary.Files) != 1 || !reflect.DeepEqual(summary.Files[0].ExcludedLines, []int{4, 7}) {
+		t.Errorf("Expected excluded lines [4 7], got %+v", summary.Files)
+	}
+
+	output := FormatExclusionReport(summary)
+	if !strings.Contains(output, "Excluded 2 line(s) and the branches of 1 line(s) in 1 file(s)") {
+		t.Errorf("Unexpected summary: %q", output)
+	}
+}
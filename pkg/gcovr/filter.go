@@ -9,19 +9,32 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// File matching modes for filter targets
+const (
+	// MatchBasename matches the full path first and falls back to the file name
+	MatchBasename = "basename"
+	// MatchSuffix matches whole trailing path components; the longest suffix wins
+	MatchSuffix = "suffix"
+)
+
 // FilterConfig represents the filter configuration file structure
 type FilterConfig struct {
 	Compiler struct {
-		Path          string `yaml:"path"`
-		GcovrExecPath string `yaml:"gcovr_exec_path"`
+		Path          string   `yaml:"path"`
+		Args          []string `yaml:"args"` // Arguments placed before the test input by Collect
+		GcovrExecPath string   `yaml:"gcovr_exec_path"`
 	} `yaml:"compiler"`
-	Targets []TargetFile `yaml:"targets"`
+	Match      string       `yaml:"match"`  // File matching mode, defaults to MatchBasename
+	Strict     bool         `yaml:"strict"` // Fail when a target matches more than one file
+	Targets    []TargetFile `yaml:"targets"`
+	Thresholds *Thresholds  `yaml:"thresholds"` // Minimum coverage checked by the check command
 }
 
 // TargetFile represents a file and its target functions to track
 type TargetFile struct {
 	File      string   `yaml:"file"`
 	Functions []string `yaml:"functions"`
+	Lines     []string `yaml:"lines"` // Line ranges such as "1200-1350" or "1402"
 }
 
 // ParseFilterConfig reads and parses a filter configuration file
@@ -36,27 +49,108 @@ func ParseFilterConfig(filePath string) (*FilterConfig, error) {
 		return nil, fmt.Errorf("failed to parse YAML from %s: %w", filePath, err)
 	}
 
+	if err := validateMatchMode(config.Match); err != nil {
+		return nil, fmt.Errorf("invalid filter config %s: %w", filePath, err)
+	}
+
+	for _, target := range config.Targets {
+		if _, err := parseLineRanges(target.Lines); err != nil {
+			return nil, fmt.Errorf("invalid filter config %s: target %q: %w", filePath, target.File, err)
+		}
+	}
+
+	if err := checkDuplicateTargets(config.Targets); err != nil {
+		return nil, fmt.Errorf("invalid filter config %s: %w", filePath, err)
+	}
+
+	if err := config.Thresholds.validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter config %s: %w", filePath, err)
+	}
+
 	return &config, nil
 }
 
 // ApplyFilter filters a GcovrReport based on the filter configuration
-// It only keeps files and functions specified in the targets
+// It only keeps files and functions specified in the targets.
+// Strict mode is ignored, unknown match modes fall back to MatchBasename,
+// invalid line ranges are skipped and only the first of several targets
+// naming the same file is used; use ApplyFilterWithMatches to have them
+// reported as errors.
 func ApplyFilter(report *GcovrReport, config *FilterConfig) *GcovrReport {
-	if config == nil || len(config.Targets) == 0 {
+	if config == nil {
 		return report
 	}
 
-	// Build a map of file -> allowed functions
-	filterMap := make(map[string]map[string]bool)
+	lenient := *config
+	lenient.Strict = false
+	if validateMatchMode(lenient.Match) != nil {
+		lenient.Match = MatchBasename
+	}
+
+	lenient.Targets = make([]TargetFile, 0, len(config.Targets))
+	seen := make(map[string]bool)
 	for _, target := range config.Targets {
+		if key := normalizeFilePath(target.File); !seen[key] {
+			seen[key] = true
+			target.Lines = validLineRanges(target.Lines)
+			lenient.Targets = append(lenient.Targets, target)
+		}
+	}
+
+	filtered, _, _ := ApplyFilterWithMatches(report, &lenient)
+	return filtered
+}
+
+// ApplyFilterWithMatches filters a GcovrReport like ApplyFilter and also
+// returns a FilterMatchReport describing what each target resolved to.
+// In strict mode an ambiguous target is an error; the match report is
+// still returned so the caller can show what the target resolved to.
+//
+// A target with line ranges keeps only the lines inside those ranges (of its
+// target functions, if any are listed) and the functions enclosing them.
+func ApplyFilterWithMatches(report *GcovrReport, config *FilterConfig) (*GcovrReport, *FilterMatchReport, error) {
+	if config == nil || len(config.Targets) == 0 {
+		return report, &FilterMatchReport{}, nil
+	}
+
+	if err := validateMatchMode(config.Match); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDuplicateTargets(config.Targets); err != nil {
+		return nil, nil, err
+	}
+
+	// Build a map of file -> index of the target that owns it
+	filterMap := make(map[string]int)
+	targetRanges := make([][]LineRange, len(config.Targets))
+	matches := &FilterMatchReport{
+		Targets: make([]TargetMatch, len(config.Targets)),
+	}
+	for i, target := range config.Targets {
+		ranges, err := parseLineRanges(target.Lines)
+		if err != nil {
+			return nil, nil, fmt.Errorf("filter target %q: %w", target.File, err)
+		}
+		targetRanges[i] = ranges
+
 		// Normalize file paths for comparison
-		normalizedFile := normalizeFilePath(target.File)
+		filterMap[normalizeFilePath(target.File)] = i
 
-		funcMap := make(map[string]bool)
-		for _, fn := range target.Functions {
-			funcMap[fn] = true
+		matches.Targets[i] = TargetMatch{
+			File:      target.File,
+			Files:     make([]string, 0),
+			Functions: make([]FunctionMatch, len(target.Functions)),
+			Ranges:    make([]RangeMatch, len(ranges)),
+		}
+		for j, r := range ranges {
+			matches.Targets[i].Ranges[j] = RangeMatch{Range: r}
+		}
+		for j, fn := range target.Functions {
+			matches.Targets[i].Functions[j] = FunctionMatch{
+				Name:    fn,
+				Matches: make([]MatchedFunction, 0),
+			}
 		}
-		filterMap[normalizedFile] = funcMap
 	}
 
 	// Filter the report
@@ -66,19 +160,15 @@ func ApplyFilter(report *GcovrReport, config *FilterConfig) *GcovrReport {
 	}
 
 	for _, file := range report.Files {
-		normalizedFilePath := normalizeFilePath(file.FilePath)
-
 		// Check if this file is in the filter
-		allowedFunctions, fileInFilter := filterMap[normalizedFilePath]
+		targetIdx, fileInFilter := resolveTargetFile(file.FilePath, filterMap, config.Match)
 		if !fileInFilter {
-			// Try matching just the filename
-			fileName := filepath.Base(file.FilePath)
-			allowedFunctions, fileInFilter = filterMap[fileName]
-			if !fileInFilter {
-				continue // Skip this file
-			}
+			continue // Skip this file
 		}
 
+		target := &matches.Targets[targetIdx]
+		target.Files = append(target.Files, file.FilePath)
+
 		// Filter lines and functions
 		filteredFile := File{
 			FilePath:  file.FilePath,
@@ -86,32 +176,194 @@ func ApplyFilter(report *GcovrReport, config *FilterConfig) *GcovrReport {
 			Functions: make([]Function, 0),
 		}
 
-		// Filter functions
+		// Filter functions, recording which target names matched them
 		for _, fn := range file.Functions {
-			if shouldIncludeFunction(fn.DemangledName, fn.Name, allowedFunctions) {
+			included := false
+			for j := range target.Functions {
+				fm := &target.Functions[j]
+				if !functionMatchesName(fn.DemangledName, fn.Name, fm.Name) {
+					continue
+				}
+				fm.Matches = append(fm.Matches, MatchedFunction{
+					File:          file.FilePath,
+					Name:          fn.Name,
+					DemangledName: functionDisplayName(fn.Name, fn.DemangledName),
+				})
+				included = true
+			}
+			if included {
 				filteredFile.Functions = append(filteredFile.Functions, fn)
 			}
 		}
 
-		// Filter lines to only include those from allowed functions
-		allowedFuncNames := make(map[string]bool)
-		for _, fn := range filteredFile.Functions {
-			allowedFuncNames[fn.Name] = true
-		}
+		if ranges := targetRanges[targetIdx]; len(ranges) > 0 {
+			filterLineRanges(&file, &filteredFile, target, ranges)
+		} else {
+			// Filter lines to only include those from allowed functions
+			allowedFuncNames := make(map[string]bool)
+			for _, fn := range filteredFile.Functions {
+				allowedFuncNames[fn.Name] = true
+			}
 
-		for _, line := range file.Lines {
-			if allowedFuncNames[line.FunctionName] {
-				filteredFile.Lines = append(filteredFile.Lines, line)
+			for _, line := range file.Lines {
+				if allowedFuncNames[line.FunctionName] {
+					filteredFile.Lines = append(filteredFile.Lines, line)
+				}
 			}
 		}
 
 		// Only add the file if it has content after filtering
-		if len(filteredFile.Functions) > 0 {
+		if len(filteredFile.Functions) > 0 || len(filteredFile.Lines) > 0 {
 			filteredReport.Files = append(filteredReport.Files, filteredFile)
 		}
 	}
 
-	return filteredReport
+	if config.Strict {
+		for i := range matches.Targets {
+			if matches.Targets[i].Ambiguous() {
+				return nil, matches, fmt.Errorf("filter target %q is ambiguous, matched %d files: %s",
+					matches.Targets[i].File, len(matches.Targets[i].Files), strings.Join(matches.Targets[i].Files, ", "))
+			}
+		}
+	}
+
+	return filteredReport, matches, nil
+}
+
+// filterLineRanges narrows a filtered file to the lines inside a target's
+// line ranges. Lines must also belong to one of the target functions when the
+// target lists any; only functions enclosing a kept line are retained.
+func filterLineRanges(file, filteredFile *File, target *TargetMatch, ranges []LineRange) {
+	allowedFuncNames := make(map[string]bool)
+	for _, fn := range filteredFile.Functions {
+		allowedFuncNames[fn.Name] = true
+	}
+
+	keptFuncNames := make(map[string]bool)
+	for _, line := range file.Lines {
+		if len(target.Functions) > 0 && !allowedFuncNames[line.FunctionName] {
+			continue
+		}
+
+		inRange := false
+		for j, r := range ranges {
+			if r.Contains(line.LineNumber) {
+				target.Ranges[j].Lines++
+				inRange = true
+			}
+		}
+		if !inRange {
+			continue
+		}
+
+		filteredFile.Lines = append(filteredFile.Lines, line)
+		keptFuncNames[line.FunctionName] = true
+	}
+
+	// Keep the records of the functions enclosing the kept lines
+	candidates := file.Functions
+	if len(target.Functions) > 0 {
+		candidates = filteredFile.Functions
+	}
+	functions := make([]Function, 0)
+	for _, fn := range candidates {
+		if keptFuncNames[fn.Name] {
+			functions = append(functions, fn)
+		}
+	}
+	filteredFile.Functions = functions
+}
+
+// validLineRanges returns the line range specs that parse successfully
+func validLineRanges(specs []string) []string {
+	valid := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		if _, err := ParseLineRange(spec); err == nil {
+			valid = append(valid, spec)
+		}
+	}
+	return valid
+}
+
+// checkDuplicateTargets rejects targets naming the same file, since only
+// one of them could ever match it
+func checkDuplicateTargets(targets []TargetFile) error {
+	seen := make(map[string]string)
+	for _, target := range targets {
+		key := normalizeFilePath(target.File)
+		if first, ok := seen[key]; ok {
+			return fmt.Errorf("filter targets %q and %q name the same file; merge them into one target", first, target.File)
+		}
+		seen[key] = target.File
+	}
+	return nil
+}
+
+// validateMatchMode checks that a file matching mode is known
+func validateMatchMode(mode string) error {
+	switch mode {
+	case "", MatchBasename, MatchSuffix:
+		return nil
+	default:
+		return fmt.Errorf("unknown match mode %q (expected %q or %q)", mode, MatchBasename, MatchSuffix)
+	}
+}
+
+// resolveTargetFile finds the index of the target that owns a report file
+func resolveTargetFile(filePath string, filterMap map[string]int, mode string) (int, bool) {
+	normalizedFilePath := normalizeFilePath(filePath)
+
+	// An exact path match always wins
+	if idx, ok := filterMap[normalizedFilePath]; ok {
+		return idx, true
+	}
+
+	if mode == MatchSuffix {
+		return longestSuffixTarget(normalizedFilePath, filterMap)
+	}
+
+	// Try matching just the filename
+	idx, ok := filterMap[filepath.Base(filePath)]
+	return idx, ok
+}
+
+// longestSuffixTarget returns the target sharing the most trailing path
+// components with the file. The target must be a whole-component suffix of
+// the file path, so "cp/expr.cc" matches "gcc/cp/expr.cc" but not
+// "gcc/expr.cc", and the more specific "a/b/foo.c" does not match "foo.c".
+func longestSuffixTarget(normalizedFilePath string, filterMap map[string]int) (int, bool) {
+	fileParts := strings.Split(normalizedFilePath, "/")
+
+	bestIdx, bestLen := 0, 0
+	for targetPath, idx := range filterMap {
+		targetParts := strings.Split(targetPath, "/")
+		n := pathSuffixLength(fileParts, targetParts)
+		if n != len(targetParts) {
+			continue
+		}
+		if n > bestLen || (n == bestLen && n > 0 && idx < bestIdx) {
+			bestIdx, bestLen = idx, n
+		}
+	}
+
+	return bestIdx, bestLen > 0
+}
+
+// pathSuffixLength returns how many trailing components two paths share,
+// or 0 when neither path is a whole-component suffix of the other
+func pathSuffixLength(a, b []string) int {
+	n := 0
+	for n < len(a) && n < len(b) {
+		if a[len(a)-1-n] != b[len(b)-1-n] {
+			break
+		}
+		n++
+	}
+
+	if n != len(a) && n != len(b) {
+		return 0
+	}
+	return n
 }
 
 // normalizeFilePath normalizes file paths for comparison
@@ -122,27 +374,28 @@ func normalizeFilePath(path string) string {
 	return cleaned
 }
 
-// shouldIncludeFunction checks if a function should be included based on filter
-func shouldIncludeFunction(demangledName, mangledName string, allowedFunctions map[string]bool) bool {
-	// Check demangled name (strip parentheses for matching)
-	simpleName := demangledName
-	if idx := strings.Index(simpleName, "("); idx != -1 {
-		simpleName = simpleName[:idx]
+// functionMatchesName checks if a single filter function name selects a function.
+// Exact names are tried first, then C++-aware matching (see matchCppPattern).
+func functionMatchesName(demangledName, mangledName, name string) bool {
+	for _, key := range functionMatchKeys(demangledName, mangledName) {
+		if key == name {
+			return true
+		}
 	}
 
-	if allowedFunctions[simpleName] {
-		return true
-	}
+	return matchCppPattern(name, functionDisplayName(mangledName, demangledName))
+}
 
-	// Also check full demangled name
-	if allowedFunctions[demangledName] {
-		return true
-	}
+// functionMatchKeys returns the names a function can be targeted by:
+// the demangled name without parameters, the full demangled name and the mangled name
+func functionMatchKeys(demangledName, mangledName string) []string {
+	demangledName = functionDisplayName(mangledName, demangledName)
 
-	// Check mangled name
-	if allowedFunctions[mangledName] {
-		return true
+	// Strip parentheses for matching
+	simpleName := demangledName
+	if idx := strings.Index(simpleName, "("); idx != -1 {
+		simpleName = simpleName[:idx]
 	}
 
-	return false
+	return []string{simpleName, demangledName, mangledName}
 }
@@ -3,17 +3,19 @@ package gcovr
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
 func TestParseFilterConfig(t *testing.T) {
 	tests := []struct {
-		name           string
-		createFile     bool
-		fileContent    string
-		expectedError  bool
-		expectedFiles  int
-		expectedFuncs  int // functions in first target
+		name          string
+		createFile    bool
+		fileContent   string
+		expectedError bool
+		expectedFiles int
+		expectedFuncs int // functions in first target
 	}{
 		{
 			name:       "Valid filter config",
@@ -65,6 +67,37 @@ targets: []
 			expectedFiles: 0,
 			expectedFuncs: 0,
 		},
+		{
+			name:       "Target with line ranges",
+			createFile: true,
+			fileContent: `targets:
+  - file: "i386-expand.cc"
+    lines: ["1200-1350", "1402"]
+`,
+			expectedError: false,
+			expectedFiles: 1,
+			expectedFuncs: 0,
+		},
+		{
+			name:       "Invalid line range",
+			createFile: true,
+			fileContent: `targets:
+  - file: "i386-expand.cc"
+    lines: ["1350-1200"]
+`,
+			expectedError: true,
+		},
+		{
+			name:       "Duplicate target file",
+			createFile: true,
+			fileContent: `targets:
+  - file: "demo.cc"
+    functions: ["f"]
+  - file: "./demo.cc"
+    functions: ["g"]
+`,
+			expectedError: true,
+		},
 		{
 			name:          "File does not exist",
 			createFile:    false,
@@ -118,7 +151,7 @@ targets: []
 					t.Errorf("Expected %d target files, got %d", tt.expectedFiles, len(result.Targets))
 				}
 				if tt.expectedFiles > 0 && len(result.Targets[0].Functions) != tt.expectedFuncs {
-					t.Errorf("Expected %d functions in first target, got %d", 
+					t.Errorf("Expected %d functions in first target, got %d",
 						tt.expectedFuncs, len(result.Targets[0].Functions))
 				}
 			}
@@ -158,11 +191,11 @@ func TestParseFilterConfig_ActualTestData(t *testing.T) {
 
 func TestApplyFilter(t *testing.T) {
 	tests := []struct {
-		name           string
-		report         *GcovrReport
-		filterConfig   *FilterConfig
-		expectedFiles  int
-		expectedFuncs  int // functions in first file (if exists)
+		name          string
+		report        *GcovrReport
+		filterConfig  *FilterConfig
+		expectedFiles int
+		expectedFuncs int // functions in first file (if exists)
 	}{
 		{
 			name: "Filter single file and function",
@@ -319,7 +352,7 @@ func TestApplyFilter(t *testing.T) {
 			}
 
 			if tt.expectedFiles > 0 && len(result.Files[0].Functions) != tt.expectedFuncs {
-				t.Errorf("Expected %d functions in first file, got %d", 
+				t.Errorf("Expected %d functions in first file, got %d",
 					tt.expectedFuncs, len(result.Files[0].Functions))
 			}
 		})
@@ -412,18 +445,18 @@ func TestNormalizeFilePath(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := normalizeFilePath(tt.input)
-			
+
 			// Verify the function returns a consistent result
 			expected := filepath.ToSlash(filepath.Clean(tt.input))
 			if result != expected {
 				t.Errorf("Expected '%s', got '%s'", expected, result)
 			}
-			
+
 			// Verify result is not empty
 			if result == "" {
 				t.Error("Expected non-empty result")
 			}
-			
+
 			// Verify the result is cleaned (no double slashes, no trailing slashes except root)
 			if result != "." && result != "/" && len(result) > 1 && result[len(result)-1] == '/' {
 				t.Errorf("Expected no trailing slash, got '%s'", result)
@@ -432,61 +465,68 @@ func TestNormalizeFilePath(t *testing.T) {
 	}
 }
 
-func TestShouldIncludeFunction(t *testing.T) {
+func TestFunctionMatchesName(t *testing.T) {
 	tests := []struct {
-		name             string
-		demangledName    string
-		mangledName      string
-		allowedFunctions map[string]bool
-		expected         bool
+		name          string
+		demangledName string
+		mangledName   string
+		pattern       string
+		expected      bool
 	}{
 		{
-			name:             "Match by simple demangled name",
-			demangledName:    "foo()",
-			mangledName:      "_Z3foov",
-			allowedFunctions: map[string]bool{"foo": true},
-			expected:         true,
+			name:          "Match by simple demangled name",
+			demangledName: "foo()",
+			mangledName:   "_Z3foov",
+			pattern:       "foo",
+			expected:      true,
+		},
+		{
+			name:          "Match by full demangled name",
+			demangledName: "foo()",
+			mangledName:   "_Z3foov",
+			pattern:       "foo()",
+			expected:      true,
 		},
 		{
-			name:             "Match by full demangled name",
-			demangledName:    "foo()",
-			mangledName:      "_Z3foov",
-			allowedFunctions: map[string]bool{"foo()": true},
-			expected:         true,
+			name:          "Match by mangled name",
+			demangledName: "foo()",
+			mangledName:   "_Z3foov",
+			pattern:       "_Z3foov",
+			expected:      true,
 		},
 		{
-			name:             "Match by mangled name",
-			demangledName:    "foo()",
-			mangledName:      "_Z3foov",
-			allowedFunctions: map[string]bool{"_Z3foov": true},
-			expected:         true,
+			name:          "No match",
+			demangledName: "foo()",
+			mangledName:   "_Z3foov",
+			pattern:       "bar",
+			expected:      false,
 		},
 		{
-			name:             "No match",
-			demangledName:    "foo()",
-			mangledName:      "_Z3foov",
-			allowedFunctions: map[string]bool{"bar": true},
-			expected:         false,
+			name:          "Match with parameters",
+			demangledName: "calculate(int, double)",
+			mangledName:   "_Z9calculateid",
+			pattern:       "calculate",
+			expected:      true,
 		},
 		{
-			name:             "Match with parameters",
-			demangledName:    "calculate(int, double)",
-			mangledName:      "_Z9calculateid",
-			allowedFunctions: map[string]bool{"calculate": true},
-			expected:         true,
+			name:          "Match by qualified suffix",
+			demangledName: "ns::Foo::bar(int)",
+			mangledName:   "_ZN2ns3Foo3barEi",
+			pattern:       "Foo::bar",
+			expected:      true,
 		},
 		{
-			name:             "Empty allowed functions",
-			demangledName:    "foo()",
-			mangledName:      "_Z3foov",
-			allowedFunctions: map[string]bool{},
-			expected:         false,
+			name:          "Empty pattern",
+			demangledName: "foo()",
+			mangledName:   "_Z3foov",
+			pattern:       "",
+			expected:      false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := shouldIncludeFunction(tt.demangledName, tt.mangledName, tt.allowedFunctions)
+			result := functionMatchesName(tt.demangledName, tt.mangledName, tt.pattern)
 			if result != tt.expected {
 				t.Errorf("Expected %v, got %v", tt.expected, result)
 			}
@@ -592,3 +632,375 @@ func TestApplyFilter_PreservesFormatVersion(t *testing.T) {
 		t.Errorf("Expected FormatVersion='0.5', got '%s'", result.FormatVersion)
 	}
 }
+
+func TestApplyFilterWithMatches(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "src/demo.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+					{LineNumber: 5, FunctionName: "_Z1gv", Count: 0},
+				},
+				Functions: []Function{
+					{Name: "_Z1fv", DemangledName: "f()"},
+					{Name: "_Z1gv", DemangledName: "g()"},
+				},
+			},
+			{
+				FilePath: "lib/demo.cc",
+				Lines:    []Line{{LineNumber: 3, FunctionName: "_Z1fv", Count: 1}},
+				Functions: []Function{
+					{Name: "_Z1fv", DemangledName: "f()"},
+				},
+			},
+		},
+	}
+
+	filterConfig := &FilterConfig{
+		Targets: []TargetFile{
+			{File: "demo.cc", Functions: []string{"f", "missing"}},
+			{File: "other.cc", Functions: []string{"foo"}},
+		},
+	}
+
+	result, matches, err := ApplyFilterWithMatches(report, filterConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(result.Files))
+	}
+	if len(matches.Targets) != 2 {
+		t.Fatalf("Expected 2 target matches, got %d", len(matches.Targets))
+	}
+
+	demo := matches.Targets[0]
+	if !demo.Ambiguous() {
+		t.Errorf("Expected demo.cc target to be ambiguous, matched %v", demo.Files)
+	}
+	if len(demo.Functions[0].Matches) != 2 {
+		t.Errorf("Expected 'f' to match 2 functions, got %d", len(demo.Functions[0].Matches))
+	}
+	if dead := demo.DeadFunctions(); len(dead) != 1 || dead[0] != "missing" {
+		t.Errorf("Expected dead functions [missing], got %v", dead)
+	}
+
+	if !matches.Targets[1].Dead() {
+		t.Error("Expected other.cc target to be dead")
+	}
+	if !matches.HasDeadTargets() {
+		t.Error("Expected HasDeadTargets to be true")
+	}
+}
+
+func TestApplyFilterWithMatches_NilConfig(t *testing.T) {
+	report := &GcovrReport{Files: []File{{FilePath: "test.cpp"}}}
+
+	result, matches, err := ApplyFilterWithMatches(report, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result != report {
+		t.Error("Expected the original report to be returned")
+	}
+	if matches == nil || len(matches.Targets) != 0 {
+		t.Errorf("Expected empty match report, got %+v", matches)
+	}
+}
+
+func TestApplyFilterWithMatches_SuffixMode(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{FilePath: "gcc/expr.cc", Functions: []Function{{Name: "expand_expr", DemangledName: "expand_expr"}}},
+			{FilePath: "gcc/cp/expr.cc", Functions: []Function{{Name: "cp_expr", DemangledName: "cp_expr"}}},
+			{FilePath: "libcpp/expr.cc", Functions: []Function{{Name: "cpp_expr", DemangledName: "cpp_expr"}}},
+		},
+	}
+
+	filterConfig := &FilterConfig{
+		Match: MatchSuffix,
+		Targets: []TargetFile{
+			{File: "gcc/expr.cc", Functions: []string{"expand_expr"}},
+			{File: "cp/expr.cc", Functions: []string{"cp_expr"}},
+		},
+	}
+
+	result, matches, err := ApplyFilterWithMatches(report, filterConfig)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(result.Files))
+	}
+	if got := matches.Targets[0].Files; len(got) != 1 || got[0] != "gcc/expr.cc" {
+		t.Errorf("Expected gcc/expr.cc target to resolve to [gcc/expr.cc], got %v", got)
+	}
+	if got := matches.Targets[1].Files; len(got) != 1 || got[0] != "gcc/cp/expr.cc" {
+		t.Errorf("Expected cp/expr.cc target to resolve to [gcc/cp/expr.cc], got %v", got)
+	}
+}
+
+func TestApplyFilterWithMatches_SuffixModeSpecificTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected bool
+	}{
+		{name: "Target is a suffix of the file", file: "src/a/b/foo.c", expected: true},
+		{name: "Same path", file: "a/b/foo.c", expected: true},
+		{name: "File is a suffix of the target", file: "foo.c", expected: false},
+		{name: "Only the file name is shared", file: "x/foo.c", expected: false},
+		{name: "Partial directory", file: "b/foo.c", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &GcovrReport{
+				Files: []File{{FilePath: tt.file, Functions: []Function{{Name: "f", DemangledName: "f()"}}}},
+			}
+			filterConfig := &FilterConfig{
+				Match:   MatchSuffix,
+				Targets: []TargetFile{{File: "a/b/foo.c", Functions: []string{"f"}}},
+			}
+
+			_, matches, err := ApplyFilterWithMatches(report, filterConfig)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if matched := len(matches.Targets[0].Files) == 1; matched != tt.expected {
+				t.Errorf("Expected match %v for %s, got files %v", tt.expected, tt.file, matches.Targets[0].Files)
+			}
+		})
+	}
+}
+
+func TestApplyFilterWithMatches_Strict(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{FilePath: "gcc/expr.cc", Functions: []Function{{Name: "f", DemangledName: "f()"}}},
+			{FilePath: "libcpp/expr.cc", Functions: []Function{{Name: "f", DemangledName: "f()"}}},
+		},
+	}
+
+	filterConfig := &FilterConfig{
+		Strict:  true,
+		Targets: []TargetFile{{File: "expr.cc", Functions: []string{"f"}}},
+	}
+
+	result, matches, err := ApplyFilterWithMatches(report, filterConfig)
+	if err == nil {
+		t.Fatal("Expected ambiguity error in strict mode")
+	}
+	if result != nil {
+		t.Error("Expected nil report on error")
+	}
+	if matches == nil || len(matches.Targets[0].Files) != 2 {
+		t.Errorf("Expected match report listing both files, got %+v", matches)
+	}
+
+	// ApplyFilter ignores strict mode
+	if filtered := ApplyFilter(report, filterConfig); len(filtered.Files) != 2 {
+		t.Errorf("Expected ApplyFilter to keep 2 files, got %d", len(filtered.Files))
+	}
+}
+
+func TestApplyFilterWithMatches_InvalidMatchMode(t *testing.T) {
+	report := &GcovrReport{Files: []File{{FilePath: "test.cpp"}}}
+	filterConfig := &FilterConfig{
+		Match:   "glob",
+		Targets: []TargetFile{{File: "test.cpp", Functions: []string{"foo"}}},
+	}
+
+	if _, _, err := ApplyFilterWithMatches(report, filterConfig); err == nil {
+		t.Error("Expected error for unknown match mode")
+	}
+}
+
+func TestApplyFilterWithMatches_DuplicateTargets(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{{
+			FilePath: "demo.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+				{LineNumber: 5, FunctionName: "_Z1gv", Count: 0},
+			},
+			Functions: []Function{
+				{Name: "_Z1fv", DemangledName: "f()"},
+				{Name: "_Z1gv", DemangledName: "g()"},
+			},
+		}},
+	}
+	filterConfig := &FilterConfig{
+		Targets: []TargetFile{
+			{File: "demo.cc", Functions: []string{"f"}},
+			{File: "./demo.cc", Functions: []string{"g"}},
+		},
+	}
+
+	if _, _, err := ApplyFilterWithMatches(report, filterConfig); err == nil {
+		t.Error("Expected an error for two targets naming demo.cc")
+	}
+
+	// ApplyFilter uses the first target
+	filtered := ApplyFilter(report, filterConfig)
+	if len(filtered.Files) != 1 || len(filtered.Files[0].Functions) != 1 || filtered.Files[0].Functions[0].Name != "_Z1fv" {
+		t.Errorf("Expected ApplyFilter to keep only f(), got %+v", filtered.Files)
+	}
+}
+
+func TestPathSuffixLength(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"gcc/cp/expr.cc", "cp/expr.cc", 2},
+		{"gcc/cp/expr.cc", "expr.cc", 1},
+		{"gcc/expr.cc", "cp/expr.cc", 0},
+		{"gcc/expr.cc", "/src/gcc/expr.cc", 2},
+		{"gcc/xexpr.cc", "expr.cc", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got := pathSuffixLength(strings.Split(tt.a, "/"), strings.Split(tt.b, "/"))
+			if got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestApplyFilter_MissingDemangledNames(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "test.cpp",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_ZN2ns3Foo3barEi", Count: 1},
+					{LineNumber: 5, FunctionName: "_Z3bazv", Count: 0},
+				},
+				Functions: []Function{
+					{Name: "_ZN2ns3Foo3barEi"},
+					{Name: "_Z3bazv"},
+				},
+			},
+		},
+	}
+
+	filterConfig := &FilterConfig{
+		Targets: []TargetFile{{File: "test.cpp", Functions: []string{"Foo::bar"}}},
+	}
+
+	result := ApplyFilter(report, filterConfig)
+
+	if len(result.Files) != 1 || len(result.Files[0].Functions) != 1 {
+		t.Fatalf("Expected 1 matching function, got %+v", result.Files)
+	}
+	if result.Files[0].Functions[0].Name != "_ZN2ns3Foo3barEi" {
+		t.Errorf("Expected _ZN2ns3Foo3barEi, got %s", result.Files[0].Functions[0].Name)
+	}
+}
+
+func TestApplyFilter_LineRanges(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "expand.cc",
+				Lines: []Line{
+					{LineNumber: 10, FunctionName: "_Z3foov", Count: 1},
+					{LineNumber: 12, FunctionName: "_Z3foov", Count: 0},
+					{LineNumber: 20, FunctionName: "_Z3barv", Count: 0},
+					{LineNumber: 21, FunctionName: "_Z3barv", Count: 1},
+					{LineNumber: 30, FunctionName: "_Z3bazv", Count: 0},
+				},
+				Functions: []Function{
+					{Name: "_Z3foov", DemangledName: "foo()"},
+					{Name: "_Z3barv", DemangledName: "bar()"},
+					{Name: "_Z3bazv", DemangledName: "baz()"},
+				},
+			},
+		},
+	}
+
+	t.Run("Ranges only", func(t *testing.T) {
+		filterConfig := &FilterConfig{
+			Targets: []TargetFile{{File: "expand.cc", Lines: []string{"12-20", "30"}}},
+		}
+
+		result, matches, err := ApplyFilterWithMatches(report, filterConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Files) != 1 {
+			t.Fatalf("Expected 1 file, got %d", len(result.Files))
+		}
+
+		var lineNumbers []int
+		for _, line := range result.Files[0].Lines {
+			lineNumbers = append(lineNumbers, line.LineNumber)
+		}
+		if !reflect.DeepEqual(lineNumbers, []int{12, 20, 30}) {
+			t.Errorf("Expected lines [12 20 30], got %v", lineNumbers)
+		}
+		if len(result.Files[0].Functions) != 3 {
+			t.Errorf("Expected 3 enclosing functions, got %d", len(result.Files[0].Functions))
+		}
+		if got := matches.Targets[0].Ranges[0].Lines; got != 2 {
+			t.Errorf("Expected 2 lines in range 12-20, got %d", got)
+		}
+		if matches.HasDeadTargets() {
+			t.Errorf("Expected no dead targets, got %v", matches.Warnings())
+		}
+	})
+
+	t.Run("Ranges and functions", func(t *testing.T) {
+		filterConfig := &FilterConfig{
+			Targets: []TargetFile{{File: "expand.cc", Functions: []string{"bar", "baz"}, Lines: []string{"10-25"}}},
+		}
+
+		result := ApplyFilter(report, filterConfig)
+		if len(result.Files) != 1 {
+			t.Fatalf("Expected 1 file, got %d", len(result.Files))
+		}
+		if len(result.Files[0].Lines) != 2 {
+			t.Errorf("Expected 2 lines, got %d", len(result.Files[0].Lines))
+		}
+		if len(result.Files[0].Functions) != 1 || result.Files[0].Functions[0].Name != "_Z3barv" {
+			t.Errorf("Expected only _Z3barv, got %+v", result.Files[0].Functions)
+		}
+	})
+
+	t.Run("Dead range", func(t *testing.T) {
+		filterConfig := &FilterConfig{
+			Targets: []TargetFile{{File: "expand.cc", Lines: []string{"100-200"}}},
+		}
+
+		result, matches, err := ApplyFilterWithMatches(report, filterConfig)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(result.Files) != 0 {
+			t.Errorf("Expected no files, got %d", len(result.Files))
+		}
+		if !matches.HasDeadTargets() {
+			t.Error("Expected the empty range to be reported as dead")
+		}
+	})
+
+	t.Run("Invalid range", func(t *testing.T) {
+		filterConfig := &FilterConfig{
+			Targets: []TargetFile{{File: "expand.cc", Lines: []string{"x", "30"}}},
+		}
+
+		if _, _, err := ApplyFilterWithMatches(report, filterConfig); err == nil {
+			t.Error("Expected error for invalid line range")
+		}
+		if result := ApplyFilter(report, filterConfig); len(result.Files) != 1 || len(result.Files[0].Lines) != 1 {
+			t.Errorf("Expected ApplyFilter to skip the invalid range, got %+v", result.Files)
+		}
+	})
+}
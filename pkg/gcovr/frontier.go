@@ -0,0 +1,382 @@
+package gcovr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Frontier target kinds
+const (
+	FrontierBranch = "branch" // Untaken branch of an executed line
+	FrontierCall   = "call"   // Never-executed function called from an executed line
+)
+
+// FrontierTarget is uncovered code next to covered code: an untaken branch
+// of an executed line, or a function that was never executed although a
+// covered line calls it
+type FrontierTarget struct {
+	Kind          string
+	File          string // File of the executed line
+	Line          int    // Executed line holding the branch or call
+	FunctionName  string // Mangled name of the function holding Line
+	DemangledName string
+	Branch        int    // Index of the untaken branch among the line's branches; -1 for calls
+	CalleeFile    string // File of the called function (calls only)
+	Callee        string // Demangled name of the called function (calls only)
+	CallSites     []string
+	MissedLines   []int // Uncovered lines the target leads to, in CalleeFile for calls
+}
+
+// Score ranks targets: the number of uncovered lines reaching the target would cover
+func (t *FrontierTarget) Score() int {
+	return len(t.MissedLines)
+}
+
+// FrontierReport lists frontier targets, highest score first
+type FrontierReport struct {
+	Targets  []FrontierTarget
+	Missing  []string // Source files that could not be read to find callees
+	Warnings []string
+}
+
+// callPattern matches an identifier followed by an optional template
+// argument list and an opening parenthesis, e.g. "parse(" or "get<int> ("
+var callPattern = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*(?:<[^;(){}]*>)?\s*\(`)
+
+// FindFrontier finds the uncovered code that is one step away from covered
+// code, building on FindUncoveredLines.
+//
+// Branch targets are untaken branches of executed lines. The lines an
+// untaken branch leads to are the uncovered lines of the same function
+// holding its destination block (from the lines' block_ids), followed by
+// the uncovered lines after them up to the next covered line. A branch whose
+// destination block has no uncovered line leads nowhere new and scores 0.
+// Only for functions without any block ids are the uncovered lines directly
+// after the branch line counted instead. Exception edges are skipped.
+//
+// Call targets are functions with no covered line that a covered line with
+// recorded calls refers to by name. gcovr does not record which function a
+// call enters, so the callees are found by reading the call sites' source
+// under sourceRoot; without a sourceRoot no call targets are reported.
+//
+// Targets are ranked by the number of uncovered lines they lead to.
+func FindFrontier(report *GcovrReport, sourceRoot string) (*FrontierReport, error) {
+	uncovered, err := FindUncoveredLines(report)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FrontierReport{
+		Targets:  make([]FrontierTarget, 0),
+		Missing:  make([]string, 0),
+		Warnings: make([]string, 0),
+	}
+
+	dead := make([]deadFunction, 0)
+	for _, file := range uncovered.Files {
+		for _, fn := range file.UncoveredFunctions {
+			if fn.CoveredLines == 0 {
+				dead = append(dead, deadFunction{file: file.FilePath, fn: fn})
+			}
+		}
+	}
+
+	for _, file := range report.Files {
+		for _, lines := range groupLinesByFunction(file.Lines) {
+			result.Targets = append(result.Targets, branchTargets(file.FilePath, lines, file.Functions)...)
+		}
+	}
+
+	callTargets, err := findCallTargets(report, dead, sourceRoot, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Targets = append(result.Targets, callTargets...)
+
+	sort.Slice(result.Targets, func(i, j int) bool {
+		a, b := &result.Targets[i], &result.Targets[j]
+		if a.Score() != b.Score() {
+			return a.Score() > b.Score()
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Branch != b.Branch {
+			return a.Branch < b.Branch
+		}
+		if a.Callee != b.Callee {
+			return a.Callee < b.Callee
+		}
+		return a.CalleeFile < b.CalleeFile
+	})
+
+	return result, nil
+}
+
+// deadFunction is a function without any covered line
+type deadFunction struct {
+	file string
+	fn   FunctionUncovered
+}
+
+// branchTargets returns the untaken branches of the executed lines of one function
+func branchTargets(filePath string, lines []Line, functions []Function) []FrontierTarget {
+	sorted := append([]Line(nil), lines...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].LineNumber < sorted[j].LineNumber })
+
+	hasBlockIDs := false
+	for _, line := range sorted {
+		if len(line.BlockIDs) > 0 {
+			hasBlockIDs = true
+			break
+		}
+	}
+
+	targets := make([]FrontierTarget, 0)
+	for i, line := range sorted {
+		if line.Count == 0 {
+			continue
+		}
+		for b, branch := range line.Branches {
+			if branch.Count > 0 || branch.Throw {
+				continue
+			}
+
+			missed := make(map[int]bool)
+			if hasBlockIDs {
+				for j, dest := range sorted {
+					if dest.Count == 0 && containsInt(dest.BlockIDs, branch.DestinationBlockID) {
+						addUncoveredRun(sorted, j, missed)
+					}
+				}
+			} else if i+1 < len(sorted) {
+				addUncoveredRun(sorted, i+1, missed)
+			}
+
+			targets = append(targets, FrontierTarget{
+				Kind:          FrontierBranch,
+				File:          filePath,
+				Line:          line.LineNumber,
+				FunctionName:  line.FunctionName,
+				DemangledName: frontierFunctionName(line.FunctionName, functions),
+				Branch:        b,
+				MissedLines:   sortedLineSet(missed),
+			})
+		}
+	}
+	return targets
+}
+
+// addUncoveredRun adds the uncovered lines from lines[start] up to the next covered line
+func addUncoveredRun(lines []Line, start int, missed map[int]bool) {
+	for k := start; k < len(lines) && lines[k].Count == 0; k++ {
+		missed[lines[k].LineNumber] = true
+	}
+}
+
+// findCallTargets matches the call sites on covered lines against the names
+// of functions that were never executed
+func findCallTargets(report *GcovrReport, dead []deadFunction, sourceRoot string, result *FrontierReport) ([]FrontierTarget, error) {
+	targets := make([]FrontierTarget, 0)
+	if len(dead) == 0 {
+		return targets, nil
+	}
+
+	byName := make(map[string][]int) // Unqualified name -> indexes into dead
+	for i, d := range dead {
+		components := parseCppName(d.fn.DemangledName).Components
+		if len(components) == 0 {
+			continue
+		}
+		name := stripTemplateArgs(components[len(components)-1])
+		if name == "" || strings.HasPrefix(name, "operator") {
+			continue
+		}
+		byName[name] = append(byName[name], i)
+	}
+
+	type callSite struct {
+		file string
+		line Line
+	}
+	sites := make(map[int][]callSite)
+
+	for _, file := range report.Files {
+		hasCalls := false
+		for _, line := range file.Lines {
+			if line.Count > 0 && len(line.Calls) > 0 {
+				hasCalls = true
+				break
+			}
+		}
+		if !hasCalls {
+			continue
+		}
+		if sourceRoot == "" {
+			result.Warnings = append(result.Warnings,
+				"call targets need the source files; set a source root to find the functions covered lines call")
+			return targets, nil
+		}
+
+		source, err := readSourceLines(resolveSourcePath(sourceRoot, file.FilePath))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to read source of %s: %w", file.FilePath, err)
+			}
+			result.Missing = append(result.Missing, file.FilePath)
+			continue
+		}
+
+		for _, line := range file.Lines {
+			if line.Count == 0 || len(line.Calls) == 0 || line.LineNumber < 1 || line.LineNumber > len(source) {
+				continue
+			}
+			seen := make(map[int]bool)
+			for _, match := range callPattern.FindAllStringSubmatch(source[line.LineNumber-1], -1) {
+				for _, idx := range byName[match[1]] {
+					if !seen[idx] {
+						seen[idx] = true
+						sites[idx] = append(sites[idx], callSite{file: file.FilePath, line: line})
+					}
+				}
+			}
+		}
+	}
+
+	for idx, calls := range sites {
+		sort.Slice(calls, func(i, j int) bool {
+			if calls[i].file != calls[j].file {
+				return calls[i].file < calls[j].file
+			}
+			return calls[i].line.LineNumber < calls[j].line.LineNumber
+		})
+
+		first := calls[0]
+		target := FrontierTarget{
+			Kind:          FrontierCall,
+			File:          first.file,
+			Line:          first.line.LineNumber,
+			FunctionName:  first.line.FunctionName,
+			DemangledName: frontierFunctionName(first.line.FunctionName, functionsOf(report, first.file)),
+			Branch:        -1,
+			CalleeFile:    dead[idx].file,
+			Callee:        dead[idx].fn.DemangledName,
+			CallSites:     make([]string, 0, len(calls)),
+			MissedLines:   append([]int(nil), dead[idx].fn.UncoveredLineNumbers...),
+		}
+		for _, call := range calls {
+			site := fmt.Sprintf("%s:%d", call.file, call.line.LineNumber)
+			if n := len(target.CallSites); n == 0 || target.CallSites[n-1] != site {
+				target.CallSites = append(target.CallSites, site)
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// readSourceLines reads a source file into lines, without line terminators
+func readSourceLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	return lines, scanner.Err()
+}
+
+// functionsOf returns the function records of a report file
+func functionsOf(report *GcovrReport, filePath string) []Function {
+	for _, file := range report.Files {
+		if file.FilePath == filePath {
+			return file.Functions
+		}
+	}
+	return nil
+}
+
+// frontierFunctionName returns the display name of a function of a file
+func frontierFunctionName(name string, functions []Function) string {
+	for _, fn := range functions {
+		if fn.Name == name {
+			return functionDisplayName(name, fn.DemangledName)
+		}
+	}
+	return functionDisplayName(name, "")
+}
+
+// containsInt reports whether values contains v
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedLineSet returns the line numbers of a set in increasing order
+func sortedLineSet(set map[int]bool) []int {
+	keys := make([]int, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// FormatFrontierReport formats the frontier targets as a human-readable
+// string, listing at most top targets (all when top <= 0). Warnings and
+// missing sources are left to the caller.
+func FormatFrontierReport(report *FrontierReport, top int) string {
+	result := fmt.Sprintf("Coverage Frontier Report\n")
+	result += fmt.Sprintf("========================\n\n")
+
+	if len(report.Targets) == 0 {
+		result += "No frontier targets found.\n"
+		return result
+	}
+
+	targets := report.Targets
+	if top > 0 && top < len(targets) {
+		targets = targets[:top]
+		result += fmt.Sprintf("Top %d of %d frontier target(s):\n\n", top, len(report.Targets))
+	} else {
+		result += fmt.Sprintf("Found %d frontier target(s):\n\n", len(report.Targets))
+	}
+
+	for i, t := range targets {
+		switch t.Kind {
+		case FrontierCall:
+			result += fmt.Sprintf("%d. [call] %s:%d in %s calls %s\n", i+1, t.File, t.Line, t.DemangledName, t.Callee)
+			if len(t.CallSites) > 1 {
+				result += fmt.Sprintf("   Call sites: %s\n", strings.Join(t.CallSites, ", "))
+			}
+			result += fmt.Sprintf("   Leads to %d uncovered line(s) in %s: %s\n", t.Score(), t.CalleeFile, FormatLineRanges(CompactLineRanges(t.MissedLines)))
+		default:
+			result += fmt.Sprintf("%d. [branch] %s:%d in %s (branch %d)\n", i+1, t.File, t.Line, t.DemangledName, t.Branch)
+			result += fmt.Sprintf("   Leads to %d uncovered line(s): %s\n", t.Score(), FormatLineRanges(CompactLineRanges(t.MissedLines)))
+		}
+		result += "\n"
+	}
+
+	return result
+}
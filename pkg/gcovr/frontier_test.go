@@ -0,0 +1,168 @@
+package gcovr
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// frontierReport builds a report in which main (lines 1-9) branches on line 2
+// to block 5 (lines 3-4, covered) or block 6 (lines 5-6, not covered), and
+// calls the never-executed parse (lines 20-22) from line 8
+func frontierReport() *GcovrReport {
+	return &GcovrReport{Files: []File{{
+		FilePath: "demo.cc",
+		Lines: []Line{
+			{LineNumber: 1, FunctionName: "main", Count: 1},
+			{LineNumber: 2, FunctionName: "main", Count: 1, BlockIDs: []int{2}, Branches: []Branch{
+				{Count: 1, DestinationBlockID: 5},
+				{Count: 0, DestinationBlockID: 6},
+				{Count: 0, Throw: true, DestinationBlockID: 9},
+			}},
+			{LineNumber: 3, FunctionName: "main", Count: 1, BlockIDs: []int{5}},
+			{LineNumber: 4, FunctionName: "main", Count: 1},
+			{LineNumber: 5, FunctionName: "main", Count: 0, BlockIDs: []int{6}},
+			{LineNumber: 6, FunctionName: "main", Count: 0},
+			{LineNumber: 8, FunctionName: "main", Count: 1, BlockIDs: []int{7},
+				Calls: []Call{{SourceBlockID: 7, DestinationBlockID: 1, Returned: 1}}},
+			{LineNumber: 9, FunctionName: "main", Count: 1},
+			{LineNumber: 20, FunctionName: "_Z5parsePKc", Count: 0},
+			{LineNumber: 21, FunctionName: "_Z5parsePKc", Count: 0},
+			{LineNumber: 22, FunctionName: "_Z5parsePKc", Count: 0},
+		},
+		Functions: []Function{
+			{Name: "main", DemangledName: "main", LineNo: 1, ExecutionCount: 1},
+			{Name: "_Z5parsePKc", DemangledName: "parse(char const*)", LineNo: 20},
+		},
+	}}}
+}
+
+func TestFindFrontierBranches(t *testing.T) {
+	frontier, err := FindFrontier(frontierReport(), "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(frontier.Targets) != 1 {
+		t.Fatalf("Expected 1 target, got %+v", frontier.Targets)
+	}
+	target := frontier.Targets[0]
+	if target.Kind != FrontierBranch || target.Line != 2 || target.Branch != 1 || target.DemangledName != "main" {
+		t.Errorf("Unexpected target: %+v", target)
+	}
+	if !reflect.DeepEqual(target.MissedLines, []int{5, 6}) {
+		t.Errorf("Expected missed lines [5 6], got %v", target.MissedLines)
+	}
+
+	if len(frontier.Warnings) != 1 {
+		t.Errorf("Expected a warning about the missing source root, got %v", frontier.Warnings)
+	}
+}
+
+func TestFindFrontierWithoutBlockIDs(t *testing.T) {
+	report := frontierReport()
+	for i := range report.Files[0].Lines {
+		report.Files[0].Lines[i].BlockIDs = nil
+	}
+	// Without block ids the untaken branch of line 4 leads to the lines after it
+	report.Files[0].Lines[3].Branches = []Branch{{Count: 1}, {Count: 0}}
+
+	frontier, err := FindFrontier(report, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := make(map[int][]int)
+	for _, target := range frontier.Targets {
+		lines[target.Line] = target.MissedLines
+	}
+	if !reflect.DeepEqual(lines[4], []int{5, 6}) {
+		t.Errorf("Expected line 4 to lead to [5 6], got %v", lines[4])
+	}
+	if len(lines[2]) != 0 {
+		t.Errorf("Expected line 2 to lead to no uncovered line, got %v", lines[2])
+	}
+}
+
+func TestFindFrontierCoveredDestination(t *testing.T) {
+	// Both branches of line 1 were never taken, but block 3 (line 4) was
+	// reached another way; only branch 1 leads to uncovered lines
+	report := &GcovrReport{Files: []File{{
+		FilePath: "demo.cc",
+		Lines: []Line{
+			{LineNumber: 1, FunctionName: "f", Count: 1, BlockIDs: []int{2}, Branches: []Branch{
+				{Count: 0, DestinationBlockID: 3},
+				{Count: 0, DestinationBlockID: 4},
+			}},
+			{LineNumber: 2, FunctionName: "f", Count: 0, BlockIDs: []int{4}},
+			{LineNumber: 3, FunctionName: "f", Count: 0, BlockIDs: []int{4}},
+			{LineNumber: 4, FunctionName: "f", Count: 1, BlockIDs: []int{3}},
+		},
+	}}}
+
+	frontier, err := FindFrontier(report, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := make(map[int][]int)
+	for _, target := range frontier.Targets {
+		lines[target.Branch] = target.MissedLines
+	}
+	if len(lines) != 2 {
+		t.Fatalf("Expected a target for each branch, got %+v", frontier.Targets)
+	}
+	if len(lines[0]) != 0 {
+		t.Errorf("Expected branch 0 to lead to no uncovered line, got %v", lines[0])
+	}
+	if !reflect.DeepEqual(lines[1], []int{2, 3}) {
+		t.Errorf("Expected branch 1 to lead to [2 3], got %v", lines[1])
+	}
+}
+
+func TestFindFrontierCalls(t *testing.T) {
+	root := t.TempDir()
+	source := make([]string, 22)
+	source[7] = "  if (parse(argv[1]) != 0) return 1;"
+	if err := os.WriteFile(filepath.Join(root, "demo.cc"), []byte(strings.Join(source, "\n")), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+
+	frontier, err := FindFrontier(frontierReport(), root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(frontier.Targets) != 2 {
+		t.Fatalf("Expected 2 targets, got %+v", frontier.Targets)
+	}
+	call := frontier.Targets[0]
+	if call.Kind != FrontierCall || call.Line != 8 || call.Callee != "parse(char const*)" || call.CalleeFile != "demo.cc" {
+		t.Errorf("Expected the call to parse ranked first, got %+v", call)
+	}
+	if !reflect.DeepEqual(call.MissedLines, []int{20, 21, 22}) {
+		t.Errorf("Expected missed lines [20 21 22], got %v", call.MissedLines)
+	}
+	if len(frontier.Warnings) != 0 || len(frontier.Missing) != 0 {
+		t.Errorf("Expected no warnings, got %v and %v", frontier.Warnings, frontier.Missing)
+	}
+
+	output := FormatFrontierReport(frontier, 1)
+	if !strings.Contains(output, "Top 1 of 2 frontier target(s)") ||
+		!strings.Contains(output, "[call] demo.cc:8 in main calls parse(char const*)") ||
+		strings.Contains(output, "[branch]") {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+}
+
+func TestFindFrontierMissingSource(t *testing.T) {
+	frontier, err := FindFrontier(frontierReport(), t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(frontier.Missing, []string{"demo.cc"}) {
+		t.Errorf("Expected demo.cc to be missing, got %v", frontier.Missing)
+	}
+}
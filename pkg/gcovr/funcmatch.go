@@ -0,0 +1,325 @@
+package gcovr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Function matching strategies used by diff to pair base and new functions
+const (
+	// FunctionMatchMangled pairs functions with the same mangled name
+	FunctionMatchMangled = "mangled"
+	// FunctionMatchBaseName pairs functions with the same qualified name,
+	// ignoring parameter lists, so re-signatured functions are recognized
+	FunctionMatchBaseName = "base-name"
+	// FunctionMatchPosition pairs functions starting at the same pos or lineno
+	FunctionMatchPosition = "position"
+	// FunctionMatchFuzzy pairs the most similar remaining functions by name
+	// and covered line numbers
+	FunctionMatchFuzzy = "fuzzy"
+)
+
+// fuzzyMatchThreshold is the minimum similarity score for a fuzzy match
+const fuzzyMatchThreshold = 0.6
+
+// DiffOptions controls how ComputeCoverageIncreaseWithOptions compares reports
+type DiffOptions struct {
+	// FunctionMatch lists the strategies tried in order for functions whose
+	// mangled name appears in only one report. Mangled names are always
+	// matched first; an empty list matches by mangled name only.
+	FunctionMatch []string
+}
+
+// FunctionRename records a base function matched to a new function with a
+// different mangled name
+type FunctionRename struct {
+	File             string
+	OldName          string // Mangled name in the base report
+	OldDemangledName string
+	NewName          string // Mangled name in the new report
+	NewDemangledName string
+	Strategy         string // The strategy that matched the pair
+}
+
+// functionIdentity holds what is known about a function in one report file
+type functionIdentity struct {
+	Name          string
+	DemangledName string
+	LineNo        int
+	Pos           string // Start position, e.g. "5:6"
+	Lines         map[int]bool
+}
+
+// validateFunctionMatch checks that every function matching strategy is known
+func validateFunctionMatch(strategies []string) error {
+	for _, strategy := range strategies {
+		switch strategy {
+		case FunctionMatchMangled, FunctionMatchBaseName, FunctionMatchPosition, FunctionMatchFuzzy:
+		default:
+			return fmt.Errorf("unknown function match strategy %q (expected %q, %q, %q or %q)",
+				strategy, FunctionMatchMangled, FunctionMatchBaseName, FunctionMatchPosition, FunctionMatchFuzzy)
+		}
+	}
+	return nil
+}
+
+// functionIdentities collects the functions of a file from its function
+// entries and the function names of its lines, ordered by name
+func functionIdentities(file *File) []*functionIdentity {
+	byName := make(map[string]*functionIdentity)
+	for _, fn := range file.Functions {
+		id := &functionIdentity{
+			Name:          fn.Name,
+			DemangledName: functionDisplayName(fn.Name, fn.DemangledName),
+			LineNo:        fn.LineNo,
+			Lines:         make(map[int]bool),
+		}
+		if len(fn.Pos) > 0 {
+			id.Pos = fn.Pos[0]
+		}
+		byName[fn.Name] = id
+	}
+	for _, line := range file.Lines {
+		if line.FunctionName == "" {
+			continue
+		}
+		id, ok := byName[line.FunctionName]
+		if !ok {
+			id = &functionIdentity{
+				Name:          line.FunctionName,
+				DemangledName: DemangleName(line.FunctionName),
+				Lines:         make(map[int]bool),
+			}
+			byName[line.FunctionName] = id
+		}
+		id.Lines[line.LineNumber] = true
+	}
+
+	ids := make([]*functionIdentity, 0, len(byName))
+	for _, id := range byName {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Name < ids[j].Name
+	})
+	return ids
+}
+
+// matchFunctions pairs the functions of a base file and a new file whose
+// mangled names differ, trying the strategies in order. It returns new
+// mangled name -> base mangled name and the renames found.
+func matchFunctions(baseFile, newFile *File, strategies []string) (map[string]string, []FunctionRename) {
+	matches := make(map[string]string)
+	renames := make([]FunctionRename, 0)
+
+	baseIDs := functionIdentities(baseFile)
+	newIDs := functionIdentities(newFile)
+
+	baseNames := make(map[string]bool)
+	for _, id := range baseIDs {
+		baseNames[id.Name] = true
+	}
+	newNames := make(map[string]bool)
+	for _, id := range newIDs {
+		newNames[id.Name] = true
+	}
+
+	// Functions whose mangled name exists on both sides are already paired
+	unmatchedBase := make([]*functionIdentity, 0)
+	for _, id := range baseIDs {
+		if !newNames[id.Name] {
+			unmatchedBase = append(unmatchedBase, id)
+		}
+	}
+	unmatchedNew := make([]*functionIdentity, 0)
+	for _, id := range newIDs {
+		if !baseNames[id.Name] {
+			unmatchedNew = append(unmatchedNew, id)
+		}
+	}
+
+	record := func(base, newID *functionIdentity, strategy string) {
+		matches[newID.Name] = base.Name
+		renames = append(renames, FunctionRename{
+			File:             newFile.FilePath,
+			OldName:          base.Name,
+			OldDemangledName: base.DemangledName,
+			NewName:          newID.Name,
+			NewDemangledName: newID.DemangledName,
+			Strategy:         strategy,
+		})
+	}
+
+	for _, strategy := range strategies {
+		if len(unmatchedBase) == 0 || len(unmatchedNew) == 0 {
+			break
+		}
+
+		switch strategy {
+		case FunctionMatchBaseName:
+			unmatchedBase, unmatchedNew = pairByKey(unmatchedBase, unmatchedNew, strategy, functionBaseName, record)
+		case FunctionMatchPosition:
+			unmatchedBase, unmatchedNew = pairByKey(unmatchedBase, unmatchedNew, strategy, functionStartPos, record)
+			unmatchedBase, unmatchedNew = pairByKey(unmatchedBase, unmatchedNew, strategy, functionLineNo, record)
+		case FunctionMatchFuzzy:
+			unmatchedBase, unmatchedNew = pairBySimilarity(unmatchedBase, unmatchedNew, record)
+		}
+	}
+
+	return matches, renames
+}
+
+// pairByKey pairs functions whose key is unique on both sides and equal.
+// Functions with an empty key are never paired. The unpaired remainder of
+// both sides is returned.
+func pairByKey(base, newIDs []*functionIdentity, strategy string, key func(*functionIdentity) string,
+	record func(base, newID *functionIdentity, strategy string)) ([]*functionIdentity, []*functionIdentity) {
+
+	baseByKey := make(map[string][]*functionIdentity)
+	for _, id := range base {
+		if k := key(id); k != "" {
+			baseByKey[k] = append(baseByKey[k], id)
+		}
+	}
+	newByKey := make(map[string][]*functionIdentity)
+	for _, id := range newIDs {
+		if k := key(id); k != "" {
+			newByKey[k] = append(newByKey[k], id)
+		}
+	}
+
+	paired := make(map[*functionIdentity]bool)
+	for _, id := range newIDs {
+		k := key(id)
+		if k == "" || len(newByKey[k]) != 1 || len(baseByKey[k]) != 1 {
+			continue
+		}
+		record(baseByKey[k][0], id, strategy)
+		paired[baseByKey[k][0]] = true
+		paired[id] = true
+	}
+
+	return withoutPaired(base, paired), withoutPaired(newIDs, paired)
+}
+
+// pairBySimilarity greedily pairs the most similar functions whose score
+// reaches fuzzyMatchThreshold
+func pairBySimilarity(base, newIDs []*functionIdentity,
+	record func(base, newID *functionIdentity, strategy string)) ([]*functionIdentity, []*functionIdentity) {
+
+	type candidate struct {
+		base, newID *functionIdentity
+		score       float64
+	}
+	candidates := make([]candidate, 0)
+	for _, b := range base {
+		for _, n := range newIDs {
+			if score := functionSimilarity(b, n); score >= fuzzyMatchThreshold {
+				candidates = append(candidates, candidate{base: b, newID: n, score: score})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	paired := make(map[*functionIdentity]bool)
+	for _, c := range candidates {
+		if paired[c.base] || paired[c.newID] {
+			continue
+		}
+		record(c.base, c.newID, FunctionMatchFuzzy)
+		paired[c.base] = true
+		paired[c.newID] = true
+	}
+
+	return withoutPaired(base, paired), withoutPaired(newIDs, paired)
+}
+
+// withoutPaired returns the functions that were not paired
+func withoutPaired(ids []*functionIdentity, paired map[*functionIdentity]bool) []*functionIdentity {
+	result := make([]*functionIdentity, 0, len(ids))
+	for _, id := range ids {
+		if !paired[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// functionBaseName returns the qualified name of a function without its
+// parameter list, e.g. "ns::Foo::bar" for "ns::Foo::bar(int) const"
+func functionBaseName(id *functionIdentity) string {
+	return strings.Join(parseCppName(id.DemangledName).Components, "::")
+}
+
+// functionStartPos returns the start position of a function, if known
+func functionStartPos(id *functionIdentity) string {
+	return id.Pos
+}
+
+// functionLineNo returns the declaration line of a function, if known
+func functionLineNo(id *functionIdentity) string {
+	if id.LineNo <= 0 {
+		return ""
+	}
+	return strconv.Itoa(id.LineNo)
+}
+
+// functionSimilarity scores two functions between 0 and 1 as the average of
+// the similarity of their base names and the overlap of their line numbers
+func functionSimilarity(a, b *functionIdentity) float64 {
+	nameScore := stringSimilarity(functionBaseName(a), functionBaseName(b))
+
+	lineScore := 0.0
+	union := len(a.Lines)
+	shared := 0
+	for line := range b.Lines {
+		if a.Lines[line] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	if union > 0 {
+		lineScore = float64(shared) / float64(union)
+	}
+
+	return (nameScore + lineScore) / 2
+}
+
+// stringSimilarity returns 1 minus the edit distance of two strings relative
+// to the longer one
+func stringSimilarity(a, b string) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1.0
+	}
+	return 1.0 - float64(editDistance(a, b))/float64(longest)
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,238 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// signatureChangeReports returns a base report with foo(int) and a new report
+// where foo gained a parameter and bar was renamed to baz
+func signatureChangeReports() (*GcovrReport, *GcovrReport) {
+	base := &GcovrReport{Files: []File{{
+		FilePath: "test.cpp",
+		Lines: []Line{
+			{LineNumber: 1, FunctionName: "_Z3fooi", Count: 1},
+			{LineNumber: 2, FunctionName: "_Z3fooi", Count: 1},
+			{LineNumber: 3, FunctionName: "_Z3fooi", Count: 0},
+			{LineNumber: 10, FunctionName: "_Z11computeSumv", Count: 1},
+			{LineNumber: 11, FunctionName: "_Z11computeSumv", Count: 0},
+		},
+		Functions: []Function{
+			{Name: "_Z3fooi", DemangledName: "foo(int)", LineNo: 1, Pos: []string{"1:6", "4:1"}},
+			{Name: "_Z11computeSumv", DemangledName: "computeSum()", LineNo: 10, Pos: []string{"10:6", "12:1"}},
+		},
+	}}}
+	newReport := &GcovrReport{Files: []File{{
+		FilePath: "test.cpp",
+		Lines: []Line{
+			{LineNumber: 1, FunctionName: "_Z3fooib", Count: 1},
+			{LineNumber: 2, FunctionName: "_Z3fooib", Count: 1},
+			{LineNumber: 3, FunctionName: "_Z3fooib", Count: 1},
+			{LineNumber: 10, FunctionName: "_Z13computeTotalv", Count: 1},
+			{LineNumber: 11, FunctionName: "_Z13computeTotalv", Count: 0},
+		},
+		Functions: []Function{
+			{Name: "_Z3fooib", DemangledName: "foo(int, bool)", LineNo: 1, Pos: []string{"1:6", "4:1"}},
+			{Name: "_Z13computeTotalv", DemangledName: "computeTotal()", LineNo: 10, Pos: []string{"10:6", "12:1"}},
+		},
+	}}}
+	return base, newReport
+}
+
+func TestComputeCoverageIncreaseWithOptions_MangledOnly(t *testing.T) {
+	base, newReport := signatureChangeReports()
+
+	report, err := ComputeCoverageIncreaseWithOptions(base, newReport, DiffOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Both functions look new, so all their covered lines count as increases
+	if len(report.Increases) != 2 {
+		t.Fatalf("Expected 2 increases, got %d", len(report.Increases))
+	}
+	if len(report.Renames) != 0 {
+		t.Errorf("Expected no renames, got %+v", report.Renames)
+	}
+}
+
+func TestComputeCoverageIncreaseWithOptions_BaseName(t *testing.T) {
+	base, newReport := signatureChangeReports()
+
+	report, err := ComputeCoverageIncreaseWithOptions(base, newReport, DiffOptions{
+		FunctionMatch: []string{FunctionMatchBaseName},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(report.Renames) != 1 {
+		t.Fatalf("Expected 1 rename, got %+v", report.Renames)
+	}
+	expected := FunctionRename{
+		File:             "test.cpp",
+		OldName:          "_Z3fooi",
+		OldDemangledName: "foo(int)",
+		NewName:          "_Z3fooib",
+		NewDemangledName: "foo(int, bool)",
+		Strategy:         FunctionMatchBaseName,
+	}
+	if report.Renames[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, report.Renames[0])
+	}
+
+	for _, inc := range report.Increases {
+		if inc.FunctionName != "_Z3fooib" {
+			continue
+		}
+		if inc.LinesIncreased != 1 || inc.OldCoveredLines != 2 || inc.PreviousName != "foo(int)" {
+			t.Errorf("Expected only line 3 to increase against foo(int), got %+v", inc)
+		}
+		return
+	}
+	t.Error("Expected an increase for foo(int, bool)")
+}
+
+func TestComputeCoverageIncreaseWithOptions_Position(t *testing.T) {
+	base, newReport := signatureChangeReports()
+
+	report, err := ComputeCoverageIncreaseWithOptions(base, newReport, DiffOptions{
+		FunctionMatch: []string{FunctionMatchPosition},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(report.Renames) != 2 {
+		t.Fatalf("Expected 2 renames, got %+v", report.Renames)
+	}
+	// computeTotal() covers the same line as computeSum() did
+	if len(report.Increases) != 1 || report.Increases[0].FunctionName != "_Z3fooib" {
+		t.Errorf("Expected only foo(int, bool) to increase, got %+v", report.Increases)
+	}
+}
+
+func TestComputeCoverageIncreaseWithOptions_Fuzzy(t *testing.T) {
+	base, newReport := signatureChangeReports()
+	// Without positions, fuzzy matching relies on names and line numbers
+	for _, report := range []*GcovrReport{base, newReport} {
+		for i := range report.Files[0].Functions {
+			report.Files[0].Functions[i].LineNo = 0
+			report.Files[0].Functions[i].Pos = nil
+		}
+	}
+
+	report, err := ComputeCoverageIncreaseWithOptions(base, newReport, DiffOptions{
+		FunctionMatch: []string{FunctionMatchBaseName, FunctionMatchFuzzy},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	strategies := make(map[string]string)
+	for _, rename := range report.Renames {
+		strategies[rename.NewDemangledName] = rename.Strategy
+	}
+	expected := map[string]string{
+		"foo(int, bool)": FunctionMatchBaseName,
+		"computeTotal()": FunctionMatchFuzzy,
+	}
+	if !reflect.DeepEqual(strategies, expected) {
+		t.Errorf("Expected %v, got %v", expected, strategies)
+	}
+}
+
+func TestComputeCoverageIncreaseWithOptions_UnknownStrategy(t *testing.T) {
+	base, newReport := signatureChangeReports()
+
+	_, err := ComputeCoverageIncreaseWithOptions(base, newReport, DiffOptions{FunctionMatch: []string{"nearest"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown function match strategy "nearest"`) {
+		t.Errorf("Expected unknown strategy error, got %v", err)
+	}
+}
+
+func TestMatchFunctions_AmbiguousBaseName(t *testing.T) {
+	// Two overloads changing at once cannot be told apart by base name
+	base := &File{FilePath: "test.cpp", Functions: []Function{
+		{Name: "_Z3fooi", DemangledName: "foo(int)"},
+		{Name: "_Z3food", DemangledName: "foo(double)"},
+	}}
+	newFile := &File{FilePath: "test.cpp", Functions: []Function{
+		{Name: "_Z3fool", DemangledName: "foo(long)"},
+		{Name: "_Z3foof", DemangledName: "foo(float)"},
+	}}
+
+	matches, renames := matchFunctions(base, newFile, []string{FunctionMatchBaseName})
+	if len(matches) != 0 || len(renames) != 0 {
+		t.Errorf("Expected no matches, got %v", matches)
+	}
+}
+
+func TestFormatReport_Renames(t *testing.T) {
+	report := &CoverageIncreaseReport{
+		Increases: []FunctionCoverageIncrease{{
+			File:                 "test.cpp",
+			FunctionName:         "_Z3fooib",
+			DemangledName:        "foo(int, bool)",
+			LinesIncreased:       1,
+			TotalLines:           3,
+			IncreasedLineNumbers: []int{3},
+			OldCoveredLines:      2,
+			NewCoveredLines:      3,
+			PreviousName:         "foo(int)",
+		}},
+		Renames: []FunctionRename{{
+			File:             "test.cpp",
+			OldDemangledName: "foo(int)",
+			NewDemangledName: "foo(int, bool)",
+			Strategy:         FunctionMatchBaseName,
+		}},
+	}
+
+	output := FormatReport(report)
+	for _, want := range []string{
+		"   Previously: foo(int)\n",
+		"Matched 1 renamed or re-signatured function(s):\n",
+		"   test.cpp: foo(int) -> foo(int, bool) [base-name]\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"computeSum", "computeTotal", 5},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q): expected %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
+
+func TestFunctionBaseName(t *testing.T) {
+	tests := []struct {
+		demangledName string
+		expected      string
+	}{
+		{"ns::Foo::bar(int)", "ns::Foo::bar"},
+		{"ns::Foo::bar(int) [clone .cold]", "ns::Foo::bar"},
+		{"foo(int, bool) [clone .isra.0] [clone .constprop.0]", "foo"},
+	}
+
+	for _, tt := range tests {
+		if got := functionBaseName(&functionIdentity{DemangledName: tt.demangledName}); got != tt.expected {
+			t.Errorf("functionBaseName(%q): expected %q, got %q", tt.demangledName, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package gcovr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// gcovJSON is the intermediate format written by "gcov --json-format"
+type gcovJSON struct {
+	FormatVersion    string     `json:"format_version"`
+	WorkingDirectory string     `json:"current_working_directory"`
+	Files            []gcovFile `json:"files"`
+}
+
+type gcovFile struct {
+	File      string         `json:"file"`
+	Lines     []gcovLine     `json:"lines"`
+	Functions []gcovFunction `json:"functions"`
+}
+
+type gcovLine struct {
+	LineNumber   int          `json:"line_number"`
+	FunctionName string       `json:"function_name"`
+	Count        int          `json:"count"`
+	Branches     []gcovBranch `json:"branches"`
+}
+
+type gcovBranch struct {
+	Count       int  `json:"count"`
+	Fallthrough bool `json:"fallthrough"`
+	Throw       bool `json:"throw"`
+}
+
+type gcovFunction struct {
+	Name           string `json:"name"`
+	DemangledName  string `json:"demangled_name"`
+	StartLine      int    `json:"start_line"`
+	StartColumn    int    `json:"start_column"`
+	EndLine        int    `json:"end_line"`
+	EndColumn      int    `json:"end_column"`
+	Blocks         int    `json:"blocks"`
+	BlocksExecuted int    `json:"blocks_executed"`
+	ExecutionCount int    `json:"execution_count"`
+}
+
+// parseGcovJSON decodes a stream of gcov JSON documents and merges them into
+// report. Source paths are resolved against the compilation directory and
+// made relative to root when they lie below it, as gcovr does.
+func parseGcovJSON(r io.Reader, root string, report *GcovrReport) error {
+	fileIndex := make(map[string]int)
+	for i, file := range report.Files {
+		fileIndex[file.FilePath] = i
+	}
+
+	decoder := json.NewDecoder(r)
+	for {
+		var doc gcovJSON
+		if err := decoder.Decode(&doc); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		for _, gf := range doc.Files {
+			file := convertGcovFile(gf, gcovSourcePath(gf.File, doc.WorkingDirectory, root))
+
+			if idx, ok := fileIndex[file.FilePath]; ok {
+				mergeFileCoverage(&report.Files[idx], file)
+				continue
+			}
+			fileIndex[file.FilePath] = len(report.Files)
+			report.Files = append(report.Files, file)
+		}
+	}
+}
+
+// gcovSourcePath turns a gcov source path into a report file path
+func gcovSourcePath(path, workingDir, root string) string {
+	if !filepath.IsAbs(path) && workingDir != "" {
+		path = filepath.Join(workingDir, path)
+	}
+	path = filepath.Clean(path)
+
+	if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+// convertGcovFile converts one gcov file record into the gcovr layout
+func convertGcovFile(gf gcovFile, filePath string) File {
+	file := File{
+		FilePath:  filePath,
+		Lines:     make([]Line, 0, len(gf.Lines)),
+		Functions: make([]Function, 0, len(gf.Functions)),
+	}
+
+	for _, gl := range gf.Lines {
+		line := Line{
+			LineNumber:   gl.LineNumber,
+			FunctionName: gl.FunctionName,
+			Count:        gl.Count,
+			Branches:     make([]Branch, 0, len(gl.Branches)),
+		}
+		for _, gb := range gl.Branches {
+			line.Branches = append(line.Branches, Branch{
+				Count:       gb.Count,
+				Fallthrough: gb.Fallthrough,
+				Throw:       gb.Throw,
+			})
+		}
+		file.Lines = append(file.Lines, line)
+	}
+
+	for _, gfn := range gf.Functions {
+		blocksPercent := 0.0
+		if gfn.Blocks > 0 {
+			blocksPercent = float64(gfn.BlocksExecuted) * 100.0 / float64(gfn.Blocks)
+		}
+		file.Functions = append(file.Functions, Function{
+			Name:           gfn.Name,
+			DemangledName:  functionDisplayName(gfn.Name, gfn.DemangledName),
+			LineNo:         gfn.StartLine,
+			ExecutionCount: gfn.ExecutionCount,
+			BlocksPercent:  blocksPercent,
+			Pos: []string{
+				fmt.Sprintf("%d:%d", gfn.StartLine, gfn.StartColumn),
+				fmt.Sprintf("%d:%d", gfn.EndLine, gfn.EndColumn),
+			},
+		})
+	}
+
+	return file
+}
+
+// mergeFileCoverage adds the counts of src into dst. Headers compiled into
+// several translation units show up once per unit and are summed line by line.
+func mergeFileCoverage(dst *File, src File) {
+	type lineKey struct {
+		number   int
+		function string
+	}
+
+	lineIndex := make(map[lineKey]int)
+	for i, line := range dst.Lines {
+		lineIndex[lineKey{line.LineNumber, line.FunctionName}] = i
+	}
+	for _, line := range src.Lines {
+		idx, ok := lineIndex[lineKey{line.LineNumber, line.FunctionName}]
+		if !ok {
+			lineIndex[lineKey{line.LineNumber, line.FunctionName}] = len(dst.Lines)
+			dst.Lines = append(dst.Lines, line)
+			continue
+		}
+
+		existing := &dst.Lines[idx]
+		existing.Count += line.Count
+		if len(existing.Branches) == len(line.Branches) {
+			for j := range line.Branches {
+				existing.Branches[j].Count += line.Branches[j].Count
+			}
+		}
+	}
+	sort.SliceStable(dst.Lines, func(i, j int) bool {
+		return dst.Lines[i].LineNumber < dst.Lines[j].LineNumber
+	})
+
+	funcIndex := make(map[string]int)
+	for i, fn := range dst.Functions {
+		funcIndex[fn.Name] = i
+	}
+	for _, fn := range src.Functions {
+		idx, ok := funcIndex[fn.Name]
+		if !ok {
+			funcIndex[fn.Name] = len(dst.Functions)
+			dst.Functions = append(dst.Functions, fn)
+			continue
+		}
+
+		existing := &dst.Functions[idx]
+		existing.ExecutionCount += fn.ExecutionCount
+		if fn.BlocksPercent > existing.BlocksPercent {
+			existing.BlocksPercent = fn.BlocksPercent
+		}
+	}
+}
+
+// sortReportFiles orders report files by path
+func sortReportFiles(report *GcovrReport) {
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].FilePath < report.Files[j].FilePath
+	})
+}
@@ -0,0 +1,62 @@
+package gcovr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGcovJSON(t *testing.T) {
+	// Two translation units including the same header, as gcov prints them
+	input := `{"format_version": "1", "current_working_directory": "/build/gcc", "files": [
+  {"file": "../../src/demo.cc", "lines": [
+    {"line_number": 3, "function_name": "_Z1fv", "count": 1, "branches": []},
+    {"line_number": 4, "function_name": "_Z1gv", "count": 0, "branches": [{"count": 0, "fallthrough": true, "throw": false}, {"count": 0, "fallthrough": false, "throw": false}]}
+  ], "functions": [
+    {"name": "_Z1fv", "demangled_name": "f()", "start_line": 3, "start_column": 5, "end_line": 3, "end_column": 21, "blocks": 2, "blocks_executed": 2, "execution_count": 1},
+    {"name": "_Z1gv", "start_line": 4, "start_column": 5, "end_line": 4, "end_column": 21, "blocks": 2, "blocks_executed": 0, "execution_count": 0}
+  ]},
+  {"file": "common.h", "lines": [{"line_number": 1, "function_name": "_Z1hv", "count": 2, "branches": []}], "functions": []}
+]}
+{"format_version": "1", "current_working_directory": "/build/gcc", "files": [
+  {"file": "/build/gcc/common.h", "lines": [{"line_number": 1, "function_name": "_Z1hv", "count": 3, "branches": []}], "functions": []}
+]}
+`
+
+	report := &GcovrReport{Files: make([]File, 0)}
+	if err := parseGcovJSON(strings.NewReader(input), "/build", report); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(report.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(report.Files))
+	}
+
+	demo := report.Files[0]
+	if demo.FilePath != "/src/demo.cc" {
+		t.Errorf("Expected a path outside the root to stay absolute, got %s", demo.FilePath)
+	}
+	if len(demo.Lines[1].Branches) != 2 {
+		t.Errorf("Expected 2 branches on line 4, got %d", len(demo.Lines[1].Branches))
+	}
+	if demo.Functions[1].DemangledName != "g()" {
+		t.Errorf("Expected missing demangled name to be filled in, got %q", demo.Functions[1].DemangledName)
+	}
+	if demo.Functions[0].BlocksPercent != 100 || demo.Functions[0].Pos[1] != "3:21" {
+		t.Errorf("Unexpected function conversion: %+v", demo.Functions[0])
+	}
+
+	header := report.Files[1]
+	if header.FilePath != "gcc/common.h" {
+		t.Errorf("Expected a path relative to the root, got %s", header.FilePath)
+	}
+	if len(header.Lines) != 1 || header.Lines[0].Count != 5 {
+		t.Errorf("Expected header counts to be summed to 5, got %+v", header.Lines)
+	}
+}
+
+func TestParseGcovJSON_Invalid(t *testing.T) {
+	report := &GcovrReport{}
+	if err := parseGcovJSON(strings.NewReader(`{"files": [`), "/", report); err == nil {
+		t.Error("Expected error for truncated JSON")
+	}
+}
@@ -0,0 +1,389 @@
+package gcovr
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// historyFileName is the append-only file of a history store
+const historyFileName = "history.jsonl"
+
+// CoverageCount is a covered/total pair
+type CoverageCount struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the covered percentage; nothing to cover counts as 100%
+func (c CoverageCount) Percent() float64 {
+	if c.Total == 0 {
+		return 100.0
+	}
+	return float64(c.Covered) * 100.0 / float64(c.Total)
+}
+
+// FunctionSnapshot is the coverage of one function at one point in time
+type FunctionSnapshot struct {
+	File          string        `json:"file"`
+	Name          string        `json:"name"` // Mangled name
+	DemangledName string        `json:"demangled_name"`
+	Lines         CoverageCount `json:"lines"`
+	Branches      CoverageCount `json:"branches"`
+}
+
+// HistoryEntry is one recorded report summary
+type HistoryEntry struct {
+	Time      time.Time          `json:"time"`
+	Label     string             `json:"label,omitempty"` // e.g. a commit or campaign round
+	Lines     CoverageCount      `json:"lines"`
+	Branches  CoverageCount      `json:"branches"`
+	Functions []FunctionSnapshot `json:"functions"`
+	// New lists the lines and branches covered for the first time in the history
+	New []FileContribution `json:"new,omitempty"`
+}
+
+// HistoryStore is an append-only record of report summaries kept in a directory
+type HistoryStore struct {
+	dir  string
+	path string
+}
+
+// OpenHistory opens the history store in dir. A missing directory is an
+// empty store; it is only created when the first entry is recorded.
+func OpenHistory(dir string) (*HistoryStore, error) {
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return nil, fmt.Errorf("history directory %s is not a directory", dir)
+	}
+	return &HistoryStore{dir: dir, path: filepath.Join(dir, historyFileName)}, nil
+}
+
+// Entries returns the recorded entries in the order they were recorded
+func (h *HistoryStore) Entries() ([]HistoryEntry, error) {
+	entries := make([]HistoryEntry, 0)
+
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse history %s line %d: %w", h.path, lineNum, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Record summarizes a report and appends it to the history. Entries must be
+// recorded in time order, since the lines an entry covers for the first time
+// are determined against everything recorded before it.
+func (h *HistoryStore) Record(report *GcovrReport, at time.Time, label string) (*HistoryEntry, error) {
+	entries, err := h.Entries()
+	if err != nil {
+		return nil, err
+	}
+	if n := len(entries); n > 0 && at.Before(entries[n-1].Time) {
+		return nil, fmt.Errorf("entry time %s is before the last recorded entry at %s",
+			at.Format(time.RFC3339), entries[n-1].Time.Format(time.RFC3339))
+	}
+
+	acc := NewAccumulator()
+	for _, entry := range entries {
+		for _, contribution := range entry.New {
+			acc.addContribution(contribution)
+		}
+	}
+	added, err := acc.Add(report)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := summarizeForHistory(report)
+	entry.Time = at
+	entry.Label = label
+	entry.New = added.Files
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode history entry: %w", err)
+	}
+
+	if err := os.MkdirAll(h.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory %s: %w", h.dir, err)
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to append to history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to append to history: %w", err)
+	}
+
+	return entry, nil
+}
+
+// summarizeForHistory computes the global and per-function coverage of a report
+func summarizeForHistory(report *GcovrReport) *HistoryEntry {
+	entry := &HistoryEntry{Functions: make([]FunctionSnapshot, 0)}
+
+	for _, file := range report.Files {
+		covered, total := countLineCoverage(file.Lines)
+		entry.Lines.Covered += covered
+		entry.Lines.Total += total
+		covered, total = countBranchCoverage(file.Lines)
+		entry.Branches.Covered += covered
+		entry.Branches.Total += total
+
+		funcLines := groupLinesByFunction(file.Lines)
+		for _, fn := range file.Functions {
+			snapshot := FunctionSnapshot{
+				File:          file.FilePath,
+				Name:          fn.Name,
+				DemangledName: functionDisplayName(fn.Name, fn.DemangledName),
+			}
+			snapshot.Lines.Covered, snapshot.Lines.Total = countLineCoverage(funcLines[fn.Name])
+			snapshot.Branches.Covered, snapshot.Branches.Total = countBranchCoverage(funcLines[fn.Name])
+			entry.Functions = append(entry.Functions, snapshot)
+		}
+	}
+
+	return entry
+}
+
+// TrendPoint is the global coverage of one history entry
+type TrendPoint struct {
+	Time        time.Time
+	Label       string
+	Lines       CoverageCount
+	Branches    CoverageCount
+	NewLines    int // Lines covered for the first time
+	NewBranches int // Branches covered for the first time
+}
+
+// FunctionTrend is the evolution of one function's line coverage
+type FunctionTrend struct {
+	File           string
+	FunctionName   string // Mangled name
+	DemangledName  string
+	First          CoverageCount // Line coverage in the first entry listing the function
+	Last           CoverageCount // Line coverage in the last entry listing the function
+	FirstCoveredAt time.Time     // Zero if no line was ever covered
+	LastImprovedAt time.Time     // Last time its covered line count reached a new high
+	Plateaued      bool          // Not fully covered and not improved within the plateau window
+}
+
+// LineFirstCovered records when a line was first covered
+type LineFirstCovered struct {
+	File  string
+	Line  int
+	Time  time.Time
+	Label string
+}
+
+// HistoryReport describes coverage trends over a history
+type HistoryReport struct {
+	Points         []TrendPoint
+	Functions      []FunctionTrend
+	FirstCovered   []LineFirstCovered // Ordered by file and line
+	LastImprovedAt time.Time          // Last entry that covered anything new
+	StaleEntries   int                // Entries since then
+	PlateauWindow  int
+}
+
+// Plateaued reports whether the last PlateauWindow entries covered nothing new
+func (r *HistoryReport) Plateaued() bool {
+	return r.PlateauWindow > 0 && len(r.Points) > 0 && r.StaleEntries >= r.PlateauWindow
+}
+
+// AnalyzeHistory computes global and per-function trends from history
+// entries. Coverage has plateaued once plateauWindow consecutive entries
+// cover nothing new.
+func AnalyzeHistory(entries []HistoryEntry, plateauWindow int) *HistoryReport {
+	result := &HistoryReport{
+		Points:        make([]TrendPoint, 0, len(entries)),
+		Functions:     make([]FunctionTrend, 0),
+		FirstCovered:  make([]LineFirstCovered, 0),
+		PlateauWindow: plateauWindow,
+	}
+
+	type trendState struct {
+		trend        FunctionTrend
+		best         int
+		staleEntries int
+	}
+	trends := make(map[string]*trendState)
+	order := make([]string, 0)
+
+	for _, entry := range entries {
+		point := TrendPoint{Time: entry.Time, Label: entry.Label, Lines: entry.Lines, Branches: entry.Branches}
+		for _, contribution := range entry.New {
+			point.NewLines += len(contribution.Lines)
+			point.NewBranches += len(contribution.Branches)
+			for _, line := range contribution.Lines {
+				result.FirstCovered = append(result.FirstCovered, LineFirstCovered{
+					File: contribution.FilePath, Line: line, Time: entry.Time, Label: entry.Label,
+				})
+			}
+		}
+		result.Points = append(result.Points, point)
+
+		if point.NewLines > 0 || point.NewBranches > 0 {
+			result.LastImprovedAt = entry.Time
+			result.StaleEntries = 0
+		} else {
+			result.StaleEntries++
+		}
+
+		listed := make(map[string]bool)
+		for _, fn := range entry.Functions {
+			key := fn.File + "\x00" + fn.Name
+			listed[key] = true
+			state, ok := trends[key]
+			if !ok {
+				state = &trendState{trend: FunctionTrend{
+					File:          fn.File,
+					FunctionName:  fn.Name,
+					DemangledName: fn.DemangledName,
+					First:         fn.Lines,
+				}}
+				trends[key] = state
+				order = append(order, key)
+			}
+
+			state.trend.Last = fn.Lines
+			if fn.Lines.Covered > 0 && state.trend.FirstCoveredAt.IsZero() {
+				state.trend.FirstCoveredAt = entry.Time
+			}
+			if fn.Lines.Covered > state.best {
+				state.best = fn.Lines.Covered
+				state.trend.LastImprovedAt = entry.Time
+				state.staleEntries = 0
+			} else {
+				state.staleEntries++
+			}
+		}
+		for _, key := range order {
+			if !listed[key] {
+				trends[key].staleEntries++
+			}
+		}
+	}
+
+	for _, key := range order {
+		state := trends[key]
+		last := state.trend.Last
+		state.trend.Plateaued = plateauWindow > 0 && last.Covered < last.Total && state.staleEntries >= plateauWindow
+		result.Functions = append(result.Functions, state.trend)
+	}
+	sort.SliceStable(result.Functions, func(i, j int) bool {
+		if result.Functions[i].File != result.Functions[j].File {
+			return result.Functions[i].File < result.Functions[j].File
+		}
+		return result.Functions[i].DemangledName < result.Functions[j].DemangledName
+	})
+	sort.SliceStable(result.FirstCovered, func(i, j int) bool {
+		if result.FirstCovered[i].File != result.FirstCovered[j].File {
+			return result.FirstCovered[i].File < result.FirstCovered[j].File
+		}
+		return result.FirstCovered[i].Line < result.FirstCovered[j].Line
+	})
+
+	return result
+}
+
+// historyTimeFormat is used for times in history output
+const historyTimeFormat = "2006-01-02 15:04:05"
+
+// FormatHistoryReport formats the history trends as a human-readable string.
+// With showLines, the time each line was first covered is listed as well.
+func FormatHistoryReport(report *HistoryReport, showLines bool) string {
+	result := fmt.Sprintf("Coverage History\n")
+	result += fmt.Sprintf("================\n\n")
+
+	if len(report.Points) == 0 {
+		result += "No history recorded.\n"
+		return result
+	}
+
+	result += fmt.Sprintf("Global Coverage (%d entries):\n", len(report.Points))
+	for _, p := range report.Points {
+		result += fmt.Sprintf("   %s", p.Time.Local().Format(historyTimeFormat))
+		if p.Label != "" {
+			result += fmt.Sprintf("  %s", p.Label)
+		}
+		result += fmt.Sprintf("  lines %d/%d (%.1f%%), branches %d/%d (%.1f%%)",
+			p.Lines.Covered, p.Lines.Total, p.Lines.Percent(),
+			p.Branches.Covered, p.Branches.Total, p.Branches.Percent())
+		if p.NewLines > 0 || p.NewBranches > 0 {
+			result += fmt.Sprintf("  +%d new line(s), +%d new branch(es)", p.NewLines, p.NewBranches)
+		}
+		result += "\n"
+	}
+	result += "\n"
+
+	if report.Plateaued() {
+		result += fmt.Sprintf("Plateau: no new coverage in the last %d entries", report.StaleEntries)
+		if !report.LastImprovedAt.IsZero() {
+			result += fmt.Sprintf(" (last at %s)", report.LastImprovedAt.Local().Format(historyTimeFormat))
+		}
+		result += "\n\n"
+	}
+
+	if len(report.Functions) > 0 {
+		result += fmt.Sprintf("Functions (%d):\n", len(report.Functions))
+		for _, fn := range report.Functions {
+			result += fmt.Sprintf("   %s: %s: lines %d/%d -> %d/%d",
+				fn.File, fn.DemangledName, fn.First.Covered, fn.First.Total, fn.Last.Covered, fn.Last.Total)
+			if fn.FirstCoveredAt.IsZero() {
+				result += ", never covered"
+			} else {
+				result += fmt.Sprintf(", first covered %s, last improved %s",
+					fn.FirstCoveredAt.Local().Format(historyTimeFormat), fn.LastImprovedAt.Local().Format(historyTimeFormat))
+			}
+			if fn.Plateaued {
+				result += " [plateau]"
+			}
+			result += "\n"
+		}
+		result += "\n"
+	}
+
+	if showLines {
+		result += fmt.Sprintf("First Covered Lines (%d):\n", len(report.FirstCovered))
+		for _, l := range report.FirstCovered {
+			result += fmt.Sprintf("   %s:%d  %s", l.File, l.Line, l.Time.Local().Format(historyTimeFormat))
+			if l.Label != "" {
+				result += fmt.Sprintf("  %s", l.Label)
+			}
+			result += "\n"
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,162 @@
+package gcovr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// historyTime returns a fixed time offset by hours
+func historyTime(hours int) time.Time {
+	return time.Date(2025, 11, 20, 10, 0, 0, 0, time.UTC).Add(time.Duration(hours) * time.Hour)
+}
+
+// historyReport builds a report of demo.cc with function f on lines 1-4 and
+// g on lines 6-7, of which the given lines are covered
+func historyReport(covered ...int) *GcovrReport {
+	file := File{
+		FilePath: "demo.cc",
+		Functions: []Function{
+			{Name: "_Z1fv", DemangledName: "f()", LineNo: 1},
+			{Name: "_Z1gv", DemangledName: "g()", LineNo: 6},
+		},
+	}
+	for l := 1; l <= 7; l++ {
+		line := Line{LineNumber: l, FunctionName: "_Z1fv"}
+		if l >= 6 {
+			line.FunctionName = "_Z1gv"
+		}
+		for _, c := range covered {
+			if c == l {
+				line.Count = 1
+			}
+		}
+		file.Lines = append(file.Lines, line)
+	}
+	return &GcovrReport{Files: []File{file}}
+}
+
+func TestHistoryStore_Record(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+	store, err := OpenHistory(dir)
+	if err != nil {
+		t.Fatalf("Failed to open history: %v", err)
+	}
+
+	entries, err := store.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty history, got %v, %v", entries, err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("Expected reading the history not to create %s, got %v", dir, err)
+	}
+
+	first, err := store.Record(historyReport(1, 2), historyTime(0), "round-1")
+	if err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+	if first.Lines != (CoverageCount{Covered: 2, Total: 7}) || len(first.Functions) != 2 {
+		t.Errorf("Unexpected first entry: %+v", first)
+	}
+
+	// Reopening the store sees the recorded entry
+	store, _ = OpenHistory(dir)
+	second, err := store.Record(historyReport(2, 3, 6), historyTime(1), "round-2")
+	if err != nil {
+		t.Fatalf("Failed to record: %v", err)
+	}
+	if len(second.New) != 1 || len(second.New[0].Lines) != 2 {
+		t.Errorf("Expected lines 3 and 6 to be new, got %+v", second.New)
+	}
+
+	if _, err := store.Record(historyReport(1), historyTime(-1), ""); err == nil {
+		t.Error("Expected an error for an entry older than the last one")
+	}
+
+	entries, err = store.Entries()
+	if err != nil {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Label != "round-2" || !entries[1].Time.Equal(historyTime(1)) {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestHistoryStore_CorruptLine(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, historyFileName), []byte("{\"time\":\"2025-11-20T10:00:00Z\"}\nnot json\n"), 0644)
+
+	store, _ := OpenHistory(dir)
+	_, err := store.Entries()
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected a parse error on line 2, got %v", err)
+	}
+}
+
+func TestAnalyzeHistory(t *testing.T) {
+	store, _ := OpenHistory(t.TempDir())
+	store.Record(historyReport(1), historyTime(0), "r1")
+	store.Record(historyReport(1, 2), historyTime(1), "r2")
+	store.Record(historyReport(1, 2), historyTime(2), "r3")
+	store.Record(historyReport(2), historyTime(3), "r4")
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatalf("Failed to read entries: %v", err)
+	}
+	report := AnalyzeHistory(entries, 2)
+
+	if len(report.Points) != 4 || report.Points[1].NewLines != 1 || report.Points[2].NewLines != 0 {
+		t.Errorf("Unexpected points: %+v", report.Points)
+	}
+	if !report.LastImprovedAt.Equal(historyTime(1)) || report.StaleEntries != 2 || !report.Plateaued() {
+		t.Errorf("Expected a plateau since r2, got last improved %v and %d stale entries",
+			report.LastImprovedAt, report.StaleEntries)
+	}
+
+	if len(report.Functions) != 2 {
+		t.Fatalf("Expected 2 function trends, got %d", len(report.Functions))
+	}
+	f, g := report.Functions[0], report.Functions[1]
+	if f.DemangledName != "f()" || f.First.Covered != 1 || f.Last.Covered != 1 ||
+		!f.FirstCoveredAt.Equal(historyTime(0)) || !f.LastImprovedAt.Equal(historyTime(1)) || !f.Plateaued {
+		t.Errorf("Unexpected trend for f(): %+v", f)
+	}
+	if !g.FirstCoveredAt.IsZero() || !g.Plateaued {
+		t.Errorf("Expected g() to be never covered and plateaued, got %+v", g)
+	}
+
+	if len(report.FirstCovered) != 2 || report.FirstCovered[1].Line != 2 || report.FirstCovered[1].Label != "r2" {
+		t.Errorf("Unexpected first covered lines: %+v", report.FirstCovered)
+	}
+
+	if AnalyzeHistory(entries, 3).Plateaued() || AnalyzeHistory(entries, 0).Plateaued() {
+		t.Error("Expected no plateau with a longer or disabled window")
+	}
+}
+
+func TestFormatHistoryReport(t *testing.T) {
+	if output := FormatHistoryReport(AnalyzeHistory(nil, 5), false); !strings.Contains(output, "No history recorded.") {
+		t.Errorf("Unexpected output for an empty history:\n%s", output)
+	}
+
+	store, _ := OpenHistory(t.TempDir())
+	store.Record(historyReport(1), historyTime(0), "r1")
+	store.Record(historyReport(1), historyTime(1), "r2")
+	entries, _ := store.Entries()
+
+	output := FormatHistoryReport(AnalyzeHistory(entries, 1), true)
+	for _, want := range []string{
+		"Global Coverage (2 entries):",
+		"r1  lines 1/7 (14.3%), branches 0/0 (100.0%)  +1 new line(s), +0 new branch(es)\n",
+		"Plateau: no new coverage in the last 1 entries",
+		"demo.cc: g(): lines 0/2 -> 0/2, never covered [plateau]\n",
+		"First Covered Lines (1):\n   demo.cc:1  ",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package gcovr
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LineRange is an inclusive range of source line numbers
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// Contains reports whether a line number falls inside the range
+func (r LineRange) Contains(line int) bool {
+	return line >= r.Start && line <= r.End
+}
+
+// String formats the range as "start-end", or a single number for one line
+func (r LineRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// CompactLineRanges collapses line numbers into ranges of consecutive lines,
+// e.g. [9 10 11 20] into 9-11 and 20. The input may be unsorted and contain
+// duplicates; it is not modified.
+func CompactLineRanges(lines []int) []LineRange {
+	sorted := append([]int(nil), lines...)
+	sort.Ints(sorted)
+
+	ranges := make([]LineRange, 0)
+	for _, line := range sorted {
+		if n := len(ranges); n > 0 && line <= ranges[n-1].End+1 {
+			if line > ranges[n-1].End {
+				ranges[n-1].End = line
+			}
+			continue
+		}
+		ranges = append(ranges, LineRange{Start: line, End: line})
+	}
+	return ranges
+}
+
+// FormatLineRanges formats ranges as a comma-separated list, e.g. "9-14, 20, 31-40"
+func FormatLineRanges(ranges []LineRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseLineRange parses a line range written as "1200-1350" or "1402"
+func ParseLineRange(spec string) (LineRange, error) {
+	startStr, endStr, isRange := strings.Cut(strings.TrimSpace(spec), "-")
+	if !isRange {
+		endStr = startStr
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(startStr))
+	if err != nil {
+		return LineRange{}, fmt.Errorf("invalid line range %q: bad start line", spec)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endStr))
+	if err != nil {
+		return LineRange{}, fmt.Errorf("invalid line range %q: bad end line", spec)
+	}
+
+	if start < 1 || end < start {
+		return LineRange{}, fmt.Errorf("invalid line range %q: lines must be positive and ascending", spec)
+	}
+
+	return LineRange{Start: start, End: end}, nil
+}
+
+// parseLineRanges parses every line range spec of a filter target
+func parseLineRanges(specs []string) ([]LineRange, error) {
+	ranges := make([]LineRange, 0, len(specs))
+	for _, spec := range specs {
+		r, err := ParseLineRange(spec)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
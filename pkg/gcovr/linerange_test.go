@@ -0,0 +1,71 @@
+package gcovr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected LineRange
+		wantErr  bool
+	}{
+		{spec: "1200-1350", expected: LineRange{Start: 1200, End: 1350}},
+		{spec: "1402", expected: LineRange{Start: 1402, End: 1402}},
+		{spec: " 10 - 20 ", expected: LineRange{Start: 10, End: 20}},
+		{spec: "", wantErr: true},
+		{spec: "abc", wantErr: true},
+		{spec: "10-", wantErr: true},
+		{spec: "20-10", wantErr: true},
+		{spec: "0", wantErr: true},
+		{spec: "-5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseLineRange(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %+v", tt.spec, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLineRange_String(t *testing.T) {
+	if s := (LineRange{Start: 9, End: 14}).String(); s != "9-14" {
+		t.Errorf("Expected 9-14, got %s", s)
+	}
+	if s := (LineRange{Start: 20, End: 20}).String(); s != "20" {
+		t.Errorf("Expected 20, got %s", s)
+	}
+}
+
+func TestCompactLineRanges(t *testing.T) {
+	lines := []int{31, 9, 10, 11, 12, 13, 14, 20, 32, 33, 11}
+	ranges := CompactLineRanges(lines)
+
+	expected := []LineRange{{Start: 9, End: 14}, {Start: 20, End: 20}, {Start: 31, End: 33}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, ranges)
+	}
+	if s := FormatLineRanges(ranges); s != "9-14, 20, 31-33" {
+		t.Errorf("Expected \"9-14, 20, 31-33\", got %q", s)
+	}
+	if lines[0] != 31 {
+		t.Error("Expected the input to be left unsorted")
+	}
+
+	if ranges := CompactLineRanges(nil); len(ranges) != 0 || FormatLineRanges(ranges) != "" {
+		t.Errorf("Expected no ranges, got %+v", ranges)
+	}
+}
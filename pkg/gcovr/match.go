@@ -0,0 +1,157 @@
+package gcovr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchedFunction identifies a report function selected by a filter target
+type MatchedFunction struct {
+	File          string
+	Name          string // Mangled name
+	DemangledName string
+}
+
+// FunctionMatch records which report functions a target function name selected
+type FunctionMatch struct {
+	Name    string // Function name as written in the filter config
+	Matches []MatchedFunction
+}
+
+// RangeMatch records how many report lines fell inside a target line range
+type RangeMatch struct {
+	Range LineRange
+	Lines int
+}
+
+// TargetMatch records which report files and functions a target file resolved to
+type TargetMatch struct {
+	File      string   // File as written in the filter config
+	Files     []string // Report file paths the target matched
+	Functions []FunctionMatch
+	Ranges    []RangeMatch
+}
+
+// FilterMatchReport describes how every filter target resolved against a report
+type FilterMatchReport struct {
+	Targets []TargetMatch
+}
+
+// Dead reports whether the target file matched no report file
+func (t *TargetMatch) Dead() bool {
+	return len(t.Files) == 0
+}
+
+// Ambiguous reports whether the target file matched more than one report file
+func (t *TargetMatch) Ambiguous() bool {
+	return len(t.Files) > 1
+}
+
+// DeadFunctions returns the target function names that matched nothing
+func (t *TargetMatch) DeadFunctions() []string {
+	dead := make([]string, 0)
+	for _, fn := range t.Functions {
+		if len(fn.Matches) == 0 {
+			dead = append(dead, fn.Name)
+		}
+	}
+	return dead
+}
+
+// DeadRanges returns the target line ranges that contained no report lines
+func (t *TargetMatch) DeadRanges() []LineRange {
+	dead := make([]LineRange, 0)
+	for _, rm := range t.Ranges {
+		if rm.Lines == 0 {
+			dead = append(dead, rm.Range)
+		}
+	}
+	return dead
+}
+
+// HasDeadTargets reports whether any target file, function or line range matched nothing
+func (r *FilterMatchReport) HasDeadTargets() bool {
+	for i := range r.Targets {
+		t := &r.Targets[i]
+		if t.Dead() || len(t.DeadFunctions()) > 0 || len(t.DeadRanges()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Warnings returns one human-readable message per dead or ambiguous target
+func (r *FilterMatchReport) Warnings() []string {
+	warnings := make([]string, 0)
+
+	for i := range r.Targets {
+		target := &r.Targets[i]
+
+		if target.Dead() {
+			warnings = append(warnings, fmt.Sprintf("target file %q matched no files in the report", target.File))
+			continue
+		}
+
+		if target.Ambiguous() {
+			warnings = append(warnings, fmt.Sprintf("target file %q is ambiguous, matched %d files: %s",
+				target.File, len(target.Files), strings.Join(target.Files, ", ")))
+		}
+
+		for _, name := range target.DeadFunctions() {
+			warnings = append(warnings, fmt.Sprintf("target function %q in %q matched no functions", name, target.File))
+		}
+
+		for _, r := range target.DeadRanges() {
+			warnings = append(warnings, fmt.Sprintf("target lines %q in %q matched no lines", r.String(), target.File))
+		}
+	}
+
+	return warnings
+}
+
+// FormatFilterMatchReport formats the filter match report as a human-readable string
+func FormatFilterMatchReport(report *FilterMatchReport) string {
+	if len(report.Targets) == 0 {
+		return "No filter targets defined.\n"
+	}
+
+	result := fmt.Sprintf("Filter Match Report\n")
+	result += fmt.Sprintf("===================\n\n")
+
+	for i := range report.Targets {
+		target := &report.Targets[i]
+
+		status := "OK"
+		if target.Dead() {
+			status = "NO MATCH"
+		} else if target.Ambiguous() {
+			status = "AMBIGUOUS"
+		}
+
+		result += fmt.Sprintf("%d. Target: %s [%s]\n", i+1, target.File, status)
+		for _, file := range target.Files {
+			result += fmt.Sprintf("   Matched File: %s\n", file)
+		}
+
+		for _, fn := range target.Functions {
+			if len(fn.Matches) == 0 {
+				result += fmt.Sprintf("   Function: %s -> NO MATCH\n", fn.Name)
+				continue
+			}
+			for _, m := range fn.Matches {
+				result += fmt.Sprintf("   Function: %s -> %s (%s)\n", fn.Name, m.DemangledName, m.File)
+			}
+		}
+
+		for _, rm := range target.Ranges {
+			if rm.Lines == 0 {
+				result += fmt.Sprintf("   Lines: %s -> NO MATCH\n", rm.Range)
+				continue
+			}
+			result += fmt.Sprintf("   Lines: %s -> %d line(s)\n", rm.Range, rm.Lines)
+		}
+		result += "\n"
+	}
+
+	return result
+}
@@ -0,0 +1,107 @@
+package gcovr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterMatchReport_Warnings(t *testing.T) {
+	report := &FilterMatchReport{
+		Targets: []TargetMatch{
+			{
+				File:  "demo.cc",
+				Files: []string{"demo.cc"},
+				Functions: []FunctionMatch{
+					{Name: "f", Matches: []MatchedFunction{{File: "demo.cc", Name: "_Z1fv", DemangledName: "f()"}}},
+					{Name: "h"},
+				},
+			},
+			{
+				File:  "expr.cc",
+				Files: []string{"gcc/expr.cc", "gcc/cp/expr.cc"},
+			},
+			{
+				File: "missing.cc",
+			},
+		},
+	}
+
+	warnings := report.Warnings()
+	if len(warnings) != 3 {
+		t.Fatalf("Expected 3 warnings, got %d: %v", len(warnings), warnings)
+	}
+
+	expected := []string{`"h"`, "ambiguous", `"missing.cc"`}
+	for i, substr := range expected {
+		if !strings.Contains(warnings[i], substr) {
+			t.Errorf("Expected warning %d to contain %s, got %q", i, substr, warnings[i])
+		}
+	}
+
+	if !report.HasDeadTargets() {
+		t.Error("Expected HasDeadTargets to be true")
+	}
+}
+
+func TestFilterMatchReport_NoWarnings(t *testing.T) {
+	report := &FilterMatchReport{
+		Targets: []TargetMatch{
+			{
+				File:  "demo.cc",
+				Files: []string{"demo.cc"},
+				Functions: []FunctionMatch{
+					{Name: "f", Matches: []MatchedFunction{{File: "demo.cc", Name: "_Z1fv", DemangledName: "f()"}}},
+				},
+			},
+		},
+	}
+
+	if warnings := report.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+	if report.HasDeadTargets() {
+		t.Error("Expected HasDeadTargets to be false")
+	}
+}
+
+func TestFormatFilterMatchReport(t *testing.T) {
+	report := &FilterMatchReport{
+		Targets: []TargetMatch{
+			{
+				File:  "demo.cc",
+				Files: []string{"demo.cc"},
+				Functions: []FunctionMatch{
+					{Name: "f", Matches: []MatchedFunction{{File: "demo.cc", Name: "_Z1fv", DemangledName: "f()"}}},
+					{Name: "h"},
+				},
+				Ranges: []RangeMatch{
+					{Range: LineRange{Start: 1200, End: 1350}, Lines: 42},
+					{Range: LineRange{Start: 1402, End: 1402}},
+				},
+			},
+			{File: "missing.cc"},
+		},
+	}
+
+	output := FormatFilterMatchReport(report)
+
+	expectedStrings := []string{
+		"Filter Match Report",
+		"1. Target: demo.cc [OK]",
+		"Matched File: demo.cc",
+		"Function: f -> f() (demo.cc)",
+		"Function: h -> NO MATCH",
+		"Lines: 1200-1350 -> 42 line(s)",
+		"Lines: 1402 -> NO MATCH",
+		"2. Target: missing.cc [NO MATCH]",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	if empty := FormatFilterMatchReport(&FilterMatchReport{}); !strings.Contains(empty, "No filter targets") {
+		t.Errorf("Expected empty message, got %q", empty)
+	}
+}
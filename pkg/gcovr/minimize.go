@@ -0,0 +1,242 @@
+package gcovr
+
+import (
+	"bufio"
+	"container/heap"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Minimizer selects a small subset of reports, e.g. the seeds of a fuzz
+// corpus, that covers every line and branch covered by all of them. Reports
+// are added one at a time and only their covered lines and branches are
+// kept, so large corpora do not need to be held in memory.
+type Minimizer struct {
+	keyIDs  map[coverageKey]int
+	isLine  []bool // Whether each key id is a line (otherwise a branch)
+	names   []string
+	costs   []float64
+	covered [][]int // Key ids covered by each report
+}
+
+// SelectedReport is one report chosen by Minimize
+type SelectedReport struct {
+	Name        string
+	Cost        float64
+	NewLines    int // Lines first covered by this report in selection order
+	NewBranches int // Branches first covered by this report in selection order
+}
+
+// MinimizeResult holds the reports chosen by Minimize, in selection order
+type MinimizeResult struct {
+	Selected        []SelectedReport
+	TotalReports    int
+	TotalCost       float64 // Cost of all reports
+	CoveredLines    int     // Lines covered by the union of all reports
+	CoveredBranches int     // Branches covered by the union of all reports
+}
+
+// SelectedCost returns the summed cost of the selected reports
+func (r *MinimizeResult) SelectedCost() float64 {
+	cost := 0.0
+	for _, s := range r.Selected {
+		cost += s.Cost
+	}
+	return cost
+}
+
+// NewMinimizer creates an empty Minimizer
+func NewMinimizer() *Minimizer {
+	return &Minimizer{
+		keyIDs:  make(map[coverageKey]int),
+		isLine:  make([]bool, 0),
+		names:   make([]string, 0),
+		costs:   make([]float64, 0),
+		covered: make([][]int, 0),
+	}
+}
+
+// Add records the lines and branches a report covers. cost weights the
+// report during selection (e.g. input size or runtime); use 1 for plain
+// set cover. The cost must be positive.
+func (m *Minimizer) Add(name string, report *GcovrReport, cost float64) error {
+	if cost <= 0 {
+		return fmt.Errorf("cost of %s must be positive, got %g", name, cost)
+	}
+
+	keys := coveredKeys(report)
+	ids := make([]int, 0, len(keys))
+	for key := range keys {
+		id, ok := m.keyIDs[key]
+		if !ok {
+			id = len(m.isLine)
+			m.keyIDs[key] = id
+			m.isLine = append(m.isLine, key.Branch < 0)
+		}
+		ids = append(ids, id)
+	}
+
+	m.names = append(m.names, name)
+	m.costs = append(m.costs, cost)
+	m.covered = append(m.covered, ids)
+	return nil
+}
+
+// Minimize runs a greedy weighted set cover: it repeatedly picks the report
+// covering the most not yet covered lines and branches per unit of cost,
+// until the union of all added reports is covered. Ties go to the report
+// added first. Reports that cover nothing new are never selected.
+func (m *Minimizer) Minimize() *MinimizeResult {
+	result := &MinimizeResult{
+		Selected:     make([]SelectedReport, 0),
+		TotalReports: len(m.names),
+	}
+	for _, cost := range m.costs {
+		result.TotalCost += cost
+	}
+	for _, line := range m.isLine {
+		if line {
+			result.CoveredLines++
+		} else {
+			result.CoveredBranches++
+		}
+	}
+
+	done := make([]bool, len(m.isLine))
+	gain := func(i int) int {
+		n := 0
+		for _, id := range m.covered[i] {
+			if !done[id] {
+				n++
+			}
+		}
+		return n
+	}
+
+	// Gains only shrink as reports are selected, so stale scores in the
+	// queue are upper bounds and only the top candidate needs rechecking
+	queue := &candidateQueue{}
+	for i := range m.names {
+		if len(m.covered[i]) > 0 {
+			queue.items = append(queue.items, coverCandidate{index: i, score: float64(len(m.covered[i])) / m.costs[i]})
+		}
+	}
+	heap.Init(queue)
+
+	for queue.Len() > 0 {
+		top := heap.Pop(queue).(coverCandidate)
+		n := gain(top.index)
+		if n == 0 {
+			continue
+		}
+		top.score = float64(n) / m.costs[top.index]
+		if queue.Len() > 0 && queue.less(queue.items[0], top) {
+			heap.Push(queue, top)
+			continue
+		}
+
+		selected := SelectedReport{Name: m.names[top.index], Cost: m.costs[top.index]}
+		for _, id := range m.covered[top.index] {
+			if done[id] {
+				continue
+			}
+			done[id] = true
+			if m.isLine[id] {
+				selected.NewLines++
+			} else {
+				selected.NewBranches++
+			}
+		}
+		result.Selected = append(result.Selected, selected)
+	}
+
+	return result
+}
+
+// coverCandidate is a report waiting in the selection queue
+type coverCandidate struct {
+	index int
+	score float64 // New keys per unit of cost, possibly stale
+}
+
+// candidateQueue is a max-heap of candidates by score, then by lowest index
+type candidateQueue struct {
+	items []coverCandidate
+}
+
+func (q *candidateQueue) less(a, b coverCandidate) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	return a.index < b.index
+}
+
+func (q *candidateQueue) Len() int           { return len(q.items) }
+func (q *candidateQueue) Less(i, j int) bool { return q.less(q.items[i], q.items[j]) }
+func (q *candidateQueue) Swap(i, j int)      { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *candidateQueue) Push(x any)         { q.items = append(q.items, x.(coverCandidate)) }
+func (q *candidateQueue) Pop() any {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
+
+// ParseCosts reads report costs, one "<path> <cost>" pair per line. Blank
+// lines and lines starting with "#" are ignored; the cost is the last field,
+// so paths may contain spaces. Paths are cleaned with filepath.Clean, so
+// "./a.json" and "a.json" are the same report and may be listed only once.
+func ParseCosts(r io.Reader) (map[string]float64, error) {
+	costs := make(map[string]float64)
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		idx := strings.LastIndexAny(text, " \t")
+		if idx == -1 {
+			return nil, fmt.Errorf("line %d: expected \"<path> <cost>\", got %q", lineNum, text)
+		}
+		path := strings.TrimSpace(text[:idx])
+		cost, err := strconv.ParseFloat(text[idx+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid cost: %w", lineNum, err)
+		}
+		if cost <= 0 {
+			return nil, fmt.Errorf("line %d: cost of %s must be positive, got %g", lineNum, path, cost)
+		}
+		path = filepath.Clean(path)
+		if _, ok := costs[path]; ok {
+			return nil, fmt.Errorf("line %d: duplicate cost for %s", lineNum, path)
+		}
+		costs[path] = cost
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return costs, nil
+}
+
+// FormatMinimizeReport formats the minimization result as a human-readable string
+func FormatMinimizeReport(result *MinimizeResult) string {
+	output := fmt.Sprintf("Corpus Minimization Report\n")
+	output += fmt.Sprintf("==========================\n\n")
+	output += fmt.Sprintf("Selected %d of %d report(s) covering %d line(s) and %d branch(es)\n",
+		len(result.Selected), result.TotalReports, result.CoveredLines, result.CoveredBranches)
+	output += fmt.Sprintf("Cost: %g of %g\n\n", result.SelectedCost(), result.TotalCost)
+
+	for i, s := range result.Selected {
+		output += fmt.Sprintf("%d. %s (+%d line(s), +%d branch(es), cost %g)\n",
+			i+1, s.Name, s.NewLines, s.NewBranches, s.Cost)
+	}
+
+	return output
+}
@@ -0,0 +1,138 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// selectedNames returns the names of the selected reports in order
+func selectedNames(result *MinimizeResult) []string {
+	names := make([]string, 0, len(result.Selected))
+	for _, s := range result.Selected {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestMinimizer(t *testing.T) {
+	m := NewMinimizer()
+	inputs := []struct {
+		name  string
+		lines []int
+	}{
+		{"small.json", []int{1}},
+		{"big.json", []int{1, 2, 3, 4}},
+		{"rest.json", []int{5, 6}},
+		{"overlap.json", []int{4, 5}},
+		{"empty.json", nil},
+	}
+	for _, in := range inputs {
+		if err := m.Add(in.name, attributionReport(in.lines), 1); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	result := m.Minimize()
+
+	if names := selectedNames(result); !reflect.DeepEqual(names, []string{"big.json", "rest.json"}) {
+		t.Errorf("Expected big.json and rest.json, got %v", names)
+	}
+	if result.TotalReports != 5 || result.CoveredLines != 6 || result.SelectedCost() != 2 || result.TotalCost != 5 {
+		t.Errorf("Unexpected totals: %+v", result)
+	}
+	if s := result.Selected[1]; s.NewLines != 2 || s.NewBranches != 0 {
+		t.Errorf("Expected rest.json to add 2 lines, got %+v", s)
+	}
+}
+
+func TestMinimizer_Branches(t *testing.T) {
+	// Same lines, but each report takes a different branch: both are needed
+	m := NewMinimizer()
+	m.Add("a.json", attributionReport([]int{10}, 0), 1)
+	m.Add("b.json", attributionReport([]int{10}, 1), 1)
+	m.Add("c.json", attributionReport([]int{10}, 0), 1)
+
+	result := m.Minimize()
+	if names := selectedNames(result); !reflect.DeepEqual(names, []string{"a.json", "b.json"}) {
+		t.Errorf("Expected a.json and b.json, got %v", names)
+	}
+	if result.CoveredBranches != 2 {
+		t.Errorf("Expected 2 covered branches, got %d", result.CoveredBranches)
+	}
+}
+
+func TestMinimizer_Costs(t *testing.T) {
+	// The big report is too expensive compared to two cheap ones
+	m := NewMinimizer()
+	m.Add("big.json", attributionReport([]int{1, 2, 3, 4}), 10)
+	m.Add("left.json", attributionReport([]int{1, 2}), 1)
+	m.Add("right.json", attributionReport([]int{3, 4}), 1)
+
+	result := m.Minimize()
+	if names := selectedNames(result); !reflect.DeepEqual(names, []string{"left.json", "right.json"}) {
+		t.Errorf("Expected left.json and right.json, got %v", names)
+	}
+	if result.SelectedCost() != 2 {
+		t.Errorf("Expected selected cost 2, got %g", result.SelectedCost())
+	}
+}
+
+func TestMinimizer_InvalidCost(t *testing.T) {
+	if err := NewMinimizer().Add("a.json", attributionReport([]int{1}), 0); err == nil {
+		t.Error("Expected an error for a zero cost")
+	}
+}
+
+func TestParseCosts(t *testing.T) {
+	input := `# seed costs
+seeds/a.json 12
+seeds/with space.json	0.5
+./seeds/../seeds/b.json 3
+
+`
+	costs, err := ParseCosts(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]float64{"seeds/a.json": 12, "seeds/with space.json": 0.5, "seeds/b.json": 3}
+	if !reflect.DeepEqual(costs, expected) {
+		t.Errorf("Expected %v, got %v", expected, costs)
+	}
+}
+
+func TestParseCosts_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{input: "a.json", err: "line 1: expected"},
+		{input: "a.json cheap", err: "line 1: invalid cost"},
+		{input: "a.json 1\nb.json -2", err: "line 2: cost of b.json must be positive"},
+		{input: "a.json 1\n./a.json 2", err: "line 2: duplicate cost for a.json"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCosts(strings.NewReader(tt.input))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseCosts(%q): expected error containing %q, got %v", tt.input, tt.err, err)
+		}
+	}
+}
+
+func TestFormatMinimizeReport(t *testing.T) {
+	m := NewMinimizer()
+	m.Add("a.json", attributionReport([]int{1, 2}), 1)
+	m.Add("b.json", attributionReport([]int{2}), 1)
+
+	output := FormatMinimizeReport(m.Minimize())
+	for _, want := range []string{
+		"Selected 1 of 2 report(s) covering 2 line(s) and 0 branch(es)\n",
+		"Cost: 1 of 2\n",
+		"1. a.json (+2 line(s), +0 branch(es), cost 1)\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
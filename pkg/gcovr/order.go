@@ -0,0 +1,214 @@
+package gcovr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Sort orders for uncovered and diff reports
+const (
+	SortByFile      = "file"      // File path, then function name
+	SortByLine      = "line"      // File path, then line number
+	SortByUncovered = "uncovered" // Most uncovered lines first
+	SortByCoverage  = "coverage"  // Lowest line coverage first
+)
+
+// ValidateSortOrder checks a sort order for uncovered and diff reports
+func ValidateSortOrder(by string) error {
+	switch by {
+	case SortByFile, SortByLine, SortByUncovered, SortByCoverage:
+		return nil
+	}
+	return fmt.Errorf("unknown sort order %q (expected %s, %s, %s or %s)",
+		by, SortByFile, SortByLine, SortByUncovered, SortByCoverage)
+}
+
+// lineCoveragePercent returns the covered percentage of a function's lines
+func lineCoveragePercent(covered, total int) float64 {
+	return CoverageCount{Covered: covered, Total: total}.Percent()
+}
+
+// functionRank holds what functions are ordered by
+type functionRank struct {
+	file      string
+	name      string // Demangled name
+	lineNo    int
+	uncovered int
+	covered   int
+	total     int
+}
+
+// functionLess orders two functions by the given sort order
+func functionLess(by string, a, b functionRank) bool {
+	switch by {
+	case SortByUncovered:
+		if a.uncovered != b.uncovered {
+			return a.uncovered > b.uncovered
+		}
+	case SortByCoverage:
+		pa, pb := lineCoveragePercent(a.covered, a.total), lineCoveragePercent(b.covered, b.total)
+		if pa != pb {
+			return pa < pb
+		}
+		if a.uncovered != b.uncovered {
+			return a.uncovered > b.uncovered
+		}
+	}
+
+	if a.file != b.file {
+		return a.file < b.file
+	}
+	if by == SortByFile && a.name != b.name {
+		return a.name < b.name
+	}
+	if a.lineNo != b.lineNo {
+		return a.lineNo < b.lineNo
+	}
+	return a.name < b.name
+}
+
+// uncoveredLess orders two uncovered functions of the given files
+func uncoveredLess(by, fileA, fileB string, a, b *FunctionUncovered) bool {
+	return functionLess(by,
+		functionRank{file: fileA, name: a.DemangledName, lineNo: a.LineNo,
+			uncovered: len(a.UncoveredLineNumbers), covered: a.CoveredLines, total: a.TotalLines},
+		functionRank{file: fileB, name: b.DemangledName, lineNo: b.LineNo,
+			uncovered: len(b.UncoveredLineNumbers), covered: b.CoveredLines, total: b.TotalLines})
+}
+
+// Sort orders the functions of the report. With SortByFile and SortByLine
+// functions stay grouped by file; SortByUncovered and SortByCoverage rank
+// functions across files, so a file is listed once for each run of its
+// functions. Unknown orders leave the report unchanged.
+func (r *UncoveredReport) Sort(by string) {
+	if ValidateSortOrder(by) != nil {
+		return
+	}
+
+	type entry struct {
+		file string
+		fn   FunctionUncovered
+	}
+	entries := make([]entry, 0)
+	for _, file := range r.Files {
+		for _, fn := range file.UncoveredFunctions {
+			entries = append(entries, entry{file: file.FilePath, fn: fn})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return uncoveredLess(by, entries[i].file, entries[j].file, &entries[i].fn, &entries[j].fn)
+	})
+
+	r.Files = make([]FileUncovered, 0)
+	for _, e := range entries {
+		if n := len(r.Files); n == 0 || r.Files[n-1].FilePath != e.file {
+			r.Files = append(r.Files, FileUncovered{FilePath: e.file, UncoveredFunctions: make([]FunctionUncovered, 0)})
+		}
+		last := &r.Files[len(r.Files)-1]
+		last.UncoveredFunctions = append(last.UncoveredFunctions, e.fn)
+	}
+}
+
+// FunctionCount returns the number of functions in the report
+func (r *UncoveredReport) FunctionCount() int {
+	count := 0
+	for _, file := range r.Files {
+		count += len(file.UncoveredFunctions)
+	}
+	return count
+}
+
+// Top keeps the first n functions of the report, in its current order.
+// A non-positive n keeps every function.
+func (r *UncoveredReport) Top(n int) {
+	if n <= 0 {
+		return
+	}
+
+	files := make([]FileUncovered, 0)
+	for _, file := range r.Files {
+		if n == 0 {
+			break
+		}
+		if len(file.UncoveredFunctions) > n {
+			file.UncoveredFunctions = file.UncoveredFunctions[:n]
+		}
+		n -= len(file.UncoveredFunctions)
+		files = append(files, file)
+	}
+	r.Files = files
+}
+
+// Sort orders each class of functions like UncoveredReport.Sort. Unknown
+// orders leave the classification unchanged.
+func (c *FunctionClassification) Sort(by string) {
+	if ValidateSortOrder(by) != nil {
+		return
+	}
+
+	rank := func(fn *ClassifiedFunction) functionRank {
+		return functionRank{file: fn.File, name: fn.DemangledName, lineNo: fn.LineNo,
+			uncovered: fn.TotalLines - fn.CoveredLines, covered: fn.CoveredLines, total: fn.TotalLines}
+	}
+	for _, functions := range [][]ClassifiedFunction{c.NeverCalled, c.Partial, c.Full} {
+		sort.SliceStable(functions, func(i, j int) bool {
+			return functionLess(by, rank(&functions[i]), rank(&functions[j]))
+		})
+	}
+}
+
+// increaseFirstLine returns the first newly covered line of a function
+func increaseFirstLine(inc *FunctionCoverageIncrease) int {
+	first := 0
+	for _, line := range inc.IncreasedLineNumbers {
+		if first == 0 || line < first {
+			first = line
+		}
+	}
+	return first
+}
+
+// Sort orders the coverage increases. SortByLine uses the first newly
+// covered line of each function; SortByUncovered and SortByCoverage rank
+// functions by the lines still uncovered in the new report. Unknown orders
+// leave the report unchanged.
+func (r *CoverageIncreaseReport) Sort(by string) {
+	if ValidateSortOrder(by) != nil {
+		return
+	}
+
+	sort.SliceStable(r.Increases, func(i, j int) bool {
+		a, b := &r.Increases[i], &r.Increases[j]
+		switch by {
+		case SortByUncovered:
+			if ua, ub := a.TotalLines-a.NewCoveredLines, b.TotalLines-b.NewCoveredLines; ua != ub {
+				return ua > ub
+			}
+		case SortByCoverage:
+			pa, pb := lineCoveragePercent(a.NewCoveredLines, a.TotalLines), lineCoveragePercent(b.NewCoveredLines, b.TotalLines)
+			if pa != pb {
+				return pa < pb
+			}
+		}
+
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if by == SortByFile && a.DemangledName != b.DemangledName {
+			return a.DemangledName < b.DemangledName
+		}
+		if la, lb := increaseFirstLine(a), increaseFirstLine(b); la != lb {
+			return la < lb
+		}
+		return a.DemangledName < b.DemangledName
+	})
+}
+
+// Top keeps the first n coverage increases, in the report's current order.
+// A non-positive n keeps every increase.
+func (r *CoverageIncreaseReport) Top(n int) {
+	if n > 0 && n < len(r.Increases) {
+		r.Increases = r.Increases[:n]
+	}
+}
@@ -0,0 +1,246 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// uncoveredOrder lists the report's functions as "file:function"
+func uncoveredOrder(report *UncoveredReport) []string {
+	names := make([]string, 0)
+	for _, file := range report.Files {
+		for _, fn := range file.UncoveredFunctions {
+			names = append(names, file.FilePath+":"+fn.FunctionName)
+		}
+	}
+	return names
+}
+
+func TestUncoveredReportOrder(t *testing.T) {
+	// b.cc: zeta (lines 20-22, all uncovered) and alpha (30-31, half covered)
+	// a.cc: main (1-4, one uncovered) and helper (10-11, all uncovered)
+	demo := &GcovrReport{Files: []File{
+		{
+			FilePath: "b.cc",
+			Lines: []Line{
+				{LineNumber: 20, FunctionName: "zeta", Count: 0},
+				{LineNumber: 21, FunctionName: "zeta", Count: 0},
+				{LineNumber: 22, FunctionName: "zeta", Count: 0},
+				{LineNumber: 30, FunctionName: "alpha", Count: 1},
+				{LineNumber: 31, FunctionName: "alpha", Count: 0},
+			},
+		},
+		{
+			FilePath: "a.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "main", Count: 1},
+				{LineNumber: 2, FunctionName: "main", Count: 1},
+				{LineNumber: 3, FunctionName: "main", Count: 1},
+				{LineNumber: 4, FunctionName: "main", Count: 0},
+				{LineNumber: 10, FunctionName: "helper", Count: 0},
+				{LineNumber: 11, FunctionName: "helper", Count: 0},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		by       string // Empty to keep the order of FindUncoveredLines
+		top      int
+		expected []string
+		files    int
+	}{
+		{
+			name:     "Default order",
+			expected: []string{"a.cc:main", "a.cc:helper", "b.cc:zeta", "b.cc:alpha"},
+			files:    2,
+		},
+		{
+			name:     "By file",
+			by:       SortByFile,
+			expected: []string{"a.cc:helper", "a.cc:main", "b.cc:alpha", "b.cc:zeta"},
+			files:    2,
+		},
+		{
+			name:     "By line",
+			by:       SortByLine,
+			expected: []string{"a.cc:main", "a.cc:helper", "b.cc:zeta", "b.cc:alpha"},
+			files:    2,
+		},
+		{
+			name:     "By uncovered lines",
+			by:       SortByUncovered,
+			expected: []string{"b.cc:zeta", "a.cc:helper", "a.cc:main", "b.cc:alpha"},
+			files:    3,
+		},
+		{
+			name:     "By coverage",
+			by:       SortByCoverage,
+			expected: []string{"b.cc:zeta", "a.cc:helper", "b.cc:alpha", "a.cc:main"},
+			files:    4,
+		},
+		{
+			name:     "Unknown order",
+			by:       "size",
+			expected: []string{"a.cc:main", "a.cc:helper", "b.cc:zeta", "b.cc:alpha"},
+			files:    2,
+		},
+		{
+			name:     "Top",
+			by:       SortByLine,
+			top:      3,
+			expected: []string{"a.cc:main", "a.cc:helper", "b.cc:zeta"},
+			files:    2,
+		},
+		{
+			name:     "Top after ranking",
+			by:       SortByUncovered,
+			top:      1,
+			expected: []string{"b.cc:zeta"},
+			files:    1,
+		},
+		{
+			name:     "Top beyond the count",
+			top:      10,
+			expected: []string{"a.cc:main", "a.cc:helper", "b.cc:zeta", "b.cc:alpha"},
+			files:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Repeat to catch map iteration order leaking into the result
+			for i := 0; i < 20; i++ {
+				report, err := FindUncoveredLines(demo)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if report.FunctionCount() != 4 {
+					t.Fatalf("Expected 4 functions, got %d", report.FunctionCount())
+				}
+				report.Sort(tt.by)
+				report.Top(tt.top)
+
+				if got := uncoveredOrder(report); !reflect.DeepEqual(got, tt.expected) {
+					t.Fatalf("Expected %v, got %v", tt.expected, got)
+				}
+				if len(report.Files) != tt.files {
+					t.Fatalf("Expected %d file groups, got %d", tt.files, len(report.Files))
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSortOrder(t *testing.T) {
+	for _, by := range []string{SortByFile, SortByLine, SortByUncovered, SortByCoverage} {
+		if err := ValidateSortOrder(by); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", by, err)
+		}
+	}
+	if err := ValidateSortOrder("size"); err == nil {
+		t.Error("Expected an error for an unknown sort order")
+	}
+}
+
+func TestCoverageIncreaseReportOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		by       string
+		top      int
+		expected []string
+	}{
+		{name: "By line", by: SortByLine, expected: []string{"z()", "c()", "b()"}},
+		{name: "By file", by: SortByFile, expected: []string{"c()", "z()", "b()"}},
+		{name: "By uncovered lines", by: SortByUncovered, expected: []string{"c()", "z()", "b()"}},
+		{name: "By coverage", by: SortByCoverage, expected: []string{"z()", "c()", "b()"}},
+		{name: "Unknown order", by: "size", expected: []string{"b()", "z()", "c()"}},
+		{name: "Top", by: SortByCoverage, top: 1, expected: []string{"z()"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &CoverageIncreaseReport{Increases: []FunctionCoverageIncrease{
+				{File: "b.cc", DemangledName: "b()", IncreasedLineNumbers: []int{5}, TotalLines: 10, NewCoveredLines: 9},
+				{File: "a.cc", DemangledName: "z()", IncreasedLineNumbers: []int{8, 3}, TotalLines: 4, NewCoveredLines: 1},
+				{File: "a.cc", DemangledName: "c()", IncreasedLineNumbers: []int{12}, TotalLines: 20, NewCoveredLines: 10},
+			}}
+			report.Sort(tt.by)
+			report.Top(tt.top)
+
+			names := make([]string, 0)
+			for _, inc := range report.Increases {
+				names = append(names, inc.DemangledName)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestFunctionClassificationOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		by       string
+		top      int
+		expected []string // Never-called functions listed by FormatNeverCalledFunctions
+		header   string
+	}{
+		{
+			name:     "By line",
+			by:       SortByLine,
+			expected: []string{"a.cc:3: small()", "b.cc:1: big()", "b.cc:9: mid()"},
+			header:   "Found 3 never-called function(s) (9 total uncovered lines):",
+		},
+		{
+			name:     "By file",
+			by:       SortByFile,
+			expected: []string{"a.cc:3: small()", "b.cc:1: big()", "b.cc:9: mid()"},
+			header:   "Found 3 never-called function(s) (9 total uncovered lines):",
+		},
+		{
+			name:     "By uncovered lines",
+			by:       SortByUncovered,
+			expected: []string{"b.cc:1: big()", "b.cc:9: mid()", "a.cc:3: small()"},
+			header:   "Found 3 never-called function(s) (9 total uncovered lines):",
+		},
+		{
+			name:     "Top",
+			by:       SortByUncovered,
+			top:      2,
+			expected: []string{"b.cc:1: big()", "b.cc:9: mid()"},
+			header:   "Top 2 of 3 never-called function(s) (9 total uncovered lines):",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classification := &FunctionClassification{
+				NeverCalled: []ClassifiedFunction{
+					{File: "b.cc", DemangledName: "mid()", LineNo: 9, TotalLines: 3},
+					{File: "a.cc", DemangledName: "small()", LineNo: 3, TotalLines: 1},
+					{File: "b.cc", DemangledName: "big()", LineNo: 1, TotalLines: 5},
+				},
+				Partial: []ClassifiedFunction{},
+				Full:    []ClassifiedFunction{},
+			}
+			classification.Sort(tt.by)
+			output := FormatNeverCalledFunctions(classification, tt.top)
+
+			if !strings.Contains(output, tt.header) {
+				t.Errorf("Expected %q in output:\n%s", tt.header, output)
+			}
+			listed := make([]string, 0)
+			for _, line := range strings.Split(output, "\n") {
+				if strings.HasPrefix(line, "   ") {
+					listed = append(listed, strings.TrimSpace(line[:strings.LastIndex(line, " (")]))
+				}
+			}
+			if !reflect.DeepEqual(listed, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, listed)
+			}
+		})
+	}
+}
@@ -18,5 +18,32 @@ func ParseReport(filePath string) (*GcovrReport, error) {
 		return nil, fmt.Errorf("failed to parse JSON from %s: %w", filePath, err)
 	}
 
+	fillDemangledNames(&report)
+
 	return &report, nil
 }
+
+// WriteReport writes a report to a file as indented gcovr JSON
+func WriteReport(report *GcovrReport, filePath string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filePath, err)
+	}
+
+	return nil
+}
+
+// fillDemangledNames demangles function names for reports that lack
+// demangled_name, such as those from older gcovr versions or native gcov
+func fillDemangledNames(report *GcovrReport) {
+	for i := range report.Files {
+		for j := range report.Files[i].Functions {
+			fn := &report.Files[i].Functions[j]
+			fn.DemangledName = functionDisplayName(fn.Name, fn.DemangledName)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package gcovr
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -266,3 +267,73 @@ func TestParseReport_DataStructure(t *testing.T) {
 		t.Errorf("Expected 2 positions, got %d", len(fn.Pos))
 	}
 }
+
+func TestParseReport_FillsMissingDemangledNames(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "parser_test_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	content := `{
+		"files": [
+			{
+				"file": "test.cpp",
+				"lines": [{"line_number": 1, "function_name": "_ZN2ns3fooEi", "count": 1}],
+				"functions": [
+					{"name": "_ZN2ns3fooEi", "lineno": 1},
+					{"name": "main", "lineno": 5},
+					{"name": "_Z3barv", "demangled_name": "bar()", "lineno": 9}
+				]
+			}
+		]
+	}`
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+	tmpFile.Close()
+
+	report, err := ParseReport(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"ns::foo(int)", "main", "bar()"}
+	for i, fn := range report.Files[0].Functions {
+		if fn.DemangledName != expected[i] {
+			t.Errorf("Expected function %d DemangledName=%q, got %q", i, expected[i], fn.DemangledName)
+		}
+	}
+}
+
+func TestWriteReport_RoundTrip(t *testing.T) {
+	report := &GcovrReport{
+		FormatVersion: "0.14",
+		Files: []File{
+			{
+				FilePath: "demo.cc",
+				Lines: []Line{
+					{LineNumber: 5, FunctionName: "_Z1fv", Count: 1, Branches: []Branch{{Count: 1, Fallthrough: true}}},
+				},
+				Functions: []Function{{Name: "_Z1fv", DemangledName: "f()", LineNo: 5}},
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := WriteReport(report, path); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	parsed, err := ParseReport(path)
+	if err != nil {
+		t.Fatalf("Failed to parse written report: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, report) {
+		t.Errorf("Expected %+v, got %+v", report, parsed)
+	}
+
+	if err := WriteReport(report, filepath.Join(t.TempDir(), "missing", "report.json")); err == nil {
+		t.Error("Expected error writing into a missing directory")
+	}
+}
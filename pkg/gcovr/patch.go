@@ -0,0 +1,245 @@
+package gcovr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FunctionPatchCoverage holds the coverage of the changed lines in one function
+type FunctionPatchCoverage struct {
+	FunctionName   string // Mangled name, empty for lines outside any function
+	DemangledName  string
+	CoveredLines   []int
+	UncoveredLines []int
+}
+
+// FilePatchCoverage holds the coverage of the changed lines in one file
+type FilePatchCoverage struct {
+	FilePath       string // Path in the coverage report
+	DiffPath       string // Path in the diff
+	CoveredLines   []int
+	UncoveredLines []int
+	Functions      []FunctionPatchCoverage
+}
+
+// AmbiguousFile is a changed file that matched several report files equally well
+type AmbiguousFile struct {
+	DiffPath string
+	Files    []string // Report file paths the changed file matched
+}
+
+// PatchCoverageReport describes how many changed lines of a patch are covered.
+// Changed lines that are not executable (absent from the report) are ignored.
+type PatchCoverageReport struct {
+	Files          []FilePatchCoverage
+	UnmatchedFiles []string        // Changed files with no counterpart in the report
+	AmbiguousFiles []AmbiguousFile // Changed files left out because they matched several report files
+}
+
+// Covered returns the number of covered changed lines
+func (r *PatchCoverageReport) Covered() int {
+	covered := 0
+	for _, file := range r.Files {
+		covered += len(file.CoveredLines)
+	}
+	return covered
+}
+
+// Total returns the number of executable changed lines
+func (r *PatchCoverageReport) Total() int {
+	total := 0
+	for _, file := range r.Files {
+		total += len(file.CoveredLines) + len(file.UncoveredLines)
+	}
+	return total
+}
+
+// Percent returns the covered share of the executable changed lines; a patch
+// without executable lines counts as fully covered
+func (r *PatchCoverageReport) Percent() float64 {
+	if r.Total() == 0 {
+		return 100.0
+	}
+	return float64(r.Covered()) * 100.0 / float64(r.Total())
+}
+
+// ComputePatchCoverage maps the added and modified lines of a unified diff
+// onto a report. Diff paths are matched to report paths by their longest
+// common trailing path components, so repository-relative diff paths match
+// build-relative report paths such as "../gcc/expr.cc". A diff path that
+// matches several report files equally well is listed in AmbiguousFiles and
+// its lines are not counted.
+func ComputePatchCoverage(report *GcovrReport, diffs []FileDiff) (*PatchCoverageReport, error) {
+	result := &PatchCoverageReport{
+		Files:          make([]FilePatchCoverage, 0),
+		UnmatchedFiles: make([]string, 0),
+		AmbiguousFiles: make([]AmbiguousFile, 0),
+	}
+
+	for _, diff := range diffs {
+		if diff.NewPath == "" {
+			continue // Deleted file
+		}
+		added := diff.AddedLines()
+		if len(added) == 0 {
+			continue
+		}
+
+		files := findReportFiles(report, diff.NewPath)
+		if len(files) == 0 {
+			result.UnmatchedFiles = append(result.UnmatchedFiles, diff.NewPath)
+			continue
+		}
+		if len(files) > 1 {
+			ambiguous := AmbiguousFile{DiffPath: diff.NewPath, Files: make([]string, 0, len(files))}
+			for _, file := range files {
+				ambiguous.Files = append(ambiguous.Files, file.FilePath)
+			}
+			result.AmbiguousFiles = append(result.AmbiguousFiles, ambiguous)
+			continue
+		}
+
+		fileCoverage := filePatchCoverage(files[0], diff.NewPath, added)
+		if len(fileCoverage.CoveredLines)+len(fileCoverage.UncoveredLines) > 0 {
+			result.Files = append(result.Files, fileCoverage)
+		}
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].FilePath < result.Files[j].FilePath
+	})
+
+	return result, nil
+}
+
+// findReportFiles returns the report files sharing the most trailing path
+// components with path. A report file with exactly that path wins outright;
+// otherwise several files are returned when they tie.
+func findReportFiles(report *GcovrReport, path string) []*File {
+	normalized := normalizeFilePath(path)
+	pathParts := strings.Split(normalized, "/")
+
+	best := make([]*File, 0)
+	bestLen := 0
+	for i := range report.Files {
+		filePath := normalizeFilePath(report.Files[i].FilePath)
+		if filePath == normalized {
+			return []*File{&report.Files[i]}
+		}
+
+		n := pathSuffixLength(strings.Split(filePath, "/"), pathParts)
+		if n == 0 || n < bestLen {
+			continue
+		}
+		if n > bestLen {
+			best, bestLen = best[:0], n
+		}
+		best = append(best, &report.Files[i])
+	}
+	return best
+}
+
+// filePatchCoverage classifies the changed lines of one report file
+func filePatchCoverage(file *File, diffPath string, added []int) FilePatchCoverage {
+	result := FilePatchCoverage{
+		FilePath:       file.FilePath,
+		DiffPath:       diffPath,
+		CoveredLines:   make([]int, 0),
+		UncoveredLines: make([]int, 0),
+		Functions:      make([]FunctionPatchCoverage, 0),
+	}
+
+	changed := make(map[int]bool)
+	for _, lineNum := range added {
+		changed[lineNum] = true
+	}
+
+	funcNames := buildFunctionNameMap(file)
+	funcIndex := make(map[string]int)
+	// A line shared by several functions (e.g. templates) counts once per
+	// file, as covered when any of its records ran
+	lineCovered := make(map[int]bool)
+
+	for _, line := range file.Lines {
+		if !changed[line.LineNumber] {
+			continue
+		}
+
+		idx, ok := funcIndex[line.FunctionName]
+		if !ok {
+			idx = len(result.Functions)
+			funcIndex[line.FunctionName] = idx
+			result.Functions = append(result.Functions, FunctionPatchCoverage{
+				FunctionName:   line.FunctionName,
+				DemangledName:  functionDisplayName(line.FunctionName, funcNames[line.FunctionName]),
+				CoveredLines:   make([]int, 0),
+				UncoveredLines: make([]int, 0),
+			})
+		}
+		fn := &result.Functions[idx]
+
+		if line.Count > 0 {
+			fn.CoveredLines = append(fn.CoveredLines, line.LineNumber)
+		} else {
+			fn.UncoveredLines = append(fn.UncoveredLines, line.LineNumber)
+		}
+
+		lineCovered[line.LineNumber] = lineCovered[line.LineNumber] || line.Count > 0
+	}
+
+	for lineNum, covered := range lineCovered {
+		if covered {
+			result.CoveredLines = append(result.CoveredLines, lineNum)
+		} else {
+			result.UncoveredLines = append(result.UncoveredLines, lineNum)
+		}
+	}
+
+	sort.Ints(result.CoveredLines)
+	sort.Ints(result.UncoveredLines)
+	for i := range result.Functions {
+		sort.Ints(result.Functions[i].CoveredLines)
+		sort.Ints(result.Functions[i].UncoveredLines)
+	}
+
+	return result
+}
+
+// FormatPatchCoverageReport formats the patch coverage report as a human-readable string
+func FormatPatchCoverageReport(report *PatchCoverageReport) string {
+	result := fmt.Sprintf("Patch Coverage Report\n")
+	result += fmt.Sprintf("=====================\n\n")
+
+	if report.Total() == 0 {
+		result += "No executable changed lines found.\n"
+		return result
+	}
+
+	result += fmt.Sprintf("Patch Coverage: %d/%d changed lines (%.1f%%)\n\n",
+		report.Covered(), report.Total(), report.Percent())
+
+	for i, file := range report.Files {
+		total := len(file.CoveredLines) + len(file.UncoveredLines)
+
+		result += fmt.Sprintf("%d. File: %s\n", i+1, file.FilePath)
+		result += fmt.Sprintf("   Changed Lines Covered: %d/%d (%.1f%%)\n",
+			len(file.CoveredLines), total, float64(len(file.CoveredLines))*100.0/float64(total))
+
+		for _, fn := range file.Functions {
+			name := fn.DemangledName
+			if name == "" {
+				name = "(outside functions)"
+			}
+			result += fmt.Sprintf("   Function: %s: %d/%d covered",
+				name, len(fn.CoveredLines), len(fn.CoveredLines)+len(fn.UncoveredLines))
+			if len(fn.UncoveredLines) > 0 {
+				result += fmt.Sprintf(", uncovered lines %v", fn.UncoveredLines)
+			}
+			result += "\n"
+		}
+		result += "\n"
+	}
+
+	return result
+}
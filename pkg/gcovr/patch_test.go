@@ -0,0 +1,191 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputePatchCoverage(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "../gcc/expr.cc",
+				Lines: []Line{
+					{LineNumber: 11, FunctionName: "_Z11expand_exprv", Count: 1},
+					{LineNumber: 12, FunctionName: "_Z11expand_exprv", Count: 0},
+					{LineNumber: 31, FunctionName: "_Z6helperv", Count: 3},
+					{LineNumber: 40, FunctionName: "_Z6helperv", Count: 0},
+				},
+				Functions: []Function{
+					{Name: "_Z11expand_exprv", DemangledName: "expand_expr()"},
+					{Name: "_Z6helperv", DemangledName: "helper()"},
+				},
+			},
+			{
+				FilePath: "../gcc/cp/expr.cc",
+				Lines:    []Line{{LineNumber: 11, FunctionName: "_Z2cpv", Count: 0}},
+			},
+		},
+	}
+
+	diffs, err := ParseUnifiedDiff(strings.NewReader(sampleGitDiff))
+	if err != nil {
+		t.Fatalf("Failed to parse diff: %v", err)
+	}
+
+	result, err := ComputePatchCoverage(report, diffs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %+v", result.Files)
+	}
+
+	file := result.Files[0]
+	if file.FilePath != "../gcc/expr.cc" || file.DiffPath != "gcc/expr.cc" {
+		t.Errorf("Expected gcc/expr.cc to map to ../gcc/expr.cc, got %s -> %s", file.DiffPath, file.FilePath)
+	}
+	if !reflect.DeepEqual(file.CoveredLines, []int{11, 31}) || !reflect.DeepEqual(file.UncoveredLines, []int{12}) {
+		t.Errorf("Unexpected lines: covered %v, uncovered %v", file.CoveredLines, file.UncoveredLines)
+	}
+	if len(file.Functions) != 2 || file.Functions[0].DemangledName != "expand_expr()" {
+		t.Errorf("Unexpected functions: %+v", file.Functions)
+	}
+
+	if result.Covered() != 2 || result.Total() != 3 {
+		t.Errorf("Expected 2/3 covered, got %d/%d", result.Covered(), result.Total())
+	}
+	if !reflect.DeepEqual(result.UnmatchedFiles, []string{"gcc/new.cc"}) {
+		t.Errorf("Expected gcc/new.cc to be unmatched, got %v", result.UnmatchedFiles)
+	}
+}
+
+func TestComputePatchCoverage_SharedLines(t *testing.T) {
+	// Line 5 belongs to two template instantiations; only the second one ran
+	report := &GcovrReport{Files: []File{{
+		FilePath: "demo.h",
+		Lines: []Line{
+			{LineNumber: 5, FunctionName: "_Z1fIiEvv", Count: 0},
+			{LineNumber: 5, FunctionName: "_Z1fIlEvv", Count: 2},
+			{LineNumber: 6, FunctionName: "_Z1fIiEvv", Count: 0},
+			{LineNumber: 6, FunctionName: "_Z1fIlEvv", Count: 0},
+		},
+	}}}
+	diffs := []FileDiff{{NewPath: "demo.h", Hunks: []DiffHunk{{NewStart: 5, NewCount: 2, Ops: "++"}}}}
+
+	result, err := ComputePatchCoverage(report, diffs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %+v", result.Files)
+	}
+
+	file := result.Files[0]
+	if !reflect.DeepEqual(file.CoveredLines, []int{5}) || !reflect.DeepEqual(file.UncoveredLines, []int{6}) {
+		t.Errorf("Expected line 5 covered and 6 uncovered, got covered %v, uncovered %v", file.CoveredLines, file.UncoveredLines)
+	}
+	if len(file.Functions) != 2 || !reflect.DeepEqual(file.Functions[0].UncoveredLines, []int{5, 6}) {
+		t.Errorf("Expected each instantiation to keep its own lines, got %+v", file.Functions)
+	}
+	if result.Covered() != 1 || result.Total() != 2 {
+		t.Errorf("Expected 1/2 covered, got %d/%d", result.Covered(), result.Total())
+	}
+}
+
+func TestComputePatchCoverage_AmbiguousFiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		diffPath  string
+		expected  string // Report file the changed lines are counted in, empty when none
+		ambiguous []string
+	}{
+		{name: "Equally long suffixes", diffPath: "expr.cc", ambiguous: []string{"gcc/expr.cc", "libcpp/expr.cc"}},
+		{name: "Longer suffix wins", diffPath: "repo/gcc/expr.cc", expected: "gcc/expr.cc"},
+		{name: "Exact path wins", diffPath: "lib/expr.cc", expected: "lib/expr.cc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &GcovrReport{Files: []File{
+				{FilePath: "gcc/expr.cc", Lines: []Line{{LineNumber: 1, Count: 1}}},
+				{FilePath: "libcpp/expr.cc", Lines: []Line{{LineNumber: 1, Count: 0}}},
+				{FilePath: "lib/expr.cc", Lines: []Line{{LineNumber: 1, Count: 0}}},
+				{FilePath: "src/lib/expr.cc", Lines: []Line{{LineNumber: 1, Count: 0}}},
+			}}
+			if tt.ambiguous != nil {
+				report.Files = report.Files[:2]
+			}
+			diffs := []FileDiff{{NewPath: tt.diffPath, Hunks: []DiffHunk{{NewStart: 1, NewCount: 1, Ops: "+"}}}}
+
+			result, err := ComputePatchCoverage(report, diffs)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if tt.expected == "" {
+				if len(result.Files) != 0 {
+					t.Errorf("Expected no file to be counted, got %+v", result.Files)
+				}
+				expected := []AmbiguousFile{{DiffPath: tt.diffPath, Files: tt.ambiguous}}
+				if !reflect.DeepEqual(result.AmbiguousFiles, expected) {
+					t.Errorf("Expected %+v, got %+v", expected, result.AmbiguousFiles)
+				}
+				return
+			}
+
+			if len(result.Files) != 1 || result.Files[0].FilePath != tt.expected {
+				t.Errorf("Expected the lines to be counted in %s, got %+v", tt.expected, result.Files)
+			}
+			if len(result.AmbiguousFiles) != 0 {
+				t.Errorf("Expected no ambiguous files, got %+v", result.AmbiguousFiles)
+			}
+		})
+	}
+}
+
+func TestComputePatchCoverage_NoExecutableLines(t *testing.T) {
+	report := &GcovrReport{Files: []File{{FilePath: "demo.cc", Lines: []Line{{LineNumber: 1, Count: 1}}}}}
+	diffs := []FileDiff{{NewPath: "demo.cc", Hunks: []DiffHunk{{NewStart: 5, NewCount: 1, Ops: "+"}}}}
+
+	result, err := ComputePatchCoverage(report, diffs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Total() != 0 || result.Percent() != 100.0 {
+		t.Errorf("Expected an empty patch to count as covered, got %d lines, %.1f%%", result.Total(), result.Percent())
+	}
+	if !strings.Contains(FormatPatchCoverageReport(result), "No executable changed lines") {
+		t.Error("Expected the empty patch message")
+	}
+}
+
+func TestFormatPatchCoverageReport(t *testing.T) {
+	report := &PatchCoverageReport{
+		Files: []FilePatchCoverage{
+			{
+				FilePath:       "demo.cc",
+				CoveredLines:   []int{5},
+				UncoveredLines: []int{9, 10},
+				Functions: []FunctionPatchCoverage{
+					{DemangledName: "f()", CoveredLines: []int{5}, UncoveredLines: []int{}},
+					{DemangledName: "g()", CoveredLines: []int{}, UncoveredLines: []int{9, 10}},
+				},
+			},
+		},
+	}
+
+	output := FormatPatchCoverageReport(report)
+	for _, expected := range []string{
+		"Patch Coverage: 1/3 changed lines (33.3%)",
+		"1. File: demo.cc",
+		"Function: f(): 1/1 covered",
+		"Function: g(): 0/2 covered, uncovered lines [9 10]",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
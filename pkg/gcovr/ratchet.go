@@ -0,0 +1,185 @@
+package gcovr
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FunctionRatchet compares the covered-line count of one function with its baseline
+type FunctionRatchet struct {
+	File             string
+	FunctionName     string // Mangled name
+	DemangledName    string
+	BaseCoveredLines int
+	NewCoveredLines  int
+	TotalLines       int   // Lines of the function in the new report
+	LostLineNumbers  []int // Lines covered in the baseline but not in the new report
+	Missing          bool  // The function is absent from the new report
+}
+
+// RatchetReport lists the functions whose coverage dropped or rose against a baseline
+type RatchetReport struct {
+	Regressions  []FunctionRatchet
+	Improvements []FunctionRatchet
+}
+
+// Passed reports whether no function lost covered lines
+func (r *RatchetReport) Passed() bool {
+	return len(r.Regressions) == 0
+}
+
+// Improved reports whether any function gained covered lines
+func (r *RatchetReport) Improved() bool {
+	return len(r.Improvements) > 0
+}
+
+// ComputeRatchet compares the covered-line count of every function in the
+// baseline with the new report. A function covering fewer lines than in the
+// baseline, or missing from the new report, is a regression; one covering
+// more lines is an improvement.
+func ComputeRatchet(baseline, newReport *GcovrReport) (*RatchetReport, error) {
+	result := &RatchetReport{
+		Regressions:  make([]FunctionRatchet, 0),
+		Improvements: make([]FunctionRatchet, 0),
+	}
+
+	newFileMap := make(map[string]*File)
+	for i := range newReport.Files {
+		newFileMap[newReport.Files[i].FilePath] = &newReport.Files[i]
+	}
+
+	for i := range baseline.Files {
+		baseFile := &baseline.Files[i]
+		baseCoverage := buildLineCoverageMap(baseFile)
+		baseNames := buildFunctionNameMap(baseFile)
+
+		var newCoverage map[string]map[int]int
+		newNames := make(map[string]string)
+		if newFile, exists := newFileMap[baseFile.FilePath]; exists {
+			newCoverage = buildLineCoverageMap(newFile)
+			newNames = buildFunctionNameMap(newFile)
+		}
+
+		for funcName, baseLines := range baseCoverage {
+			newLines, exists := newCoverage[funcName]
+
+			entry := FunctionRatchet{
+				File:            baseFile.FilePath,
+				FunctionName:    funcName,
+				TotalLines:      len(newLines),
+				LostLineNumbers: make([]int, 0),
+				Missing:         !exists,
+			}
+
+			demangledName := newNames[funcName]
+			if demangledName == "" {
+				demangledName = baseNames[funcName]
+			}
+			entry.DemangledName = functionDisplayName(funcName, demangledName)
+
+			for lineNum, count := range baseLines {
+				if count > 0 {
+					entry.BaseCoveredLines++
+					if newLines[lineNum] == 0 {
+						entry.LostLineNumbers = append(entry.LostLineNumbers, lineNum)
+					}
+				}
+			}
+			for _, count := range newLines {
+				if count > 0 {
+					entry.NewCoveredLines++
+				}
+			}
+			sort.Ints(entry.LostLineNumbers)
+
+			switch {
+			case entry.NewCoveredLines < entry.BaseCoveredLines:
+				result.Regressions = append(result.Regressions, entry)
+			case entry.NewCoveredLines > entry.BaseCoveredLines:
+				result.Improvements = append(result.Improvements, entry)
+			}
+		}
+	}
+
+	// Functions new to the report count as improvements when they cover anything
+	increases, err := ComputeCoverageIncrease(baseline, newReport)
+	if err != nil {
+		return nil, err
+	}
+	for _, inc := range increases.Increases {
+		if inc.OldCoveredLines != 0 || hasFunctionLines(baseline, inc.File, inc.FunctionName) {
+			continue
+		}
+		result.Improvements = append(result.Improvements, FunctionRatchet{
+			File:            inc.File,
+			FunctionName:    inc.FunctionName,
+			DemangledName:   inc.DemangledName,
+			NewCoveredLines: inc.NewCoveredLines,
+			TotalLines:      inc.TotalLines,
+			LostLineNumbers: make([]int, 0),
+		})
+	}
+
+	sortFunctionRatchets(result.Regressions)
+	sortFunctionRatchets(result.Improvements)
+
+	return result, nil
+}
+
+// hasFunctionLines reports whether a report has lines for a function in a file
+func hasFunctionLines(report *GcovrReport, filePath, funcName string) bool {
+	for _, file := range report.Files {
+		if file.FilePath != filePath {
+			continue
+		}
+		for _, line := range file.Lines {
+			if line.FunctionName == funcName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortFunctionRatchets orders entries by file and function name
+func sortFunctionRatchets(entries []FunctionRatchet) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].File != entries[j].File {
+			return entries[i].File < entries[j].File
+		}
+		return entries[i].DemangledName < entries[j].DemangledName
+	})
+}
+
+// FormatRatchetReport formats the ratchet result as a human-readable string
+func FormatRatchetReport(report *RatchetReport) string {
+	result := fmt.Sprintf("Coverage Ratchet Report\n")
+	result += fmt.Sprintf("=======================\n\n")
+
+	if len(report.Regressions) == 0 {
+		result += "No coverage regressions.\n\n"
+	} else {
+		result += fmt.Sprintf("Found %d function(s) with decreased coverage:\n\n", len(report.Regressions))
+		for i, r := range report.Regressions {
+			result += fmt.Sprintf("%d. File: %s\n", i+1, r.File)
+			result += fmt.Sprintf("   Function: %s\n", r.DemangledName)
+			if r.Missing {
+				result += fmt.Sprintf("   Missing from the new report (baseline covered %d lines)\n\n", r.BaseCoveredLines)
+				continue
+			}
+			result += fmt.Sprintf("   Covered Lines: %d -> %d\n", r.BaseCoveredLines, r.NewCoveredLines)
+			result += fmt.Sprintf("   No Longer Covered Line Numbers: %v\n\n", r.LostLineNumbers)
+		}
+	}
+
+	if len(report.Improvements) > 0 {
+		result += fmt.Sprintf("Found %d function(s) with increased coverage:\n\n", len(report.Improvements))
+		for i, r := range report.Improvements {
+			result += fmt.Sprintf("%d. File: %s\n", i+1, r.File)
+			result += fmt.Sprintf("   Function: %s\n", r.DemangledName)
+			result += fmt.Sprintf("   Covered Lines: %d -> %d\n\n", r.BaseCoveredLines, r.NewCoveredLines)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,136 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputeRatchet(t *testing.T) {
+	baseline := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "demo.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+					{LineNumber: 2, FunctionName: "_Z1fv", Count: 1},
+					{LineNumber: 5, FunctionName: "_Z1gv", Count: 0},
+					{LineNumber: 6, FunctionName: "_Z1gv", Count: 1},
+					{LineNumber: 9, FunctionName: "_Z1hv", Count: 1},
+					{LineNumber: 12, FunctionName: "_Z1kv", Count: 1},
+				},
+				Functions: []Function{
+					{Name: "_Z1fv", DemangledName: "f()"},
+					{Name: "_Z1gv", DemangledName: "g()"},
+					{Name: "_Z1hv", DemangledName: "h()"},
+					{Name: "_Z1kv", DemangledName: "k()"},
+				},
+			},
+		},
+	}
+
+	newReport := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "demo.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+					{LineNumber: 2, FunctionName: "_Z1fv", Count: 0},
+					{LineNumber: 5, FunctionName: "_Z1gv", Count: 2},
+					{LineNumber: 6, FunctionName: "_Z1gv", Count: 1},
+					{LineNumber: 12, FunctionName: "_Z1kv", Count: 5},
+					{LineNumber: 15, FunctionName: "_Z1nv", Count: 1},
+				},
+				Functions: []Function{
+					{Name: "_Z1fv", DemangledName: "f()"},
+					{Name: "_Z1gv", DemangledName: "g()"},
+					{Name: "_Z1kv", DemangledName: "k()"},
+					{Name: "_Z1nv", DemangledName: "n()"},
+				},
+			},
+		},
+	}
+
+	result, err := ComputeRatchet(baseline, newReport)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Passed() {
+		t.Error("Expected the ratchet to fail")
+	}
+	if len(result.Regressions) != 2 {
+		t.Fatalf("Expected 2 regressions, got %+v", result.Regressions)
+	}
+
+	f := result.Regressions[0]
+	if f.DemangledName != "f()" || f.BaseCoveredLines != 2 || f.NewCoveredLines != 1 {
+		t.Errorf("Unexpected regression for f(): %+v", f)
+	}
+	if !reflect.DeepEqual(f.LostLineNumbers, []int{2}) {
+		t.Errorf("Expected lost line [2], got %v", f.LostLineNumbers)
+	}
+
+	h := result.Regressions[1]
+	if h.DemangledName != "h()" || !h.Missing {
+		t.Errorf("Expected h() to be reported missing, got %+v", h)
+	}
+
+	if !result.Improved() || len(result.Improvements) != 2 {
+		t.Fatalf("Expected 2 improvements, got %+v", result.Improvements)
+	}
+	if result.Improvements[0].DemangledName != "g()" || result.Improvements[1].DemangledName != "n()" {
+		t.Errorf("Expected g() and n() to improve, got %+v", result.Improvements)
+	}
+}
+
+func TestComputeRatchet_Unchanged(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{
+				FilePath:  "demo.cc",
+				Lines:     []Line{{LineNumber: 1, FunctionName: "_Z1fv", Count: 1}},
+				Functions: []Function{{Name: "_Z1fv", DemangledName: "f()"}},
+			},
+		},
+	}
+
+	result, err := ComputeRatchet(report, report)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.Passed() || result.Improved() {
+		t.Errorf("Expected an unchanged report to pass without improvements, got %+v", result)
+	}
+}
+
+func TestFormatRatchetReport(t *testing.T) {
+	report := &RatchetReport{
+		Regressions: []FunctionRatchet{
+			{File: "demo.cc", DemangledName: "f()", BaseCoveredLines: 3, NewCoveredLines: 1, LostLineNumbers: []int{6, 7}},
+			{File: "demo.cc", DemangledName: "h()", BaseCoveredLines: 2, Missing: true},
+		},
+		Improvements: []FunctionRatchet{
+			{File: "demo.cc", DemangledName: "g()", BaseCoveredLines: 0, NewCoveredLines: 3},
+		},
+	}
+
+	output := FormatRatchetReport(report)
+	for _, expected := range []string{
+		"Coverage Ratchet Report",
+		"Found 2 function(s) with decreased coverage",
+		"Covered Lines: 3 -> 1",
+		"No Longer Covered Line Numbers: [6 7]",
+		"Missing from the new report (baseline covered 2 lines)",
+		"Found 1 function(s) with increased coverage",
+		"Covered Lines: 0 -> 3",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	if passed := FormatRatchetReport(&RatchetReport{}); !strings.Contains(passed, "No coverage regressions") {
+		t.Errorf("Expected no-regression message, got %q", passed)
+	}
+}
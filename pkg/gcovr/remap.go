@@ -0,0 +1,172 @@
+package gcovr
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// LineMapper maps line numbers of the old side of a file diff to the new side
+type LineMapper struct {
+	hunks []DiffHunk
+}
+
+// NewLineMapper creates a LineMapper for one file of a unified diff
+func NewLineMapper(diff FileDiff) *LineMapper {
+	hunks := append([]DiffHunk{}, diff.Hunks...)
+	sort.Slice(hunks, func(i, j int) bool {
+		return hunks[i].OldStart < hunks[j].OldStart
+	})
+	return &LineMapper{hunks: hunks}
+}
+
+// Map returns the new line number of an old line. Lines removed or modified
+// by the diff have no new line number and return false.
+func (m *LineMapper) Map(oldLine int) (int, bool) {
+	delta := 0
+	for _, hunk := range m.hunks {
+		// A hunk without old lines inserts after OldStart
+		first := hunk.OldStart
+		if hunk.OldCount == 0 {
+			first++
+		}
+
+		if oldLine < first {
+			break
+		}
+		if oldLine >= first+hunk.OldCount {
+			delta += hunk.NewCount - hunk.OldCount
+			continue
+		}
+
+		o, n := hunk.OldStart, hunk.NewStart
+		for i := 0; i < len(hunk.Ops); i++ {
+			switch hunk.Ops[i] {
+			case ' ':
+				if o == oldLine {
+					return n, true
+				}
+				o++
+				n++
+			case '-':
+				if o == oldLine {
+					return 0, false
+				}
+				o++
+			case '+':
+				n++
+			}
+		}
+		return 0, false
+	}
+	return oldLine + delta, true
+}
+
+// RemapReport moves the line numbers of a report taken at an old revision
+// onto a newer revision using the unified diff between the two. Lines removed
+// or modified by the diff are dropped, since their old coverage says nothing
+// about the new code; files renamed by the diff take their new path. Files
+// the diff does not touch are copied unchanged.
+func RemapReport(report *GcovrReport, diffs []FileDiff) *GcovrReport {
+	result := &GcovrReport{
+		FormatVersion: report.FormatVersion,
+		Files:         make([]File, 0, len(report.Files)),
+	}
+
+	for _, file := range report.Files {
+		diff := findFileDiff(diffs, file.FilePath)
+		if diff == nil {
+			result.Files = append(result.Files, file)
+			continue
+		}
+		if diff.NewPath == "" {
+			continue // Deleted in the new revision
+		}
+
+		mapper := NewLineMapper(*diff)
+		remapped := File{
+			FilePath:  renamedReportPath(file.FilePath, diff.OldPath, diff.NewPath),
+			Lines:     make([]Line, 0, len(file.Lines)),
+			Functions: make([]Function, 0, len(file.Functions)),
+		}
+
+		for _, line := range file.Lines {
+			newLine, ok := mapper.Map(line.LineNumber)
+			if !ok {
+				continue
+			}
+			line.LineNumber = newLine
+			remapped.Lines = append(remapped.Lines, line)
+		}
+
+		for _, fn := range file.Functions {
+			// Keep functions whose declaration line changed; their name still identifies them
+			if newLine, ok := mapper.Map(fn.LineNo); ok {
+				fn.LineNo = newLine
+			}
+			remapped.Functions = append(remapped.Functions, fn)
+		}
+
+		result.Files = append(result.Files, remapped)
+	}
+
+	return result
+}
+
+// findFileDiff returns the file diff whose old path shares the most trailing
+// path components with a report path, or nil
+func findFileDiff(diffs []FileDiff, filePath string) *FileDiff {
+	fileParts := strings.Split(normalizeFilePath(filePath), "/")
+
+	var best *FileDiff
+	bestLen := 0
+	for i := range diffs {
+		if diffs[i].OldPath == "" {
+			continue // Added in the new revision
+		}
+		n := pathSuffixLength(fileParts, strings.Split(normalizeFilePath(diffs[i].OldPath), "/"))
+		if n > bestLen {
+			best, bestLen = &diffs[i], n
+		}
+	}
+	return best
+}
+
+// renamedReportPath replaces the old diff path at the end of a report path with the new one
+func renamedReportPath(filePath, oldPath, newPath string) string {
+	if oldPath == newPath {
+		return filePath
+	}
+
+	normalized := normalizeFilePath(filePath)
+	oldPath = normalizeFilePath(oldPath)
+	if normalized == oldPath {
+		return newPath
+	}
+	if strings.HasSuffix(normalized, "/"+oldPath) {
+		return strings.TrimSuffix(normalized, oldPath) + newPath
+	}
+	// The report path is a suffix of the diff path; keep the new file name
+	return newPath
+}
+
+// GitDiff runs "git diff" between two revisions in a repository and parses the result.
+// Prefixes and repository-relative paths are passed explicitly so that user
+// settings such as diff.noprefix, diff.mnemonicPrefix and diff.relative do
+// not change the headers ParseUnifiedDiff expects.
+func GitDiff(repoDir, baseRev, newRev string) ([]FileDiff, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "--find-renames",
+		"--src-prefix=a/", "--dst-prefix=b/", "--no-relative", baseRev, newRev)
+	cmd.Dir = repoDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git diff %s %s failed: %w: %s", baseRev, newRev, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return ParseUnifiedDiff(&stdout)
+}
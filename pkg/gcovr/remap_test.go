@@ -0,0 +1,215 @@
+package gcovr
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLineMapper(t *testing.T) {
+	// Old lines 1-3 unchanged, 4 modified, 5 unchanged, two lines inserted
+	// after old line 8, old line 12 removed
+	diff := `--- a/demo.cc
++++ b/demo.cc
+@@ -3,3 +3,3 @@
+ three
+-four
++FOUR
+ five
+@@ -8,0 +9,2 @@
++new1
++new2
+@@ -12 +13,0 @@
+-twelve
+`
+
+	diffs, err := ParseUnifiedDiff(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("Failed to parse diff: %v", err)
+	}
+	mapper := NewLineMapper(diffs[0])
+
+	tests := []struct {
+		old      int
+		expected int
+		ok       bool
+	}{
+		{old: 1, expected: 1, ok: true},
+		{old: 3, expected: 3, ok: true},
+		{old: 4, ok: false},
+		{old: 5, expected: 5, ok: true},
+		{old: 8, expected: 8, ok: true},
+		{old: 9, expected: 11, ok: true},
+		{old: 11, expected: 13, ok: true},
+		{old: 12, ok: false},
+		{old: 13, expected: 14, ok: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := mapper.Map(tt.old)
+		if ok != tt.ok || (ok && got != tt.expected) {
+			t.Errorf("Map(%d): expected (%d, %v), got (%d, %v)", tt.old, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestRemapReport(t *testing.T) {
+	report := &GcovrReport{
+		FormatVersion: "0.14",
+		Files: []File{
+			{
+				FilePath: "../src/old_name.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+					{LineNumber: 2, FunctionName: "_Z1fv", Count: 0},
+					{LineNumber: 3, FunctionName: "_Z1fv", Count: 1},
+				},
+				Functions: []Function{{Name: "_Z1fv", LineNo: 1}},
+			},
+			{FilePath: "../src/untouched.cc", Lines: []Line{{LineNumber: 7, Count: 1}}},
+			{FilePath: "../src/deleted.cc", Lines: []Line{{LineNumber: 1, Count: 1}}},
+		},
+	}
+
+	diffs := []FileDiff{
+		{OldPath: "src/old_name.cc", NewPath: "src/new_name.cc", Hunks: []DiffHunk{
+			{OldStart: 1, OldCount: 2, NewStart: 1, NewCount: 2, Ops: "+ -"},
+		}},
+		{OldPath: "src/deleted.cc"},
+	}
+
+	result := RemapReport(report, diffs)
+
+	if len(result.Files) != 2 {
+		t.Fatalf("Expected the deleted file to be dropped, got %d files", len(result.Files))
+	}
+
+	renamed := result.Files[0]
+	if renamed.FilePath != "../src/new_name.cc" {
+		t.Errorf("Expected renamed path ../src/new_name.cc, got %s", renamed.FilePath)
+	}
+
+	var lines []int
+	for _, line := range renamed.Lines {
+		lines = append(lines, line.LineNumber)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 3}) {
+		t.Errorf("Expected lines [2 3] (line 2 removed), got %v", lines)
+	}
+	if renamed.Functions[0].LineNo != 2 {
+		t.Errorf("Expected function line 2, got %d", renamed.Functions[0].LineNo)
+	}
+
+	if result.Files[1].Lines[0].LineNumber != 7 {
+		t.Error("Expected untouched files to be copied unchanged")
+	}
+	if report.Files[0].Lines[0].LineNumber != 1 {
+		t.Error("Expected the input report to be left unmodified")
+	}
+}
+
+func TestRemapReport_CoverageIncrease(t *testing.T) {
+	// Two lines were inserted at the top, shifting covered lines 1-2 to 3-4
+	base := &GcovrReport{
+		Files: []File{{
+			FilePath: "demo.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+				{LineNumber: 2, FunctionName: "_Z1fv", Count: 1},
+			},
+			Functions: []Function{{Name: "_Z1fv", DemangledName: "f()"}},
+		}},
+	}
+	newReport := &GcovrReport{
+		Files: []File{{
+			FilePath: "demo.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+				{LineNumber: 2, FunctionName: "_Z1fv", Count: 0},
+				{LineNumber: 3, FunctionName: "_Z1fv", Count: 1},
+				{LineNumber: 4, FunctionName: "_Z1fv", Count: 1},
+			},
+			Functions: []Function{{Name: "_Z1fv", DemangledName: "f()"}},
+		}},
+	}
+	diffs := []FileDiff{{OldPath: "demo.cc", NewPath: "demo.cc", Hunks: []DiffHunk{
+		{OldStart: 0, OldCount: 0, NewStart: 1, NewCount: 2, Ops: "++"},
+	}}}
+
+	plain, _ := ComputeCoverageIncrease(base, newReport)
+	if len(plain.Increases) != 1 || len(plain.Increases[0].IncreasedLineNumbers) != 2 {
+		t.Fatalf("Expected the plain diff to misreport shifted lines, got %+v", plain.Increases)
+	}
+
+	remapped, err := ComputeCoverageIncrease(RemapReport(base, diffs), newReport)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(remapped.Increases) != 1 || !reflect.DeepEqual(remapped.Increases[0].IncreasedLineNumbers, []int{1}) {
+		t.Errorf("Expected only the inserted line 1 to be newly covered, got %+v", remapped.Increases)
+	}
+}
+
+func TestGitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	// A directory named like a diff prefix catches prefixes being stripped twice
+	if err := os.Mkdir(filepath.Join(dir, "b"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "b", "demo.cc"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write demo.cc: %v", err)
+		}
+	}
+
+	run("init", "-q")
+	write("a\nb\nc\n")
+	run("add", "b/demo.cc")
+	run("commit", "-q", "-m", "base")
+	baseRev := run("rev-parse", "HEAD")
+
+	write("new\na\nb\nc\n")
+	run("commit", "-q", "-am", "new")
+
+	// User settings that change the diff headers must not affect parsing
+	run("config", "diff.noprefix", "true")
+	run("config", "diff.mnemonicPrefix", "true")
+	run("config", "diff.relative", "true")
+
+	// Run from a subdirectory so that diff.relative would shorten the paths
+	diffs, err := GitDiff(filepath.Join(dir, "b"), baseRev, "HEAD")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].OldPath != "b/demo.cc" || diffs[0].NewPath != "b/demo.cc" {
+		t.Fatalf("Expected a diff of demo.cc, got %+v", diffs)
+	}
+	if got, ok := NewLineMapper(diffs[0]).Map(3); !ok || got != 4 {
+		t.Errorf("Expected old line 3 to map to 4, got %d (%v)", got, ok)
+	}
+
+	if _, err := GitDiff(dir, "no-such-rev", "HEAD"); err == nil {
+		t.Error("Expected error for an unknown revision")
+	}
+}
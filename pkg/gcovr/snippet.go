@@ -0,0 +1,239 @@
+package gcovr
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SnippetLine is one source line of a snippet
+type SnippetLine struct {
+	Number  int
+	Text    string
+	InRange bool // Whether the line is one the snippet is about rather than context
+}
+
+// SourceSnippet is the source text of one or more nearby line ranges with
+// some lines of context around them
+type SourceSnippet struct {
+	File   string
+	Ranges []LineRange
+	Lines  []SnippetLine
+}
+
+// SourceReader reads source files for snippets and caches them. Files are
+// looked up under the source root unless their report path is absolute.
+type SourceReader struct {
+	root   string
+	files  map[string][]string
+	errors map[string]error
+	warned map[string]bool // Files whose read error was returned as a warning
+}
+
+// NewSourceReader creates a SourceReader for the sources under sourceRoot;
+// an empty root resolves report paths against the working directory
+func NewSourceReader(sourceRoot string) *SourceReader {
+	return &SourceReader{
+		root:   sourceRoot,
+		files:  make(map[string][]string),
+		errors: make(map[string]error),
+		warned: make(map[string]bool),
+	}
+}
+
+// Lines returns the lines of a source file, without line terminators
+func (r *SourceReader) Lines(filePath string) ([]string, error) {
+	if lines, ok := r.files[filePath]; ok {
+		return lines, nil
+	}
+	if err, ok := r.errors[filePath]; ok {
+		return nil, err
+	}
+
+	lines, err := readSourceLines(resolveSourcePath(r.root, filePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = fmt.Errorf("source file %s not found", filePath)
+		} else {
+			err = fmt.Errorf("failed to read source of %s: %w", filePath, err)
+		}
+		r.errors[filePath] = err
+		return nil, err
+	}
+	r.files[filePath] = lines
+	return lines, nil
+}
+
+// readWarnings appends the read error of a file to warnings unless it was
+// reported before
+func (r *SourceReader) readWarnings(warnings []string, filePath string, err error) []string {
+	if r.warned[filePath] {
+		return warnings
+	}
+	r.warned[filePath] = true
+	return append(warnings, err.Error())
+}
+
+// Snippets returns the source of the given ranges of a file with context
+// lines before and after each. Ranges whose context overlaps or touches are
+// merged into one snippet. Lines past the end of the file are ignored.
+func (r *SourceReader) Snippets(filePath string, ranges []LineRange, context int) ([]SourceSnippet, error) {
+	lines, err := r.Lines(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if context < 0 {
+		context = 0
+	}
+
+	snippets := make([]SourceSnippet, 0)
+	for _, lr := range CompactLineRanges(expandLineRanges(ranges)) {
+		if lr.Start > len(lines) {
+			break
+		}
+		start := max(1, lr.Start-context)
+		end := min(len(lines), lr.End+context)
+
+		if n := len(snippets); n > 0 {
+			last := &snippets[n-1]
+			if lastLine := last.Lines[len(last.Lines)-1].Number; start <= lastLine+1 {
+				last.Ranges = append(last.Ranges, lr)
+				for i := start; i <= end; i++ {
+					if i > lastLine {
+						last.Lines = append(last.Lines, SnippetLine{Number: i, Text: lines[i-1]})
+					}
+					if lr.Contains(i) {
+						last.Lines[i-last.Lines[0].Number].InRange = true
+					}
+				}
+				continue
+			}
+		}
+
+		snippet := SourceSnippet{File: filePath, Ranges: []LineRange{lr}, Lines: make([]SnippetLine, 0, end-start+1)}
+		for i := start; i <= end; i++ {
+			snippet.Lines = append(snippet.Lines, SnippetLine{Number: i, Text: lines[i-1], InRange: lr.Contains(i)})
+		}
+		snippets = append(snippets, snippet)
+	}
+
+	return snippets, nil
+}
+
+// expandLineRanges lists every line of the given ranges
+func expandLineRanges(ranges []LineRange) []int {
+	lines := make([]int, 0)
+	for _, r := range ranges {
+		for i := max(1, r.Start); i <= r.End; i++ {
+			lines = append(lines, i)
+		}
+	}
+	return lines
+}
+
+// VerifyHashes compares the gcovr/md5 hash of every line of a report with
+// the source on disk and returns a warning for every file that differs or
+// cannot be read. Lines without a hash are not checked.
+func (r *SourceReader) VerifyHashes(report *GcovrReport) []string {
+	warnings := make([]string, 0)
+	for _, file := range report.Files {
+		hashed := false
+		for _, line := range file.Lines {
+			if line.MD5 != "" {
+				hashed = true
+				break
+			}
+		}
+		if !hashed {
+			continue
+		}
+
+		lines, err := r.Lines(file.FilePath)
+		if err != nil {
+			warnings = r.readWarnings(warnings, file.FilePath, err)
+			continue
+		}
+
+		mismatched := make([]int, 0)
+		for _, line := range file.Lines {
+			if line.MD5 == "" || line.LineNumber < 1 {
+				continue
+			}
+			if line.LineNumber > len(lines) || hashSourceLine(lines[line.LineNumber-1]) != line.MD5 {
+				mismatched = append(mismatched, line.LineNumber)
+			}
+		}
+		if len(mismatched) > 0 {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s: source differs from the report on %d line(s) (%s); snippets may not match the coverage",
+				file.FilePath, len(mismatched), FormatLineRanges(CompactLineRanges(mismatched))))
+		}
+	}
+	return warnings
+}
+
+// hashSourceLine returns the gcovr/md5 hash of a source line
+func hashSourceLine(text string) string {
+	sum := md5.Sum([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// AddUncoveredSnippets attaches the source of the uncovered ranges of every
+// function to the report. Files that cannot be read are skipped and returned
+// as warnings, once per reader.
+func (r *SourceReader) AddUncoveredSnippets(report *UncoveredReport, context int) []string {
+	warnings := make([]string, 0)
+	for i := range report.Files {
+		file := &report.Files[i]
+		for j := range file.UncoveredFunctions {
+			fn := &file.UncoveredFunctions[j]
+			snippets, err := r.Snippets(file.FilePath, CompactLineRanges(fn.UncoveredLineNumbers), context)
+			if err != nil {
+				warnings = r.readWarnings(warnings, file.FilePath, err)
+				break
+			}
+			fn.Snippets = snippets
+		}
+	}
+	return warnings
+}
+
+// AddIncreaseSnippets attaches the source of the newly covered ranges of
+// every function to the report. Files that cannot be read are skipped and
+// returned as warnings, once per reader.
+func (r *SourceReader) AddIncreaseSnippets(report *CoverageIncreaseReport, context int) []string {
+	warnings := make([]string, 0)
+	for i := range report.Increases {
+		inc := &report.Increases[i]
+		snippets, err := r.Snippets(inc.File, CompactLineRanges(inc.IncreasedLineNumbers), context)
+		if err != nil {
+			warnings = r.readWarnings(warnings, inc.File, err)
+			continue
+		}
+		inc.Snippets = snippets
+	}
+	return warnings
+}
+
+// formatSnippets formats source snippets, marking the lines in range with
+// ">" and separating snippets with "..."
+func formatSnippets(snippets []SourceSnippet, indent string) string {
+	result := ""
+	for i, snippet := range snippets {
+		if i > 0 {
+			result += indent + "...\n"
+		}
+		width := len(fmt.Sprint(snippet.Lines[len(snippet.Lines)-1].Number))
+		for _, line := range snippet.Lines {
+			marker := " "
+			if line.InRange {
+				marker = ">"
+			}
+			text := fmt.Sprintf("%s%s %*d | %s", indent, marker, width, line.Number, line.Text)
+			result += strings.TrimRight(text, " \t") + "\n"
+		}
+	}
+	return result
+}
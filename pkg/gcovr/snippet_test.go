@@ -0,0 +1,121 @@
+package gcovr
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeSource writes a source file with the given lines below a temporary root
+func writeSource(t *testing.T, name string, lines []string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, name), []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write source: %v", err)
+	}
+	return root
+}
+
+func TestSourceReaderSnippets(t *testing.T) {
+	lines := make([]string, 12)
+	for i := range lines {
+		lines[i] = "line " + string(rune('a'+i))
+	}
+	reader := NewSourceReader(writeSource(t, "demo.cc", lines))
+
+	snippets, err := reader.Snippets("demo.cc", []LineRange{{Start: 2, End: 3}, {Start: 5, End: 5}, {Start: 11, End: 14}}, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(snippets) != 2 {
+		t.Fatalf("Expected 2 snippets, got %+v", snippets)
+	}
+	// 2-3 and 5 share their context line 4 and are merged
+	if !reflect.DeepEqual(snippets[0].Ranges, []LineRange{{Start: 2, End: 3}, {Start: 5, End: 5}}) {
+		t.Errorf("Unexpected ranges: %+v", snippets[0].Ranges)
+	}
+	first := snippets[0].Lines
+	if first[0].Number != 1 || first[len(first)-1].Number != 6 || first[0].InRange || !first[1].InRange || first[3].InRange {
+		t.Errorf("Unexpected snippet lines: %+v", first)
+	}
+	// Lines past the end of the file are dropped
+	second := snippets[1].Lines
+	if second[0].Number != 10 || second[len(second)-1].Number != 12 || second[0].Text != "line j" {
+		t.Errorf("Unexpected snippet lines: %+v", second)
+	}
+
+	if _, err := reader.Snippets("missing.cc", []LineRange{{Start: 1, End: 1}}, 0); err == nil {
+		t.Error("Expected an error for a missing source file")
+	}
+}
+
+func TestSourceReaderVerifyHashes(t *testing.T) {
+	root := writeSource(t, "demo.cc", []string{"void f() {", "  return;", "}"})
+	report := &GcovrReport{Files: []File{{
+		FilePath: "demo.cc",
+		Lines: []Line{
+			{LineNumber: 1, MD5: "c82822a1761e7f0f209f36b0e346eb0f"}, // "void f() {"
+			{LineNumber: 3, MD5: hashSourceLine("}")},
+		},
+	}}}
+
+	if warnings := NewSourceReader(root).VerifyHashes(report); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+
+	report.Files[0].Lines = append(report.Files[0].Lines, Line{LineNumber: 2, MD5: hashSourceLine("  return 1;")})
+	warnings := NewSourceReader(root).VerifyHashes(report)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "demo.cc: source differs from the report on 1 line(s) (2)") {
+		t.Errorf("Expected a warning for line 2, got %v", warnings)
+	}
+}
+
+func TestAddUncoveredSnippets(t *testing.T) {
+	root := writeSource(t, "test.cpp", []string{"int foo() {", "  if (x)", "    return 1;", "  return 0;", "}"})
+	report := &UncoveredReport{Files: []FileUncovered{{
+		FilePath: "test.cpp",
+		UncoveredFunctions: []FunctionUncovered{{
+			FunctionName:         "_Z3foov",
+			DemangledName:        "foo()",
+			UncoveredLineNumbers: []int{3},
+			TotalLines:           5,
+			CoveredLines:         4,
+		}},
+	}}}
+
+	reader := NewSourceReader(root)
+	if warnings := reader.AddUncoveredSnippets(report, 1); len(warnings) != 0 {
+		t.Fatalf("Unexpected warnings: %v", warnings)
+	}
+
+	output := FormatUncoveredReport(report)
+	expected := "   Uncovered Lines (1): 3\n" +
+		"        2 |   if (x)\n" +
+		"      > 3 |     return 1;\n" +
+		"        4 |   return 0;\n\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain:\n%s\ngot:\n%s", expected, output)
+	}
+}
+
+func TestAddIncreaseSnippetsMissingSource(t *testing.T) {
+	report := &CoverageIncreaseReport{Increases: []FunctionCoverageIncrease{
+		{File: "gone.cc", FunctionName: "_Z1fv", IncreasedLineNumbers: []int{1}},
+		{File: "gone.cc", FunctionName: "_Z1gv", IncreasedLineNumbers: []int{5}},
+	}}
+
+	reader := NewSourceReader(t.TempDir())
+	warnings := reader.AddIncreaseSnippets(report, 2)
+	if len(warnings) != 1 || warnings[0] != "source file gone.cc not found" {
+		t.Errorf("Expected one warning for gone.cc, got %v", warnings)
+	}
+	if warnings := reader.AddIncreaseSnippets(report, 2); len(warnings) != 0 {
+		t.Errorf("Expected the warning to be reported once, got %v", warnings)
+	}
+	if report.Increases[0].Snippets != nil {
+		t.Errorf("Expected no snippets, got %+v", report.Increases[0].Snippets)
+	}
+}
@@ -0,0 +1,306 @@
+package gcovr
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Summary sort orders
+const (
+	SummarySortName      = "name"      // By path or function name
+	SummarySortCoverage  = "coverage"  // Lowest line coverage first
+	SummarySortUncovered = "uncovered" // Most uncovered lines first
+)
+
+// CoverageTotals holds the line, function and branch coverage of a scope
+type CoverageTotals struct {
+	Lines     CoverageCount
+	Functions CoverageCount // A function is covered when it was called
+	Branches  CoverageCount
+}
+
+// add adds the totals of a nested scope
+func (t *CoverageTotals) add(other CoverageTotals) {
+	t.Lines.Covered += other.Lines.Covered
+	t.Lines.Total += other.Lines.Total
+	t.Functions.Covered += other.Functions.Covered
+	t.Functions.Total += other.Functions.Total
+	t.Branches.Covered += other.Branches.Covered
+	t.Branches.Total += other.Branches.Total
+}
+
+// FunctionSummary is the coverage of one function
+type FunctionSummary struct {
+	FunctionName   string // Mangled name
+	DemangledName  string
+	LineNo         int // Declaration line, or the first line of the function without a record
+	ExecutionCount int
+	Lines          CoverageCount
+	Branches       CoverageCount
+}
+
+// FileSummary is the coverage of one file and its functions
+type FileSummary struct {
+	FilePath  string
+	Totals    CoverageTotals
+	Functions []FunctionSummary
+}
+
+// DirectorySummary is the coverage of every file below a directory
+type DirectorySummary struct {
+	Path   string // Slash-separated, e.g. "gcc/config"
+	Depth  int    // Number of path components, e.g. 2 for "gcc/config"
+	Files  int    // Number of files below the directory
+	Totals CoverageTotals
+}
+
+// SummaryReport holds coverage totals at global, directory, file and function level
+type SummaryReport struct {
+	Totals      CoverageTotals
+	Directories []DirectorySummary // Every directory holding a file, and their parents
+	Files       []FileSummary
+}
+
+// Summarize computes the line, function and branch coverage of a report as a
+// whole, of every directory (including the files of its subdirectories), of
+// every file and of every function. Functions are counted from the report's
+// function records, plus any function only named by its lines. The result
+// is sorted by path; use Sort for another order.
+func Summarize(report *GcovrReport) *SummaryReport {
+	result := &SummaryReport{
+		Directories: make([]DirectorySummary, 0),
+		Files:       make([]FileSummary, 0, len(report.Files)),
+	}
+	dirs := make(map[string]*DirectorySummary)
+
+	for _, file := range report.Files {
+		summary := summarizeFile(file)
+		result.Files = append(result.Files, summary)
+		result.Totals.add(summary.Totals)
+
+		for _, dir := range parentDirectories(file.FilePath) {
+			d, ok := dirs[dir]
+			if !ok {
+				d = &DirectorySummary{Path: dir, Depth: strings.Count(strings.TrimPrefix(dir, "/"), "/") + 1}
+				dirs[dir] = d
+			}
+			d.Files++
+			d.Totals.add(summary.Totals)
+		}
+	}
+
+	for _, d := range dirs {
+		result.Directories = append(result.Directories, *d)
+	}
+
+	result.Sort(SummarySortName)
+	return result
+}
+
+// summarizeFile computes the coverage of one file and its functions
+func summarizeFile(file File) FileSummary {
+	summary := FileSummary{FilePath: file.FilePath, Functions: make([]FunctionSummary, 0)}
+	summary.Totals.Lines.Covered, summary.Totals.Lines.Total = countLineCoverage(file.Lines)
+	summary.Totals.Branches.Covered, summary.Totals.Branches.Total = countBranchCoverage(file.Lines)
+
+	funcLines := groupLinesByFunction(file.Lines)
+	seen := make(map[string]bool)
+	addFunction := func(name, demangledName string, lineNo, executionCount int) {
+		fn := FunctionSummary{
+			FunctionName:   name,
+			DemangledName:  functionDisplayName(name, demangledName),
+			LineNo:         lineNo,
+			ExecutionCount: executionCount,
+		}
+		fn.Lines.Covered, fn.Lines.Total = countLineCoverage(funcLines[name])
+		fn.Branches.Covered, fn.Branches.Total = countBranchCoverage(funcLines[name])
+		if fn.ExecutionCount == 0 && fn.Lines.Covered > 0 {
+			fn.ExecutionCount = 1 // Known to have run although no record says so
+		}
+
+		summary.Functions = append(summary.Functions, fn)
+		summary.Totals.Functions.Total++
+		if fn.ExecutionCount > 0 {
+			summary.Totals.Functions.Covered++
+		}
+		seen[name] = true
+	}
+
+	for _, fn := range file.Functions {
+		if !seen[fn.Name] {
+			addFunction(fn.Name, fn.DemangledName, fn.LineNo, fn.ExecutionCount)
+		}
+	}
+	for _, line := range file.Lines {
+		if line.FunctionName != "" && !seen[line.FunctionName] {
+			addFunction(line.FunctionName, "", line.LineNumber, 0)
+		}
+	}
+
+	return summary
+}
+
+// parentDirectories returns the directories holding a file, innermost last,
+// e.g. "gcc", "gcc/config" for "gcc/config/i386.cc"
+func parentDirectories(filePath string) []string {
+	dirs := make([]string, 0)
+	for dir := path.Dir(filepath.ToSlash(filePath)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		dirs = append(dirs, dir)
+	}
+	for i, j := 0, len(dirs)-1; i < j; i, j = i+1, j-1 {
+		dirs[i], dirs[j] = dirs[j], dirs[i]
+	}
+	return dirs
+}
+
+// ValidateSummarySort checks a summary sort order
+func ValidateSummarySort(by string) error {
+	switch by {
+	case SummarySortName, SummarySortCoverage, SummarySortUncovered:
+		return nil
+	}
+	return fmt.Errorf("unknown sort order %q (expected %s, %s or %s)",
+		by, SummarySortName, SummarySortCoverage, SummarySortUncovered)
+}
+
+// Sort orders the directories, the files and the functions of each file by
+// name, by line coverage (lowest first) or by uncovered lines (most first).
+// Ties are broken by name. Unknown orders leave the report unchanged.
+func (r *SummaryReport) Sort(by string) {
+	if ValidateSummarySort(by) != nil {
+		return
+	}
+
+	sort.Slice(r.Directories, func(i, j int) bool {
+		a, b := &r.Directories[i], &r.Directories[j]
+		return summaryLess(by, a.Totals.Lines, b.Totals.Lines, a.Path, b.Path)
+	})
+	sort.Slice(r.Files, func(i, j int) bool {
+		a, b := &r.Files[i], &r.Files[j]
+		return summaryLess(by, a.Totals.Lines, b.Totals.Lines, a.FilePath, b.FilePath)
+	})
+	for k := range r.Files {
+		functions := r.Files[k].Functions
+		sort.Slice(functions, func(i, j int) bool {
+			a, b := &functions[i], &functions[j]
+			return summaryLess(by, a.Lines, b.Lines, a.DemangledName, b.DemangledName)
+		})
+	}
+}
+
+// summaryLess compares two scopes by their line coverage, then by name
+func summaryLess(by string, a, b CoverageCount, nameA, nameB string) bool {
+	switch by {
+	case SummarySortCoverage:
+		if a.Percent() != b.Percent() {
+			return a.Percent() < b.Percent()
+		}
+	case SummarySortUncovered:
+		if ua, ub := a.Total-a.Covered, b.Total-b.Covered; ua != ub {
+			return ua > ub
+		}
+	}
+	return nameA < nameB
+}
+
+// formatTotals formats line, function and branch coverage on one line
+func formatTotals(t CoverageTotals) string {
+	return fmt.Sprintf("lines %d/%d (%.1f%%), functions %d/%d (%.1f%%), branches %d/%d (%.1f%%)",
+		t.Lines.Covered, t.Lines.Total, t.Lines.Percent(),
+		t.Functions.Covered, t.Functions.Total, t.Functions.Percent(),
+		t.Branches.Covered, t.Branches.Total, t.Branches.Percent())
+}
+
+// FormatSummaryReport formats the summary as a human-readable string. The
+// functions of each file are listed with showFunctions.
+func FormatSummaryReport(report *SummaryReport, showFunctions bool) string {
+	result := fmt.Sprintf("Coverage Summary\n")
+	result += fmt.Sprintf("================\n\n")
+
+	t := report.Totals
+	result += fmt.Sprintf("Lines:     %d/%d (%.1f%%)\n", t.Lines.Covered, t.Lines.Total, t.Lines.Percent())
+	result += fmt.Sprintf("Functions: %d/%d (%.1f%%)\n", t.Functions.Covered, t.Functions.Total, t.Functions.Percent())
+	result += fmt.Sprintf("Branches:  %d/%d (%.1f%%)\n\n", t.Branches.Covered, t.Branches.Total, t.Branches.Percent())
+
+	if len(report.Directories) > 0 {
+		result += fmt.Sprintf("Directories (%d):\n", len(report.Directories))
+		for _, d := range report.Directories {
+			result += fmt.Sprintf("   %s/: %s\n", d.Path, formatTotals(d.Totals))
+		}
+		result += "\n"
+	}
+
+	result += fmt.Sprintf("Files (%d):\n", len(report.Files))
+	for _, f := range report.Files {
+		result += fmt.Sprintf("   %s: %s\n", f.FilePath, formatTotals(f.Totals))
+		if !showFunctions {
+			continue
+		}
+		for _, fn := range f.Functions {
+			result += fmt.Sprintf("      %s: lines %d/%d (%.1f%%), branches %d/%d (%.1f%%)",
+				fn.DemangledName, fn.Lines.Covered, fn.Lines.Total, fn.Lines.Percent(),
+				fn.Branches.Covered, fn.Branches.Total, fn.Branches.Percent())
+			if fn.ExecutionCount == 0 {
+				result += " [never called]"
+			}
+			result += "\n"
+		}
+	}
+
+	return result
+}
+
+// FormatSummaryTree formats the directory summaries as an indented tree,
+// each directory under its parent, down to depth directory levels (all
+// levels when depth <= 0). Siblings keep the order of report.Directories.
+func FormatSummaryTree(report *SummaryReport, depth int) string {
+	result := fmt.Sprintf("Coverage Tree\n")
+	result += fmt.Sprintf("=============\n\n")
+
+	children := make(map[string][]int) // Parent path ("" for top level) -> indexes into report.Directories
+	for i, d := range report.Directories {
+		parent := path.Dir(d.Path)
+		if parent == "." || parent == "/" {
+			parent = ""
+		}
+		children[parent] = append(children[parent], i)
+	}
+
+	type row struct {
+		name   string
+		totals CoverageTotals
+	}
+	rows := []row{{name: "(all)", totals: report.Totals}}
+
+	var walk func(parent string, level int)
+	walk = func(parent string, level int) {
+		if depth > 0 && level > depth {
+			return
+		}
+		for _, i := range children[parent] {
+			d := report.Directories[i]
+			name := path.Base(d.Path) + "/"
+			if parent == "" {
+				name = d.Path + "/"
+			}
+			rows = append(rows, row{name: strings.Repeat("   ", level) + name, totals: d.Totals})
+			walk(d.Path, level+1)
+		}
+	}
+	walk("", 1)
+
+	width := 0
+	for _, r := range rows {
+		if len(r.name) > width {
+			width = len(r.name)
+		}
+	}
+	for _, r := range rows {
+		result += fmt.Sprintf("%-*s  %s\n", width, r.name, formatTotals(r.totals))
+	}
+
+	return result
+}
@@ -0,0 +1,218 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// summaryReport builds a report with files in nested directories
+func summaryReport() *GcovrReport {
+	return &GcovrReport{Files: []File{
+		{
+			FilePath: "gcc/config/i386/i386.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "_Z1av", Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+				{LineNumber: 2, FunctionName: "_Z1av", Count: 0},
+				{LineNumber: 5, FunctionName: "_Z1bv", Count: 0},
+				{LineNumber: 6, FunctionName: "_Z1bv", Count: 0},
+			},
+			Functions: []Function{
+				{Name: "_Z1av", DemangledName: "a()", ExecutionCount: 3},
+				{Name: "_Z1bv", DemangledName: "b()"},
+			},
+		},
+		{
+			FilePath: "gcc/tree.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "_Z1cv", Count: 2},
+				{LineNumber: 2, FunctionName: "_Z1cv", Count: 2},
+			},
+		},
+		{
+			FilePath: "main.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "main", Count: 0},
+			},
+			Functions: []Function{{Name: "main", DemangledName: "main"}},
+		},
+	}}
+}
+
+func TestSummarize(t *testing.T) {
+	summary := Summarize(summaryReport())
+
+	expected := CoverageTotals{
+		Lines:     CoverageCount{Covered: 3, Total: 7},
+		Functions: CoverageCount{Covered: 2, Total: 4},
+		Branches:  CoverageCount{Covered: 1, Total: 2},
+	}
+	if summary.Totals != expected {
+		t.Errorf("Expected totals %+v, got %+v", expected, summary.Totals)
+	}
+
+	dirs := make([]string, 0)
+	for _, d := range summary.Directories {
+		dirs = append(dirs, d.Path)
+	}
+	if !reflect.DeepEqual(dirs, []string{"gcc", "gcc/config", "gcc/config/i386"}) {
+		t.Errorf("Unexpected directories: %v", dirs)
+	}
+	gcc := summary.Directories[0]
+	if gcc.Depth != 1 || gcc.Files != 2 || gcc.Totals.Lines != (CoverageCount{Covered: 3, Total: 6}) {
+		t.Errorf("Unexpected gcc/ summary: %+v", gcc)
+	}
+	if summary.Directories[2].Depth != 3 {
+		t.Errorf("Expected gcc/config/i386 at depth 3, got %d", summary.Directories[2].Depth)
+	}
+
+	// Functions without records are taken from the lines and count as
+	// called when one of their lines ran
+	tree := summary.Files[1]
+	if tree.FilePath != "gcc/tree.cc" || tree.Totals.Functions != (CoverageCount{Covered: 1, Total: 1}) {
+		t.Errorf("Unexpected gcc/tree.cc summary: %+v", tree)
+	}
+	if tree.Functions[0].DemangledName != "c()" || tree.Functions[0].ExecutionCount != 1 {
+		t.Errorf("Unexpected function summary: %+v", tree.Functions[0])
+	}
+}
+
+func TestSummarySort(t *testing.T) {
+	report := &GcovrReport{Files: []File{
+		{
+			FilePath: "lib/parse.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "_Z1av", Count: 1},
+				{LineNumber: 2, FunctionName: "_Z1av", Count: 0},
+				{LineNumber: 5, FunctionName: "_Z1bv", Count: 0},
+				{LineNumber: 6, FunctionName: "_Z1bv", Count: 0},
+			},
+			Functions: []Function{
+				{Name: "_Z1av", DemangledName: "a()", ExecutionCount: 1},
+				{Name: "_Z1bv", DemangledName: "b()"},
+			},
+		},
+		{
+			FilePath: "lib/util/str.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "_Z1cv", Count: 1},
+			},
+		},
+		{
+			FilePath: "main.cc",
+			Lines: []Line{
+				{LineNumber: 1, FunctionName: "main", Count: 0},
+			},
+		},
+	}}
+
+	tests := []struct {
+		by        string
+		files     []string
+		dirs      []string
+		functions []string // Functions of lib/parse.cc
+	}{
+		{
+			by:        SummarySortName,
+			files:     []string{"lib/parse.cc", "lib/util/str.cc", "main.cc"},
+			dirs:      []string{"lib", "lib/util"},
+			functions: []string{"a()", "b()"},
+		},
+		{
+			by:        SummarySortCoverage,
+			files:     []string{"main.cc", "lib/parse.cc", "lib/util/str.cc"},
+			dirs:      []string{"lib", "lib/util"},
+			functions: []string{"b()", "a()"},
+		},
+		{
+			by:        SummarySortUncovered,
+			files:     []string{"lib/parse.cc", "main.cc", "lib/util/str.cc"},
+			dirs:      []string{"lib", "lib/util"},
+			functions: []string{"b()", "a()"},
+		},
+		{
+			by:        "size", // Unknown orders leave the report sorted by name
+			files:     []string{"lib/parse.cc", "lib/util/str.cc", "main.cc"},
+			dirs:      []string{"lib", "lib/util"},
+			functions: []string{"a()", "b()"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			summary := Summarize(report)
+			summary.Sort(tt.by)
+
+			files := make([]string, 0)
+			for _, f := range summary.Files {
+				files = append(files, f.FilePath)
+			}
+			dirs := make([]string, 0)
+			for _, d := range summary.Directories {
+				dirs = append(dirs, d.Path)
+			}
+			functions := make([]string, 0)
+			for _, f := range summary.Files {
+				if f.FilePath != "lib/parse.cc" {
+					continue
+				}
+				for _, fn := range f.Functions {
+					functions = append(functions, fn.DemangledName)
+				}
+			}
+
+			if !reflect.DeepEqual(files, tt.files) {
+				t.Errorf("Expected files %v, got %v", tt.files, files)
+			}
+			if !reflect.DeepEqual(dirs, tt.dirs) {
+				t.Errorf("Expected directories %v, got %v", tt.dirs, dirs)
+			}
+			if !reflect.DeepEqual(functions, tt.functions) {
+				t.Errorf("Expected functions %v, got %v", tt.functions, functions)
+			}
+		})
+	}
+
+	if err := ValidateSummarySort("size"); err == nil {
+		t.Error("Expected an error for an unknown sort order")
+	}
+}
+
+func TestFormatSummaryReport(t *testing.T) {
+	output := FormatSummaryReport(Summarize(summaryReport()), true)
+
+	for _, want := range []string{
+		"Lines:     3/7 (42.9%)",
+		"Functions: 2/4 (50.0%)",
+		"   gcc/config/: lines 1/4 (25.0%), functions 1/2 (50.0%), branches 1/2 (50.0%)",
+		"   main.cc: lines 0/1 (0.0%)",
+		"      b(): lines 0/2 (0.0%), branches 0/0 (100.0%) [never called]",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestFormatSummaryTree(t *testing.T) {
+	summary := Summarize(summaryReport())
+
+	output := FormatSummaryTree(summary, 0)
+	expected := []string{
+		"(all)           lines 3/7 (42.9%)",
+		"   gcc/         lines 3/6 (50.0%)",
+		"      config/   lines 1/4 (25.0%)",
+		"         i386/  lines 1/4 (25.0%)",
+	}
+	lines := strings.Split(output, "\n")[3:]
+	for i, want := range expected {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("Expected line %d to start with %q, got %q", i, want, lines[i])
+		}
+	}
+
+	output = FormatSummaryTree(summary, 2)
+	if !strings.Contains(output, "config/") || strings.Contains(output, "i386/") {
+		t.Errorf("Expected the tree to stop at depth 2:\n%s", output)
+	}
+}
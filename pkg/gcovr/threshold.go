@@ -0,0 +1,323 @@
+package gcovr
+
+import (
+	"fmt"
+)
+
+// Thresholds holds the minimum coverage percentages checked by CheckThresholds.
+// A threshold of 0 is not checked.
+type Thresholds struct {
+	Line           float64             `yaml:"line"`            // Global line coverage
+	Branch         float64             `yaml:"branch"`          // Global branch coverage
+	FunctionLine   float64             `yaml:"function_line"`   // Line coverage of every function in the report
+	FunctionBranch float64             `yaml:"function_branch"` // Branch coverage of every function in the report
+	Files          []FileThreshold     `yaml:"files"`
+	Functions      []FunctionThreshold `yaml:"functions"`
+
+	// AllowUnmatched only warns about file and function thresholds that match
+	// nothing in the report instead of failing them
+	AllowUnmatched bool `yaml:"allow_unmatched"`
+}
+
+// FileThreshold sets the minimum coverage of one file
+type FileThreshold struct {
+	File   string  `yaml:"file"`
+	Line   float64 `yaml:"line"`
+	Branch float64 `yaml:"branch"`
+}
+
+// FunctionThreshold sets the minimum coverage of one function. The function
+// name is matched like a filter target function; File narrows it to one file.
+type FunctionThreshold struct {
+	File     string  `yaml:"file"`
+	Function string  `yaml:"function"`
+	Line     float64 `yaml:"line"`
+	Branch   float64 `yaml:"branch"`
+}
+
+// Threshold scopes and metrics reported in a ThresholdResult
+const (
+	ScopeGlobal   = "global"
+	ScopeFile     = "file"
+	ScopeFunction = "function"
+
+	MetricLine   = "line"
+	MetricBranch = "branch"
+)
+
+// ThresholdResult is the outcome of checking one threshold
+type ThresholdResult struct {
+	Scope     string // ScopeGlobal, ScopeFile or ScopeFunction
+	File      string
+	Function  string // Demangled name for function thresholds
+	Metric    string // MetricLine or MetricBranch
+	Covered   int
+	Total     int
+	Required  float64
+	Passed    bool
+	Unmatched bool // The threshold matched nothing in the report; File and Function are as configured
+}
+
+// Percent returns the measured coverage percentage; nothing to cover counts as 100%
+func (r ThresholdResult) Percent() float64 {
+	if r.Total == 0 {
+		return 100.0
+	}
+	return float64(r.Covered) * 100.0 / float64(r.Total)
+}
+
+// ThresholdReport contains the results of every checked threshold
+type ThresholdReport struct {
+	Results  []ThresholdResult
+	Warnings []string // Unmatched file and function thresholds allowed by AllowUnmatched
+}
+
+// Failed returns the thresholds that were not met
+func (r *ThresholdReport) Failed() []ThresholdResult {
+	failed := make([]ThresholdResult, 0)
+	for _, result := range r.Results {
+		if !result.Passed {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// validate checks that every threshold is a percentage
+func (t *Thresholds) validate() error {
+	if t == nil {
+		return nil
+	}
+
+	check := func(what string, values ...float64) error {
+		for _, v := range values {
+			if v < 0 || v > 100 {
+				return fmt.Errorf("threshold %v for %s is not between 0 and 100", v, what)
+			}
+		}
+		return nil
+	}
+
+	if err := check("global coverage", t.Line, t.Branch); err != nil {
+		return err
+	}
+	if err := check("function coverage", t.FunctionLine, t.FunctionBranch); err != nil {
+		return err
+	}
+	for _, ft := range t.Files {
+		if err := check(fmt.Sprintf("file %q", ft.File), ft.Line, ft.Branch); err != nil {
+			return err
+		}
+	}
+	for _, ft := range t.Functions {
+		if ft.Function == "" {
+			return fmt.Errorf("function threshold without a function name")
+		}
+		if err := check(fmt.Sprintf("function %q", ft.Function), ft.Line, ft.Branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckThresholds evaluates coverage thresholds against a report. The report
+// should already be filtered when only target files and functions count.
+// A file or function threshold that matches nothing fails, unless
+// AllowUnmatched is set, in which case it is only reported as a warning.
+func CheckThresholds(report *GcovrReport, thresholds *Thresholds, match string) (*ThresholdReport, error) {
+	result := &ThresholdReport{
+		Results:  make([]ThresholdResult, 0),
+		Warnings: make([]string, 0),
+	}
+	if thresholds == nil {
+		return result, nil
+	}
+	if err := thresholds.validate(); err != nil {
+		return nil, err
+	}
+	if err := validateMatchMode(match); err != nil {
+		return nil, err
+	}
+
+	// Global thresholds
+	allLines := make([]Line, 0)
+	for _, file := range report.Files {
+		allLines = append(allLines, file.Lines...)
+	}
+	result.check(ScopeGlobal, "", "", allLines, thresholds.Line, thresholds.Branch)
+
+	// Per-file thresholds
+	for _, ft := range thresholds.Files {
+		filterMap := map[string]int{normalizeFilePath(ft.File): 0}
+		found := false
+		for _, file := range report.Files {
+			if _, ok := resolveTargetFile(file.FilePath, filterMap, match); !ok {
+				continue
+			}
+			found = true
+			result.check(ScopeFile, file.FilePath, "", file.Lines, ft.Line, ft.Branch)
+		}
+		if !found {
+			result.unmatched(ScopeFile, ft.File, "", ft.Line, ft.Branch, thresholds.AllowUnmatched,
+				fmt.Sprintf("file threshold %q matched no files in the report", ft.File))
+		}
+	}
+
+	// Per-function thresholds
+	for _, file := range report.Files {
+		funcLines := groupLinesByFunction(file.Lines)
+		for _, fn := range file.Functions {
+			result.check(ScopeFunction, file.FilePath, functionDisplayName(fn.Name, fn.DemangledName),
+				funcLines[fn.Name], thresholds.FunctionLine, thresholds.FunctionBranch)
+		}
+	}
+
+	for _, ft := range thresholds.Functions {
+		var filterMap map[string]int
+		if ft.File != "" {
+			filterMap = map[string]int{normalizeFilePath(ft.File): 0}
+		}
+
+		found := false
+		for _, file := range report.Files {
+			if filterMap != nil {
+				if _, ok := resolveTargetFile(file.FilePath, filterMap, match); !ok {
+					continue
+				}
+			}
+
+			funcLines := groupLinesByFunction(file.Lines)
+			for _, fn := range file.Functions {
+				if !functionMatchesName(fn.DemangledName, fn.Name, ft.Function) {
+					continue
+				}
+				found = true
+				result.check(ScopeFunction, file.FilePath, functionDisplayName(fn.Name, fn.DemangledName),
+					funcLines[fn.Name], ft.Line, ft.Branch)
+			}
+		}
+		if !found {
+			result.unmatched(ScopeFunction, ft.File, ft.Function, ft.Line, ft.Branch, thresholds.AllowUnmatched,
+				fmt.Sprintf("function threshold %q matched no functions in the report", ft.Function))
+		}
+	}
+
+	return result, nil
+}
+
+// check records line and branch threshold results for a set of lines
+func (r *ThresholdReport) check(scope, file, function string, lines []Line, lineMin, branchMin float64) {
+	if lineMin > 0 {
+		covered, total := countLineCoverage(lines)
+		r.add(ThresholdResult{Scope: scope, File: file, Function: function, Metric: MetricLine,
+			Covered: covered, Total: total, Required: lineMin})
+	}
+	if branchMin > 0 {
+		covered, total := countBranchCoverage(lines)
+		r.add(ThresholdResult{Scope: scope, File: file, Function: function, Metric: MetricBranch,
+			Covered: covered, Total: total, Required: branchMin})
+	}
+}
+
+// unmatched records a failed result for every metric of a threshold that
+// matched nothing, or only a warning when allowed or when no metric is set
+func (r *ThresholdReport) unmatched(scope, file, function string, lineMin, branchMin float64, allow bool, warning string) {
+	if allow || (lineMin <= 0 && branchMin <= 0) {
+		r.Warnings = append(r.Warnings, warning)
+		return
+	}
+	if lineMin > 0 {
+		r.Results = append(r.Results, ThresholdResult{Scope: scope, File: file, Function: function,
+			Metric: MetricLine, Required: lineMin, Unmatched: true})
+	}
+	if branchMin > 0 {
+		r.Results = append(r.Results, ThresholdResult{Scope: scope, File: file, Function: function,
+			Metric: MetricBranch, Required: branchMin, Unmatched: true})
+	}
+}
+
+// add records a result, deciding whether it passed
+func (r *ThresholdReport) add(result ThresholdResult) {
+	result.Passed = result.Percent() >= result.Required
+	r.Results = append(r.Results, result)
+}
+
+// groupLinesByFunction groups a file's lines by their function name
+func groupLinesByFunction(lines []Line) map[string][]Line {
+	grouped := make(map[string][]Line)
+	for _, line := range lines {
+		grouped[line.FunctionName] = append(grouped[line.FunctionName], line)
+	}
+	return grouped
+}
+
+// countLineCoverage returns the number of executed lines and the total number of lines
+func countLineCoverage(lines []Line) (covered, total int) {
+	for _, line := range lines {
+		total++
+		if line.Count > 0 {
+			covered++
+		}
+	}
+	return covered, total
+}
+
+// countBranchCoverage returns the number of taken branches and the total number of branches
+func countBranchCoverage(lines []Line) (covered, total int) {
+	for _, line := range lines {
+		for _, branch := range line.Branches {
+			total++
+			if branch.Count > 0 {
+				covered++
+			}
+		}
+	}
+	return covered, total
+}
+
+// FormatThresholdReport formats the threshold check results as a human-readable string
+func FormatThresholdReport(report *ThresholdReport) string {
+	if len(report.Results) == 0 {
+		return "No coverage thresholds defined.\n"
+	}
+
+	failed := report.Failed()
+
+	result := fmt.Sprintf("Coverage Threshold Check\n")
+	result += fmt.Sprintf("========================\n\n")
+
+	for _, r := range report.Results {
+		status := "PASS"
+		if !r.Passed {
+			status = "FAIL"
+		}
+
+		target := "global"
+		switch r.Scope {
+		case ScopeFile:
+			target = fmt.Sprintf("file %s", r.File)
+		case ScopeFunction:
+			target = fmt.Sprintf("function %s", r.Function)
+			if r.File != "" {
+				target += fmt.Sprintf(" (%s)", r.File)
+			}
+		}
+
+		if r.Unmatched {
+			result += fmt.Sprintf("[%s] %s %s coverage: matched nothing in the report, required %.1f%%\n",
+				status, target, r.Metric, r.Required)
+			continue
+		}
+		result += fmt.Sprintf("[%s] %s %s coverage: %.1f%% (%d/%d), required %.1f%%\n",
+			status, target, r.Metric, r.Percent(), r.Covered, r.Total, r.Required)
+	}
+
+	result += "\n"
+	if len(failed) == 0 {
+		result += fmt.Sprintf("All %d threshold(s) met.\n", len(report.Results))
+	} else {
+		result += fmt.Sprintf("%d of %d threshold(s) failed.\n", len(failed), len(report.Results))
+	}
+
+	return result
+}
@@ -0,0 +1,218 @@
+package gcovr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	demo := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "src/demo.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1fv", Count: 1, Branches: []Branch{{Count: 1}, {Count: 0}}},
+					{LineNumber: 2, FunctionName: "_Z1fv", Count: 1},
+					{LineNumber: 5, FunctionName: "_Z1gv", Count: 0, Branches: []Branch{{Count: 0}, {Count: 0}}},
+					{LineNumber: 6, FunctionName: "_Z1gv", Count: 1},
+				},
+				Functions: []Function{
+					{Name: "_Z1fv", DemangledName: "f()"},
+					{Name: "_Z1gv", DemangledName: "g()"},
+				},
+			},
+			{
+				FilePath: "src/other.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1hv", Count: 0},
+				},
+				Functions: []Function{
+					{Name: "_Z1hv", DemangledName: "h()"},
+				},
+			},
+		},
+	}
+
+	type result struct {
+		scope     string
+		target    string // File for file thresholds, function for function thresholds
+		metric    string
+		covered   int
+		total     int
+		passed    bool
+		unmatched bool
+	}
+
+	tests := []struct {
+		name       string
+		report     *GcovrReport
+		thresholds *Thresholds
+		expected   []result
+		warnings   int
+		wantErr    bool
+	}{
+		{
+			name:   "Global, file and function scopes",
+			report: demo,
+			thresholds: &Thresholds{
+				Line:      60,
+				Branch:    20,
+				Files:     []FileThreshold{{File: "demo.cc", Line: 75}},
+				Functions: []FunctionThreshold{{Function: "g", Line: 50, Branch: 50}},
+			},
+			expected: []result{
+				{scope: ScopeGlobal, metric: MetricLine, covered: 3, total: 5, passed: true},
+				{scope: ScopeGlobal, metric: MetricBranch, covered: 1, total: 4, passed: true},
+				{scope: ScopeFile, target: "src/demo.cc", metric: MetricLine, covered: 3, total: 4, passed: true},
+				{scope: ScopeFunction, target: "g()", metric: MetricLine, covered: 1, total: 2, passed: true},
+				{scope: ScopeFunction, target: "g()", metric: MetricBranch, covered: 0, total: 2, passed: false},
+			},
+		},
+		{
+			name:       "Every function",
+			report:     demo,
+			thresholds: &Thresholds{FunctionLine: 50},
+			expected: []result{
+				{scope: ScopeFunction, target: "f()", metric: MetricLine, covered: 2, total: 2, passed: true},
+				{scope: ScopeFunction, target: "g()", metric: MetricLine, covered: 1, total: 2, passed: true},
+				{scope: ScopeFunction, target: "h()", metric: MetricLine, covered: 0, total: 1, passed: false},
+			},
+		},
+		{
+			name:   "Unmatched thresholds fail",
+			report: demo,
+			thresholds: &Thresholds{
+				Files:     []FileThreshold{{File: "missing.cc", Line: 50, Branch: 10}},
+				Functions: []FunctionThreshold{{File: "other.cc", Function: "f", Line: 50}},
+			},
+			expected: []result{
+				{scope: ScopeFile, target: "missing.cc", metric: MetricLine, unmatched: true},
+				{scope: ScopeFile, target: "missing.cc", metric: MetricBranch, unmatched: true},
+				{scope: ScopeFunction, target: "f", metric: MetricLine, unmatched: true},
+			},
+		},
+		{
+			name:   "Unmatched thresholds allowed",
+			report: demo,
+			thresholds: &Thresholds{
+				Files:          []FileThreshold{{File: "missing.cc", Line: 50}},
+				Functions:      []FunctionThreshold{{File: "other.cc", Function: "f", Line: 50}},
+				AllowUnmatched: true,
+			},
+			expected: []result{},
+			warnings: 2,
+		},
+		{
+			name:       "No branches",
+			report:     &GcovrReport{Files: []File{{FilePath: "a.cc", Lines: []Line{{LineNumber: 1, Count: 1}}}}},
+			thresholds: &Thresholds{Branch: 90},
+			expected: []result{
+				{scope: ScopeGlobal, metric: MetricBranch, passed: true},
+			},
+		},
+		{
+			name:       "Threshold out of range",
+			report:     demo,
+			thresholds: &Thresholds{Line: -5},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := CheckThresholds(tt.report, tt.thresholds, "")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(report.Results) != len(tt.expected) {
+				t.Fatalf("Expected %d results, got %d: %+v", len(tt.expected), len(report.Results), report.Results)
+			}
+			for i, e := range tt.expected {
+				r := report.Results[i]
+				target := r.File
+				if r.Scope == ScopeFunction {
+					target = r.Function
+				}
+				got := result{scope: r.Scope, target: target, metric: r.Metric, covered: r.Covered,
+					total: r.Total, passed: r.Passed, unmatched: r.Unmatched}
+				if got != e {
+					t.Errorf("Result %d: expected %+v, got %+v", i, e, got)
+				}
+			}
+			if len(report.Warnings) != tt.warnings {
+				t.Errorf("Expected %d warnings, got %v", tt.warnings, report.Warnings)
+			}
+		})
+	}
+}
+
+func TestThresholds_Validate(t *testing.T) {
+	valid := &Thresholds{Line: 80, Files: []FileThreshold{{File: "a.cc", Branch: 100}}}
+	if err := valid.validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	invalid := []*Thresholds{
+		{Line: 101},
+		{FunctionBranch: -1},
+		{Files: []FileThreshold{{File: "a.cc", Line: 150}}},
+		{Functions: []FunctionThreshold{{Line: 50}}},
+	}
+	for _, th := range invalid {
+		if err := th.validate(); err == nil {
+			t.Errorf("Expected error for %+v", th)
+		}
+	}
+
+	var none *Thresholds
+	if err := none.validate(); err != nil {
+		t.Errorf("Expected nil thresholds to be valid, got %v", err)
+	}
+}
+
+func TestFormatThresholdReport(t *testing.T) {
+	report := &GcovrReport{
+		Files: []File{
+			{
+				FilePath: "demo.cc",
+				Lines: []Line{
+					{LineNumber: 1, FunctionName: "_Z1fv", Count: 1},
+					{LineNumber: 2, FunctionName: "_Z1fv", Count: 0},
+				},
+				Functions: []Function{{Name: "_Z1fv", DemangledName: "f()"}},
+			},
+		},
+	}
+	thresholds := &Thresholds{
+		Line:      90,
+		Functions: []FunctionThreshold{{Function: "g", Line: 50}},
+	}
+
+	result, err := CheckThresholds(report, thresholds, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	output := FormatThresholdReport(result)
+
+	for _, expected := range []string{
+		"Coverage Threshold Check",
+		"[FAIL] global line coverage: 50.0% (1/2), required 90.0%",
+		"[FAIL] function g line coverage: matched nothing in the report, required 50.0%",
+		"2 of 2 threshold(s) failed.",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	if empty := FormatThresholdReport(&ThresholdReport{}); !strings.Contains(empty, "No coverage thresholds") {
+		t.Errorf("Expected empty message, got %q", empty)
+	}
+}
@@ -15,9 +15,30 @@ type File struct {
 
 // Line represents a single line of code with coverage information
 type Line struct {
-	LineNumber   int    `json:"line_number"`
-	FunctionName string `json:"function_name"`
-	Count        int    `json:"count"`
+	LineNumber   int      `json:"line_number"`
+	FunctionName string   `json:"function_name"`
+	Count        int      `json:"count"`
+	Branches     []Branch `json:"branches"`
+	BlockIDs     []int    `json:"block_ids,omitempty"` // Basic blocks starting on the line
+	Calls        []Call   `json:"calls,omitempty"`
+	MD5          string   `json:"gcovr/md5,omitempty"` // Hash of the line's source text
+}
+
+// Call represents a call site recorded on a line. gcovr does not record the
+// called function, only the blocks the call leaves and returns to.
+type Call struct {
+	SourceBlockID      int `json:"source_block_id"`
+	DestinationBlockID int `json:"destination_block_id"`
+	Returned           int `json:"returned"` // Number of times the call returned
+}
+
+// Branch represents a single branch outcome recorded on a line
+type Branch struct {
+	Count              int  `json:"count"`
+	Fallthrough        bool `json:"fallthrough"`
+	Throw              bool `json:"throw"`
+	SourceBlockID      int  `json:"source_block_id"`
+	DestinationBlockID int  `json:"destination_block_id"`
 }
 
 // Function represents a function in the source code
@@ -38,22 +59,29 @@ type FunctionCoverageIncrease struct {
 	LinesIncreased       int
 	TotalLines           int
 	IncreasedLineNumbers []int
-	OldCoveredLines      int // Number of lines covered in base report
-	NewCoveredLines      int // Number of lines covered in new report
+	IncreasedRanges      []LineRange     // IncreasedLineNumbers as ranges of consecutive lines
+	OldCoveredLines      int             // Number of lines covered in base report
+	NewCoveredLines      int             // Number of lines covered in new report
+	PreviousName         string          // Demangled base name when matched under another mangled name
+	Snippets             []SourceSnippet // Source of the newly covered lines, if added with a SourceReader
 }
 
 // CoverageIncreaseReport contains all coverage increases between two reports
 type CoverageIncreaseReport struct {
 	Increases []FunctionCoverageIncrease
+	Renames   []FunctionRename // Functions matched across reports despite a changed mangled name
 }
 
 // FunctionUncovered represents the uncovered lines within a single function
 type FunctionUncovered struct {
 	FunctionName         string // Mangled name
 	DemangledName        string
+	LineNo               int // First line of the function in the report
 	UncoveredLineNumbers []int
+	UncoveredRanges      []LineRange // UncoveredLineNumbers as ranges of consecutive lines
 	TotalLines           int
 	CoveredLines         int
+	Snippets             []SourceSnippet // Source of the uncovered lines, if added with a SourceReader
 }
 
 // FileUncovered represents all uncovered functions within a single file
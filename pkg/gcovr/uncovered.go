@@ -6,7 +6,8 @@ import (
 )
 
 // FindUncoveredLines analyzes a gcovr report and returns all uncovered lines
-// grouped by file and function
+// grouped by file and function. Files are sorted by path and the functions of
+// each file by their first line; use Sort for another order.
 func FindUncoveredLines(report *GcovrReport) (*UncoveredReport, error) {
 	result := &UncoveredReport{
 		Files: make([]FileUncovered, 0),
@@ -78,9 +79,10 @@ func FindUncoveredLines(report *GcovrReport) (*UncoveredReport, error) {
 				continue
 			}
 
-			// Calculate total lines and covered lines for this function
+			// Calculate total lines, covered lines and the first line of this function
 			totalLines := 0
 			coveredLines := 0
+			firstLine := 0
 
 			for _, line := range fileObj.Lines {
 				if line.FunctionName == funcName {
@@ -88,14 +90,14 @@ func FindUncoveredLines(report *GcovrReport) (*UncoveredReport, error) {
 					if line.Count > 0 {
 						coveredLines++
 					}
+					if firstLine == 0 || line.LineNumber < firstLine {
+						firstLine = line.LineNumber
+					}
 				}
 			}
 
 			// Get demangled name
-			demangledName := funcMetadata[filePath][funcName]
-			if demangledName == "" {
-				demangledName = funcName
-			}
+			demangledName := functionDisplayName(funcName, funcMetadata[filePath][funcName])
 
 			// Sort line numbers for consistent output
 			sort.Ints(uncoveredLines)
@@ -103,12 +105,20 @@ func FindUncoveredLines(report *GcovrReport) (*UncoveredReport, error) {
 			fileResult.UncoveredFunctions = append(fileResult.UncoveredFunctions, FunctionUncovered{
 				FunctionName:         funcName,
 				DemangledName:        demangledName,
+				LineNo:               firstLine,
 				UncoveredLineNumbers: uncoveredLines,
+				UncoveredRanges:      CompactLineRanges(uncoveredLines),
 				TotalLines:           totalLines,
 				CoveredLines:         coveredLines,
 			})
 		}
 
+		// List functions in source order
+		sort.Slice(fileResult.UncoveredFunctions, func(i, j int) bool {
+			return uncoveredLess(SortByLine, filePath, filePath,
+				&fileResult.UncoveredFunctions[i], &fileResult.UncoveredFunctions[j])
+		})
+
 		if len(fileResult.UncoveredFunctions) > 0 {
 			result.Files = append(result.Files, fileResult)
 		}
@@ -152,8 +162,14 @@ func FormatUncoveredReport(report *UncoveredReport) string {
 			result += fmt.Sprintf("   Function: %s\n", fn.DemangledName)
 			result += fmt.Sprintf("   Coverage: %d/%d lines (%.1f%%)\n",
 				fn.CoveredLines, fn.TotalLines, coveragePercent)
-			result += fmt.Sprintf("   Uncovered Lines (%d): %v\n\n",
-				len(fn.UncoveredLineNumbers), fn.UncoveredLineNumbers)
+			ranges := fn.UncoveredRanges
+			if ranges == nil {
+				ranges = CompactLineRanges(fn.UncoveredLineNumbers)
+			}
+			result += fmt.Sprintf("   Uncovered Lines (%d): %s\n",
+				len(fn.UncoveredLineNumbers), FormatLineRanges(ranges))
+			result += formatSnippets(fn.Snippets, "      ")
+			result += "\n"
 
 			funcIdx++
 		}
@@ -161,3 +177,115 @@ func FormatUncoveredReport(report *UncoveredReport) string {
 
 	return result
 }
+
+// Function coverage classes
+const (
+	FunctionNeverCalled = "never-called" // Execution count of zero
+	FunctionPartial     = "partial"      // Called, but some lines not covered
+	FunctionFull        = "full"         // Called, with every line covered
+)
+
+// ClassifiedFunction is a function with its coverage class
+type ClassifiedFunction struct {
+	File           string
+	FunctionName   string // Mangled name
+	DemangledName  string
+	LineNo         int
+	ExecutionCount int
+	TotalLines     int
+	CoveredLines   int
+	Class          string
+}
+
+// FunctionClassification splits the functions of a report by coverage class,
+// each list ordered by file and line
+type FunctionClassification struct {
+	NeverCalled []ClassifiedFunction
+	Partial     []ClassifiedFunction
+	Full        []ClassifiedFunction
+}
+
+// ClassifyFunctions sorts every function of a report into never-called,
+// partially covered and fully covered ones. A function is never called when
+// its execution count is zero; a function without a record in the report
+// counts as called when one of its lines ran.
+func ClassifyFunctions(report *GcovrReport) *FunctionClassification {
+	result := &FunctionClassification{
+		NeverCalled: make([]ClassifiedFunction, 0),
+		Partial:     make([]ClassifiedFunction, 0),
+		Full:        make([]ClassifiedFunction, 0),
+	}
+
+	summary := Summarize(report)
+	for _, file := range summary.Files {
+		functions := append([]FunctionSummary(nil), file.Functions...)
+		sort.SliceStable(functions, func(i, j int) bool {
+			if functions[i].LineNo != functions[j].LineNo {
+				return functions[i].LineNo < functions[j].LineNo
+			}
+			return functions[i].DemangledName < functions[j].DemangledName
+		})
+
+		for _, fn := range functions {
+			classified := ClassifiedFunction{
+				File:           file.FilePath,
+				FunctionName:   fn.FunctionName,
+				DemangledName:  fn.DemangledName,
+				LineNo:         fn.LineNo,
+				ExecutionCount: fn.ExecutionCount,
+				TotalLines:     fn.Lines.Total,
+				CoveredLines:   fn.Lines.Covered,
+			}
+			switch {
+			case fn.ExecutionCount == 0:
+				classified.Class = FunctionNeverCalled
+				result.NeverCalled = append(result.NeverCalled, classified)
+			case fn.Lines.Covered < fn.Lines.Total:
+				classified.Class = FunctionPartial
+				result.Partial = append(result.Partial, classified)
+			default:
+				classified.Class = FunctionFull
+				result.Full = append(result.Full, classified)
+			}
+		}
+	}
+
+	return result
+}
+
+// FormatNeverCalledFunctions formats the never-called functions as a
+// compact list, one function per line, listing at most top functions (all
+// when top <= 0). The counts always cover every function.
+func FormatNeverCalledFunctions(classification *FunctionClassification, top int) string {
+	total := len(classification.NeverCalled) + len(classification.Partial) + len(classification.Full)
+
+	result := fmt.Sprintf("Never-Called Functions Report\n")
+	result += fmt.Sprintf("=============================\n\n")
+	result += fmt.Sprintf("Functions: %d never called, %d partially covered, %d fully covered (%d total)\n\n",
+		len(classification.NeverCalled), len(classification.Partial), len(classification.Full), total)
+
+	if len(classification.NeverCalled) == 0 {
+		result += "No never-called functions found. Every function ran!\n"
+		return result
+	}
+
+	lines := 0
+	for _, fn := range classification.NeverCalled {
+		lines += fn.TotalLines
+	}
+	neverCalled := classification.NeverCalled
+	if top > 0 && top < len(neverCalled) {
+		neverCalled = neverCalled[:top]
+		result += fmt.Sprintf("Top %d of %d never-called function(s) (%d total uncovered lines):\n\n",
+			top, len(classification.NeverCalled), lines)
+	} else {
+		result += fmt.Sprintf("Found %d never-called function(s) (%d total uncovered lines):\n\n",
+			len(classification.NeverCalled), lines)
+	}
+
+	for _, fn := range neverCalled {
+		result += fmt.Sprintf("   %s:%d: %s (%d line(s))\n", fn.File, fn.LineNo, fn.DemangledName, fn.TotalLines)
+	}
+
+	return result
+}
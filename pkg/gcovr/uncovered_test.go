@@ -1,6 +1,7 @@
 package gcovr
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -273,7 +274,7 @@ func TestFormatUncoveredReport(t *testing.T) {
 				"File: test.cpp",
 				"Function: foo()",
 				"Coverage: 3/5 lines (60.0%)",
-				"Uncovered Lines (2): [2 3]",
+				"Uncovered Lines (2): 2-3",
 			},
 		},
 		{
@@ -352,3 +353,62 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestClassifyFunctions(t *testing.T) {
+	report := &GcovrReport{Files: []File{{
+		FilePath: "demo.cc",
+		Lines: []Line{
+			{LineNumber: 5, FunctionName: "_Z1fv", Count: 1},
+			{LineNumber: 6, FunctionName: "_Z1fv", Count: 1},
+			{LineNumber: 9, FunctionName: "_Z1gv", Count: 0},
+			{LineNumber: 10, FunctionName: "_Z1gv", Count: 0},
+			{LineNumber: 13, FunctionName: "main", Count: 1},
+			{LineNumber: 14, FunctionName: "main", Count: 0},
+			{LineNumber: 20, FunctionName: "_Z1hv", Count: 0},
+		},
+		Functions: []Function{
+			{Name: "main", DemangledName: "main", LineNo: 13, ExecutionCount: 1},
+			{Name: "_Z1gv", DemangledName: "g()", LineNo: 9},
+			{Name: "_Z1fv", DemangledName: "f()", LineNo: 5, ExecutionCount: 4},
+		},
+	}}}
+
+	classification := ClassifyFunctions(report)
+
+	names := func(functions []ClassifiedFunction) string {
+		result := make([]string, 0)
+		for _, fn := range functions {
+			result = append(result, fn.DemangledName)
+		}
+		return strings.Join(result, ",")
+	}
+	if got := names(classification.NeverCalled); got != "g(),h()" {
+		t.Errorf("Expected g() and h() never called, got %s", got)
+	}
+	if got := names(classification.Partial); got != "main" {
+		t.Errorf("Expected main partially covered, got %s", got)
+	}
+	if got := names(classification.Full); got != "f()" {
+		t.Errorf("Expected f() fully covered, got %s", got)
+	}
+
+	g := classification.NeverCalled[0]
+	if g.Class != FunctionNeverCalled || g.LineNo != 9 || g.TotalLines != 2 {
+		t.Errorf("Unexpected classification of g(): %+v", g)
+	}
+	// Functions without a record start at their first line
+	if h := classification.NeverCalled[1]; h.LineNo != 20 {
+		t.Errorf("Expected h() at line 20, got %d", h.LineNo)
+	}
+
+	output := FormatNeverCalledFunctions(classification, 0)
+	for _, want := range []string{
+		"Functions: 2 never called, 1 partially covered, 1 fully covered (4 total)",
+		"Found 2 never-called function(s) (3 total uncovered lines):",
+		"   demo.cc:9: g() (2 line(s))\n   demo.cc:20: h() (1 line(s))\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q:\n%s", want, output)
+		}
+	}
+}
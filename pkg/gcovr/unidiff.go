@@ -0,0 +1,187 @@
+package gcovr
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DiffHunk is one "@@ -a,b +c,d @@" section of a unified diff
+type DiffHunk struct {
+	OldStart int
+	OldCount int
+	NewStart int
+	NewCount int
+	Ops      string // One of ' ', '-' or '+' per hunk line
+}
+
+// FileDiff holds the hunks of one file in a unified diff
+type FileDiff struct {
+	OldPath string // Empty for added files
+	NewPath string // Empty for deleted files
+	Hunks   []DiffHunk
+}
+
+// AddedLines returns the new-side line numbers of every added or modified line
+func (d *FileDiff) AddedLines() []int {
+	added := make([]int, 0)
+	for _, hunk := range d.Hunks {
+		newLine := hunk.NewStart
+		for i := 0; i < len(hunk.Ops); i++ {
+			switch hunk.Ops[i] {
+			case '+':
+				added = append(added, newLine)
+				newLine++
+			case ' ':
+				newLine++
+			}
+		}
+	}
+	return added
+}
+
+// ParseUnifiedDiff parses unified diff output such as "git diff" or "diff -u".
+// The "a/" and "b/" prefixes git adds to paths are removed.
+func ParseUnifiedDiff(r io.Reader) ([]FileDiff, error) {
+	diffs := make([]FileDiff, 0)
+	var current *FileDiff
+	var hunk *DiffHunk
+	oldLeft, newLeft := 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		text := scanner.Text()
+
+		// Inside a hunk, lines are consumed until both sides are complete
+		if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+			if text == "" {
+				// Some tools strip the trailing space of empty context lines
+				text = " "
+			}
+			switch text[0] {
+			case ' ':
+				oldLeft--
+				newLeft--
+			case '-':
+				oldLeft--
+			case '+':
+				newLeft--
+			case '\\':
+				continue // "\ No newline at end of file"
+			default:
+				return nil, fmt.Errorf("line %d: unexpected line in hunk: %q", lineNum, text)
+			}
+			if oldLeft < 0 || newLeft < 0 {
+				return nil, fmt.Errorf("line %d: hunk is longer than its header", lineNum)
+			}
+			hunk.Ops += text[:1]
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			diffs = append(diffs, FileDiff{Hunks: make([]DiffHunk, 0)})
+			current = &diffs[len(diffs)-1]
+			hunk = nil
+			oldPath, newPath := parseGitDiffHeader(text)
+			current.OldPath, current.NewPath = oldPath, newPath
+
+		case strings.HasPrefix(text, "--- "):
+			// A "---" line starts a new file unless it follows a git header
+			if current == nil || len(current.Hunks) > 0 || hunk != nil {
+				diffs = append(diffs, FileDiff{Hunks: make([]DiffHunk, 0)})
+				current = &diffs[len(diffs)-1]
+				hunk = nil
+			}
+			current.OldPath = parseDiffPath(text[4:], "a/")
+
+		case strings.HasPrefix(text, "+++ ") && current != nil:
+			current.NewPath = parseDiffPath(text[4:], "b/")
+
+		case strings.HasPrefix(text, "@@ ") && current != nil:
+			h, err := parseHunkHeader(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			current.Hunks = append(current.Hunks, h)
+			hunk = &current.Hunks[len(current.Hunks)-1]
+			oldLeft, newLeft = h.OldCount, h.NewCount
+
+		case strings.HasPrefix(text, "rename from ") && current != nil:
+			current.OldPath = strings.TrimPrefix(text, "rename from ")
+
+		case strings.HasPrefix(text, "rename to ") && current != nil:
+			current.NewPath = strings.TrimPrefix(text, "rename to ")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if hunk != nil && (oldLeft > 0 || newLeft > 0) {
+		return nil, fmt.Errorf("unexpected end of diff inside a hunk")
+	}
+
+	return diffs, nil
+}
+
+// parseGitDiffHeader extracts the paths from "diff --git a/x b/x"
+func parseGitDiffHeader(text string) (string, string) {
+	rest := strings.TrimPrefix(text, "diff --git ")
+	if idx := strings.Index(rest, " b/"); idx != -1 {
+		return strings.TrimPrefix(rest[:idx], "a/"), rest[idx+3:]
+	}
+	return "", ""
+}
+
+// parseDiffPath extracts the path of a "---" or "+++" line. /dev/null becomes "".
+func parseDiffPath(text, prefix string) string {
+	// Timestamps written by diff -u follow a tab
+	if idx := strings.Index(text, "\t"); idx != -1 {
+		text = text[:idx]
+	}
+	text = strings.TrimSpace(text)
+	if text == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(text, prefix)
+}
+
+// parseHunkHeader parses "@@ -oldStart[,oldCount] +newStart[,newCount] @@"
+func parseHunkHeader(text string) (DiffHunk, error) {
+	fields := strings.Fields(text)
+	if len(fields) < 4 || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return DiffHunk{}, fmt.Errorf("invalid hunk header %q", text)
+	}
+
+	oldStart, oldCount, err := parseHunkRange(fields[1][1:])
+	if err != nil {
+		return DiffHunk{}, fmt.Errorf("invalid hunk header %q: %w", text, err)
+	}
+	newStart, newCount, err := parseHunkRange(fields[2][1:])
+	if err != nil {
+		return DiffHunk{}, fmt.Errorf("invalid hunk header %q: %w", text, err)
+	}
+
+	return DiffHunk{OldStart: oldStart, OldCount: oldCount, NewStart: newStart, NewCount: newCount}, nil
+}
+
+// parseHunkRange parses "start,count"; a missing count means 1
+func parseHunkRange(s string) (int, int, error) {
+	startStr, countStr, hasCount := strings.Cut(s, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countStr); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
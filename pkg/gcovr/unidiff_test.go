@@ -0,0 +1,126 @@
+package gcovr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleGitDiff = `diff --git a/gcc/expr.cc b/gcc/expr.cc
+index 1111111..2222222 100644
+--- a/gcc/expr.cc
++++ b/gcc/expr.cc
+@@ -10,3 +10,4 @@ expand_expr (tree exp)
+ int a;
+-int b;
++int b2;
++int c;
+ int d;
+@@ -30 +31 @@
+-old
++new
+diff --git a/gcc/new.cc b/gcc/new.cc
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/gcc/new.cc
+@@ -0,0 +1,2 @@
++line one
++line two
+\ No newline at end of file
+diff --git a/gcc/old.cc b/gcc/old.cc
+deleted file mode 100644
+--- a/gcc/old.cc
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+`
+
+func TestParseUnifiedDiff(t *testing.T) {
+	diffs, err := ParseUnifiedDiff(strings.NewReader(sampleGitDiff))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(diffs) != 3 {
+		t.Fatalf("Expected 3 file diffs, got %d", len(diffs))
+	}
+
+	expr := diffs[0]
+	if expr.OldPath != "gcc/expr.cc" || expr.NewPath != "gcc/expr.cc" {
+		t.Errorf("Unexpected paths: %q -> %q", expr.OldPath, expr.NewPath)
+	}
+	if len(expr.Hunks) != 2 {
+		t.Fatalf("Expected 2 hunks, got %d", len(expr.Hunks))
+	}
+	if expr.Hunks[0].Ops != " -++ " {
+		t.Errorf("Expected ops %q, got %q", " -++ ", expr.Hunks[0].Ops)
+	}
+	if h := expr.Hunks[1]; h.OldStart != 30 || h.OldCount != 1 || h.NewStart != 31 || h.NewCount != 1 {
+		t.Errorf("Unexpected hunk header: %+v", h)
+	}
+	if got := expr.AddedLines(); !reflect.DeepEqual(got, []int{11, 12, 31}) {
+		t.Errorf("Expected added lines [11 12 31], got %v", got)
+	}
+
+	if diffs[1].OldPath != "" || diffs[1].NewPath != "gcc/new.cc" {
+		t.Errorf("Expected an added file, got %q -> %q", diffs[1].OldPath, diffs[1].NewPath)
+	}
+	if got := diffs[1].AddedLines(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("Expected added lines [1 2], got %v", got)
+	}
+
+	if diffs[2].NewPath != "" {
+		t.Errorf("Expected a deleted file, got %q", diffs[2].NewPath)
+	}
+}
+
+func TestParseUnifiedDiff_RemovedLineLikeHeader(t *testing.T) {
+	diff := `--- a.c
++++ a.c
+@@ -1,2 +1,1 @@
+--- x
+ keep
+`
+
+	diffs, err := ParseUnifiedDiff(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].Hunks[0].Ops != "- " {
+		t.Errorf("Expected a removed line inside the hunk, got %+v", diffs)
+	}
+}
+
+func TestParseUnifiedDiff_PlainDiff(t *testing.T) {
+	diff := "--- demo.cc.orig\t2024-01-01 00:00:00\n+++ demo.cc\t2024-01-02 00:00:00\n@@ -1 +1,2 @@\n a\n+b\n" +
+		"--- other.cc\n+++ other.cc\n@@ -5 +5 @@\n-x\n+y\n"
+
+	diffs, err := ParseUnifiedDiff(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("Expected 2 file diffs, got %d", len(diffs))
+	}
+	if diffs[0].NewPath != "demo.cc" || diffs[0].OldPath != "demo.cc.orig" {
+		t.Errorf("Expected timestamps to be stripped, got %q -> %q", diffs[0].OldPath, diffs[0].NewPath)
+	}
+	if got := diffs[1].AddedLines(); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("Expected added line [5], got %v", got)
+	}
+}
+
+func TestParseUnifiedDiff_Errors(t *testing.T) {
+	invalid := []string{
+		"--- a\n+++ b\n@@ -1,x +1 @@\n",
+		"--- a\n+++ b\n@@ -1,2 +1,2 @@\n a\n",
+		"--- a\n+++ b\n@@ -1 +1 @@\n?what\n",
+	}
+
+	for _, diff := range invalid {
+		if _, err := ParseUnifiedDiff(strings.NewReader(diff)); err == nil {
+			t.Errorf("Expected error for %q", diff)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package gcovr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// WatchEvent describes a report that added coverage to the running union
+type WatchEvent struct {
+	Time          time.Time          `json:"time"`
+	Report        string             `json:"report"`
+	NewLines      int                `json:"new_lines"`
+	NewBranches   int                `json:"new_branches"`
+	TotalLines    int                `json:"total_lines"`
+	TotalBranches int                `json:"total_branches"`
+	Files         []FileContribution `json:"files"`
+}
+
+// WatchOptions controls WatchDirectory
+type WatchOptions struct {
+	Interval     time.Duration // Time between directory scans; defaults to one second
+	Pattern      string        // File name glob; defaults to "*.json"
+	SkipExisting bool          // Ignore reports present when watching starts
+	Once         bool          // Process the reports present now and return
+
+	// Load reads a report; defaults to ParseReport. Use it to apply filters.
+	Load func(path string) (*GcovrReport, error)
+	// OnError is called for reports that cannot be loaded; they are skipped
+	// until they change again
+	OnError func(path string, err error)
+	// Wait is called between scans and returns false to stop watching;
+	// defaults to waiting Interval unless ctx is done first
+	Wait func(ctx context.Context) bool
+}
+
+// fileStamp identifies one version of a file
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// WatchDirectory polls a directory for new reports, adds each to the
+// accumulator and calls handle for every report that covers new lines or
+// branches. A report is read once its size and modification time are the
+// same in two consecutive scans, so files still being written are not read
+// early; a report rewritten later is read again. Reports are processed in
+// modification time order. It returns when ctx is done, when handle returns
+// an error, or after one pass with Once.
+func WatchDirectory(ctx context.Context, dir string, acc *Accumulator, opts WatchOptions, handle func(*WatchEvent) error) error {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Pattern == "" {
+		opts.Pattern = "*.json"
+	}
+	if _, err := filepath.Match(opts.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", opts.Pattern, err)
+	}
+	if opts.Load == nil {
+		opts.Load = ParseReport
+	}
+	if opts.Wait == nil {
+		opts.Wait = func(ctx context.Context) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(opts.Interval):
+				return true
+			}
+		}
+	}
+
+	seen := make(map[string]fileStamp)
+	pending := make(map[string]fileStamp)
+
+	if opts.SkipExisting {
+		stamps, err := scanReportDir(dir, opts.Pattern)
+		if err != nil {
+			return err
+		}
+		for name, stamp := range stamps {
+			seen[name] = stamp
+		}
+	}
+
+	for {
+		stamps, err := scanReportDir(dir, opts.Pattern)
+		if err != nil {
+			return err
+		}
+
+		ready := make([]string, 0)
+		for name, stamp := range stamps {
+			if prev, ok := seen[name]; ok && prev == stamp {
+				continue
+			}
+			if prev, ok := pending[name]; opts.Once || (ok && prev == stamp) {
+				ready = append(ready, name)
+				continue
+			}
+			pending[name] = stamp
+		}
+		sort.Slice(ready, func(i, j int) bool {
+			a, b := stamps[ready[i]], stamps[ready[j]]
+			if !a.modTime.Equal(b.modTime) {
+				return a.modTime.Before(b.modTime)
+			}
+			return ready[i] < ready[j]
+		})
+
+		for _, name := range ready {
+			seen[name] = stamps[name]
+			delete(pending, name)
+
+			if err := watchReport(filepath.Join(dir, name), acc, opts, handle); err != nil {
+				return err
+			}
+		}
+
+		if opts.Once || !opts.Wait(ctx) {
+			return nil
+		}
+	}
+}
+
+// watchReport adds one report to the accumulator and reports its contribution
+func watchReport(path string, acc *Accumulator, opts WatchOptions, handle func(*WatchEvent) error) error {
+	report, err := opts.Load(path)
+	if err == nil {
+		var added *NewCoverage
+		if added, err = acc.Add(report); err == nil {
+			if added.Empty() {
+				return nil
+			}
+			return handle(&WatchEvent{
+				Time:          time.Now(),
+				Report:        path,
+				NewLines:      added.Lines,
+				NewBranches:   added.Branches,
+				TotalLines:    acc.Lines(),
+				TotalBranches: acc.Branches(),
+				Files:         added.Files,
+			})
+		}
+	}
+
+	if opts.OnError != nil {
+		opts.OnError(path, err)
+	}
+	return nil
+}
+
+// scanReportDir returns the stamps of the regular files in dir matching pattern
+func scanReportDir(dir, pattern string) (map[string]fileStamp, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	stamps := make(map[string]fileStamp)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, entry.Name()); !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed since the directory was read
+		}
+		stamps[entry.Name()] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return stamps, nil
+}
+
+// FormatWatchEvent formats a watch event as a single human-readable line
+func FormatWatchEvent(event *WatchEvent) string {
+	return fmt.Sprintf("[%s] %s: +%d line(s), +%d branch(es) (total %d line(s), %d branch(es))\n",
+		event.Time.Format("15:04:05"), event.Report, event.NewLines, event.NewBranches,
+		event.TotalLines, event.TotalBranches)
+}
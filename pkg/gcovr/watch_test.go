@@ -0,0 +1,247 @@
+package gcovr
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// watchedReport builds a report of demo.cc with lines 1-10 of which lines are covered
+func watchedReport(lines ...int) *GcovrReport {
+	covered := make(map[int]bool)
+	for _, l := range lines {
+		covered[l] = true
+	}
+	file := File{FilePath: "demo.cc"}
+	for l := 1; l <= 10; l++ {
+		line := Line{LineNumber: l, FunctionName: "_Z1fv"}
+		if covered[l] {
+			line.Count = 1
+		}
+		file.Lines = append(file.Lines, line)
+	}
+	return &GcovrReport{Files: []File{file}}
+}
+
+// writeWatchedReport writes a report covering lines to dir/name with a fixed modification time
+func writeWatchedReport(t *testing.T, dir, name string, modTime time.Time, lines ...int) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := WriteReport(watchedReport(lines...), path); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+}
+
+// writeWatchedFile writes raw content to dir/name with a fixed modification time
+func writeWatchedFile(t *testing.T, dir, name string, modTime time.Time, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+}
+
+// watchSteps returns a WatchOptions.Wait that runs one step between each pair
+// of scans and stops watching once every step has run
+func watchSteps(steps ...func()) func(context.Context) bool {
+	return func(context.Context) bool {
+		if len(steps) == 0 {
+			return false
+		}
+		steps[0]()
+		steps = steps[1:]
+		return true
+	}
+}
+
+func TestWatchDirectory_Once(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Hour)
+	writeWatchedReport(t, dir, "b.json", start, 1, 2)
+	writeWatchedReport(t, dir, "a.json", start.Add(time.Minute), 2, 3)
+	writeWatchedReport(t, dir, "dup.json", start.Add(2*time.Minute), 1)
+	writeWatchedFile(t, dir, "notes.txt", start, "ignored")
+	writeWatchedFile(t, dir, "broken.json", start, "{")
+
+	var events []*WatchEvent
+	var failed []string
+	acc := NewAccumulator()
+	err := WatchDirectory(context.Background(), dir, acc, WatchOptions{
+		Once:    true,
+		OnError: func(path string, err error) { failed = append(failed, filepath.Base(path)) },
+	}, func(event *WatchEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Reports are processed oldest first; dup.json adds nothing
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if filepath.Base(events[0].Report) != "b.json" || events[0].NewLines != 2 {
+		t.Errorf("Unexpected first event: %+v", events[0])
+	}
+	if filepath.Base(events[1].Report) != "a.json" || events[1].NewLines != 1 || events[1].TotalLines != 3 {
+		t.Errorf("Unexpected second event: %+v", events[1])
+	}
+	if len(failed) != 1 || failed[0] != "broken.json" {
+		t.Errorf("Expected broken.json to fail, got %v", failed)
+	}
+}
+
+func TestWatchDirectory_Scans(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		existing     func(t *testing.T, dir string)
+		steps        func(t *testing.T, dir string) []func()
+		skipExisting bool
+		expected     []string // Report and new lines of each event, e.g. "new.json:2"
+	}{
+		{
+			name: "Existing reports are skipped",
+			existing: func(t *testing.T, dir string) {
+				writeWatchedReport(t, dir, "old.json", start, 1)
+			},
+			steps: func(t *testing.T, dir string) []func() {
+				return []func(){
+					func() { writeWatchedReport(t, dir, "new.json", start.Add(time.Minute), 1, 2) },
+					func() {},
+				}
+			},
+			skipExisting: true,
+			expected:     []string{"new.json:2"}, // old.json was skipped, so line 1 is new as well
+		},
+		{
+			name: "Reports are read once stable",
+			existing: func(t *testing.T, dir string) {
+				writeWatchedFile(t, dir, "seed.json", start, `{"files": [`)
+			},
+			steps: func(t *testing.T, dir string) []func() {
+				return []func(){
+					func() { writeWatchedReport(t, dir, "seed.json", start.Add(time.Minute), 4, 5) },
+					func() {},
+				}
+			},
+			expected: []string{"seed.json:2"},
+		},
+		{
+			name: "Rewritten reports are read again",
+			existing: func(t *testing.T, dir string) {
+				writeWatchedReport(t, dir, "seed.json", start, 1)
+			},
+			steps: func(t *testing.T, dir string) []func() {
+				return []func(){
+					func() {},
+					func() { writeWatchedReport(t, dir, "seed.json", start.Add(time.Minute), 1, 2, 3) },
+					func() {},
+				}
+			},
+			expected: []string{"seed.json:1", "seed.json:2"},
+		},
+		{
+			name: "Unstable reports are not read",
+			steps: func(t *testing.T, dir string) []func() {
+				return []func(){
+					func() { writeWatchedFile(t, dir, "seed.json", start, `{"files": [`) },
+				}
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.existing != nil {
+				tt.existing(t, dir)
+			}
+
+			events := make([]string, 0)
+			var failed []string
+			err := WatchDirectory(context.Background(), dir, NewAccumulator(), WatchOptions{
+				SkipExisting: tt.skipExisting,
+				Wait:         watchSteps(tt.steps(t, dir)...),
+				OnError:      func(path string, err error) { failed = append(failed, filepath.Base(path)) },
+			}, func(event *WatchEvent) error {
+				events = append(events, fmt.Sprintf("%s:%d", filepath.Base(event.Report), event.NewLines))
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(events, tt.expected) {
+				t.Errorf("Expected events %v, got %v", tt.expected, events)
+			}
+			if len(failed) != 0 {
+				t.Errorf("Expected no report to fail, got %v", failed)
+			}
+		})
+	}
+}
+
+func TestWatchDirectory_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := WatchDirectory(ctx, t.TempDir(), NewAccumulator(), WatchOptions{Interval: time.Hour},
+		func(*WatchEvent) error { return nil })
+	if err != nil {
+		t.Errorf("Expected a cancelled watch to stop without error, got %v", err)
+	}
+}
+
+func TestWatchDirectory_HandlerError(t *testing.T) {
+	dir := t.TempDir()
+	writeWatchedReport(t, dir, "a.json", time.Now(), 1)
+
+	err := WatchDirectory(context.Background(), dir, NewAccumulator(), WatchOptions{Once: true},
+		func(*WatchEvent) error { return os.ErrClosed })
+	if err != os.ErrClosed {
+		t.Errorf("Expected the handler error to be returned, got %v", err)
+	}
+}
+
+func TestWatchDirectory_Errors(t *testing.T) {
+	acc := NewAccumulator()
+	handle := func(*WatchEvent) error { return nil }
+
+	if err := WatchDirectory(context.Background(), filepath.Join(t.TempDir(), "missing"), acc, WatchOptions{Once: true}, handle); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+	if err := WatchDirectory(context.Background(), t.TempDir(), acc, WatchOptions{Once: true, Pattern: "["}, handle); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestFormatWatchEvent(t *testing.T) {
+	event := &WatchEvent{
+		Time:          time.Date(2025, 11, 20, 14, 3, 9, 0, time.UTC),
+		Report:        "out/seed-17.json",
+		NewLines:      3,
+		NewBranches:   1,
+		TotalLines:    120,
+		TotalBranches: 40,
+	}
+
+	output := FormatWatchEvent(event)
+	expected := "[14:03:09] out/seed-17.json: +3 line(s), +1 branch(es) (total 120 line(s), 40 branch(es))\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}